@@ -0,0 +1,67 @@
+// Package auth carries the authenticated caller through a context.Context so
+// services never have to know whether the request came from HTTP, a CLI job
+// or a test.
+package auth
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/Amierza/e-wallet/constants"
+)
+
+var ErrUnauthenticated = errors.New("no authenticated principal in context")
+
+type Principal struct {
+	UserID    string
+	Role      string
+	TokenID   string
+	Scopes    []string
+	DeviceID  string
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+}
+
+func (p Principal) HasRole(roles ...string) bool {
+	return slices.Contains(roles, p.Role)
+}
+
+func (p Principal) HasScope(scope string) bool {
+	return slices.Contains(p.Scopes, scope)
+}
+
+// principalKey is unexported so no other package can read or overwrite the
+// value by accident.
+type principalKey struct{}
+
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+func FromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	if !ok || p.UserID == "" {
+		return Principal{}, false
+	}
+	return p, true
+}
+
+func UserID(ctx context.Context) (string, error) {
+	p, ok := FromContext(ctx)
+	if !ok {
+		return "", ErrUnauthenticated
+	}
+	return p.UserID, nil
+}
+
+func Role(ctx context.Context) string {
+	p, _ := FromContext(ctx)
+	return p.Role
+}
+
+func IsAdmin(ctx context.Context) bool {
+	p, ok := FromContext(ctx)
+	return ok && p.HasRole(constants.ENUM_ROLE_ADMIN)
+}
@@ -0,0 +1,64 @@
+// Package buildinfo describes the running binary. Version, Commit and
+// BuildTime are set at link time:
+//
+//	go build -ldflags "-X github.com/Amierza/e-wallet/buildinfo.Version=v1.4.0 \
+//		-X github.com/Amierza/e-wallet/buildinfo.Commit=$(git rev-parse HEAD) \
+//		-X github.com/Amierza/e-wallet/buildinfo.BuildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+//
+// Without them, the commit and time Go stamps into the binary are used.
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+var (
+	Version   = "dev"
+	Commit    = ""
+	BuildTime = ""
+)
+
+type Info struct {
+	Version   string
+	Commit    string
+	BuildTime string
+	GoVersion string
+	// Modified reports a build from a working tree with uncommitted changes,
+	// when Go recorded it.
+	Modified bool
+}
+
+func Get() Info {
+	info := Info{
+		Version:   Version,
+		Commit:    Commit,
+		BuildTime: BuildTime,
+		GoVersion: runtime.Version(),
+	}
+
+	if build, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range build.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				if info.Commit == "" {
+					info.Commit = setting.Value
+				}
+			case "vcs.time":
+				if info.BuildTime == "" {
+					info.BuildTime = setting.Value
+				}
+			case "vcs.modified":
+				info.Modified = setting.Value == "true"
+			}
+		}
+	}
+
+	if info.Commit == "" {
+		info.Commit = "unknown"
+	}
+	if info.BuildTime == "" {
+		info.BuildTime = "unknown"
+	}
+	return info
+}
@@ -1,37 +1,450 @@
+// Package cmd is the command line of the e-wallet binary. Every operation is
+// a subcommand with its own --help, a documented exit code and an optional
+// JSON output, so it can run unattended as a Kubernetes job.
 package cmd
 
 import (
-	"log"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
 	"os"
+	"strings"
 
-	"github.com/Amierza/e-wallet/migrations"
+	"github.com/Amierza/e-wallet/buildinfo"
+	"github.com/Amierza/e-wallet/config"
+	"github.com/Amierza/e-wallet/dto"
+	"github.com/Amierza/e-wallet/helpers"
+	"github.com/Amierza/e-wallet/logging"
+	"github.com/Amierza/e-wallet/utils"
+	"gopkg.in/yaml.v3"
 	"gorm.io/gorm"
 )
 
-func Command(db *gorm.DB) {
-	migrate := false
-	seed := false
+const programName = "e-wallet"
 
-	for _, arg := range os.Args[1:] {
-		if arg == "--migrate" {
-			migrate = true
+// Exit codes returned by Execute.
+const (
+	ExitOK = 0
+	// ExitFailure is any error while running the command.
+	ExitFailure = 1
+	// ExitUsage is a bad command, flag or argument.
+	ExitUsage = 2
+	// ExitCheckFailed means a check ran fine and found a problem, such as an
+	// inconsistent ledger.
+	ExitCheckFailed = 3
+	// ExitNotFound means the user or device the command names does not exist.
+	ExitNotFound = 4
+)
+
+// ServeFunc runs the HTTP server until it shuts down. It lives in main, which
+// owns the wiring of the API.
+type ServeFunc func(cfg config.Config, db *gorm.DB) error
+
+type command struct {
+	name    string
+	args    string
+	summary string
+	// description adds detail to the summary in the command's own help.
+	description string
+	// failed is the response message when the command fails in JSON mode.
+	failed   string
+	setup    func(fs *flag.FlagSet)
+	run      func(e *env, args []string) error
+	children []*command
+}
+
+// env holds what commands share. Configuration and the database are only
+// loaded when a command asks for them, so help and `migrate create` work
+// without either.
+type env struct {
+	ctx      context.Context
+	stdout   io.Writer
+	stderr   io.Writer
+	json     bool
+	serve    ServeFunc
+	cfg      *config.Config
+	database *gorm.DB
+}
+
+type usageError struct {
+	err error
+}
+
+func (u usageError) Error() string { return u.err.Error() }
+
+func usageErrorf(format string, args ...any) error {
+	return usageError{fmt.Errorf(format, args...)}
+}
+
+// resultError is a failure that still carries data for the JSON response,
+// such as the report of a failed check.
+type resultError struct {
+	err  error
+	data any
+}
+
+func (r resultError) Error() string { return r.err.Error() }
+func (r resultError) Unwrap() error { return r.err }
+
+// Execute runs the command named by args and returns the process exit code.
+// Without arguments it serves the API.
+func Execute(args []string, serve ServeFunc) int {
+	e := &env{
+		ctx:    context.Background(),
+		stdout: os.Stdout,
+		stderr: os.Stderr,
+		serve:  serve,
+	}
+	defer e.close()
+
+	root := rootCommand()
+	args = legacyArgs(args)
+
+	for len(args) > 0 && strings.HasPrefix(args[0], "-") {
+		switch args[0] {
+		case "--json", "-json":
+			e.json = true
+		case "-h", "--help", "-help":
+			root.usage(e.stdout, nil)
+			return ExitOK
+		default:
+			fmt.Fprintf(e.stderr, "unknown flag %s\n\n", args[0])
+			root.usage(e.stderr, nil)
+			return ExitUsage
+		}
+		args = args[1:]
+	}
+	if len(args) == 0 {
+		args = []string{"serve"}
+	}
+
+	cmd, path, err := root.execute(e, nil, args)
+	if err == nil {
+		return ExitOK
+	}
+	if errors.Is(err, flag.ErrHelp) {
+		return ExitOK
+	}
+
+	var usage usageError
+	if errors.As(err, &usage) {
+		fmt.Fprintf(e.stderr, "%s\n\n", err)
+		cmd.usage(e.stderr, path)
+		return ExitUsage
+	}
+
+	e.fail(cmd, err)
+	return exitCode(err)
+}
+
+func exitCode(err error) int {
+	switch {
+	case errors.Is(err, dto.ErrLedgerInconsistent):
+		return ExitCheckFailed
+	case errors.Is(err, dto.ErrUserNotFound), errors.Is(err, dto.ErrDeviceNotFound):
+		return ExitNotFound
+	default:
+		return ExitFailure
+	}
+}
+
+// execute walks down the command tree and runs the leaf. It returns the
+// command it stopped at, for usage and failure messages.
+func (c *command) execute(e *env, path []string, args []string) (*command, []string, error) {
+	if len(c.children) > 0 {
+		if len(args) == 0 {
+			return c, path, usageErrorf("missing command")
 		}
-		if arg == "--seed" {
-			seed = true
+		if args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+			c.usage(e.stdout, path)
+			return c, path, flag.ErrHelp
 		}
+		for _, child := range c.children {
+			if child.name == args[0] {
+				return child.execute(e, append(path, child.name), args[1:])
+			}
+		}
+		return c, path, usageErrorf("unknown command %q", args[0])
+	}
+
+	fs := flag.NewFlagSet(strings.Join(path, " "), flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.BoolVar(&e.json, "json", e.json, "print the result as JSON")
+	if c.setup != nil {
+		c.setup(fs)
 	}
 
-	if migrate {
-		if err := migrations.Migrate(db); err != nil {
-			log.Fatal("error migration: %v", err)
+	// Flags may follow the arguments too, as in `migrate up 1 --json`.
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				c.usage(e.stdout, path)
+				return c, path, err
+			}
+			return c, path, usageError{err}
 		}
-		log.Println("migration completed successfully!")
+		if fs.NArg() == 0 {
+			break
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+
+	return c, path, c.run(e, positional)
+}
+
+func (c *command) usage(w io.Writer, path []string) {
+	name := strings.TrimSpace(programName + " " + strings.Join(path, " "))
+
+	if len(c.children) > 0 {
+		if path == nil {
+			fmt.Fprintf(w, "Usage: %s [--json] <command> [flags]\n", programName)
+		} else {
+			fmt.Fprintf(w, "Usage: %s <command> [flags]\n", name)
+		}
+		if c.summary != "" {
+			fmt.Fprintf(w, "\n%s\n", c.summary)
+		}
+		fmt.Fprintln(w, "\nCommands:")
+		for _, child := range c.children {
+			fmt.Fprintf(w, "  %-14s %s\n", child.name, child.summary)
+		}
+		fmt.Fprintf(w, "\nRun '%s <command> --help' for details.\n", name)
+		if path == nil {
+			fmt.Fprintln(w, "\nExit codes: 0 ok, 1 failure, 2 usage error, 3 check failed, 4 not found.")
+		}
+		return
+	}
+
+	fmt.Fprintf(w, "Usage: %s\n\n%s\n", strings.TrimSpace(name+" [flags] "+c.args), c.summary)
+	if c.description != "" {
+		fmt.Fprintf(w, "\n%s\n", c.description)
+	}
+	fmt.Fprintln(w, "\nFlags:")
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(w)
+	fs.Bool("json", false, "print the result as JSON")
+	if c.setup != nil {
+		c.setup(fs)
+	}
+	fs.PrintDefaults()
+}
+
+// config loads the configuration once and applies the process-wide settings
+// derived from it.
+func (e *env) config() (config.Config, error) {
+	if e.cfg != nil {
+		return *e.cfg, nil
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return cfg, err
+	}
+
+	helpers.SetPinHasher(helpers.NewPinHasherFromConfig(cfg.PIN))
+	slog.SetDefault(logging.New(cfg.Log, e.stderr))
+	e.cfg = &cfg
+	return cfg, nil
+}
+
+// db opens the database on first use. Its log goes to the structured logger
+// on stderr so that stdout only carries the command's result.
+func (e *env) db() (*gorm.DB, error) {
+	if e.database != nil {
+		return e.database, nil
+	}
+
+	cfg, err := e.config()
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := config.SetUpDatabaseConnection(cfg.Database)
+	if err != nil {
+		return nil, err
+	}
+	db.Logger = logging.NewGormLogger(slog.Default(), cfg.Log.SlowQuery)
+
+	e.database = db
+	return db, nil
+}
+
+func (e *env) close() {
+	if e.database != nil {
+		config.CloseDatabaseConnection(e.database)
+	}
+}
+
+// result prints a successful outcome: the standard response envelope in JSON
+// mode, otherwise whatever text writes.
+func (e *env) result(message string, data any, text func(w io.Writer)) error {
+	if e.json {
+		return writeJSON(e.stdout, utils.BuildResponseSuccess(message, data))
+	}
+	if text != nil {
+		text(e.stdout)
+	}
+	return nil
+}
+
+func (e *env) fail(c *command, err error) {
+	if !e.json {
+		fmt.Fprintf(e.stderr, "error: %v\n", err)
+		return
+	}
+
+	var data any
+	var withData resultError
+	if errors.As(err, &withData) {
+		data = withData.data
+	}
+
+	message := c.failed
+	if message == "" {
+		message = dto.MESSAGE_FAILED_PROSES_REQUEST
 	}
+	writeJSON(e.stdout, utils.BuildResponseFailed(message, err.Error(), data))
+}
+
+func writeJSON(w io.Writer, v any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+func rootCommand() *command {
+	return &command{
+		children: []*command{
+			serveCommand(),
+			migrateCommand(),
+			seedCommand(),
+			configCommand(),
+			userCommand(),
+			tokensCommand(),
+			ledgerCommand(),
+			exportCommand(),
+			versionCommand(),
+		},
+	}
+}
 
-	if seed {
-		if err := migrations.Seeder(db); err != nil {
-			log.Fatal("error migration seeder: %v", err)
+// legacyArgs maps the flags older deploy scripts pass onto the subcommands
+// that replaced them.
+func legacyArgs(args []string) []string {
+	if len(args) == 0 {
+		return args
+	}
+
+	switch args[0] {
+	case "--migrate":
+		if len(args) == 1 {
+			return []string{"migrate", "up"}
+		}
+		return append([]string{"migrate"}, args[1:]...)
+	case "--seed":
+		if len(args) == 1 {
+			return []string{"seed", "fixtures"}
 		}
-		log.Println("seeder completed successfully!")
+		return append([]string{"seed"}, args[1:]...)
+	case "--print-config":
+		return append([]string{"config", "print"}, args[1:]...)
+	}
+
+	return args
+}
+
+func serveCommand() *command {
+	return &command{
+		name:    "serve",
+		summary: "Run the HTTP API until SIGINT or SIGTERM (the default command).",
+		failed:  dto.MESSAGE_FAILED_PROSES_REQUEST,
+		run: func(e *env, args []string) error {
+			if len(args) > 0 {
+				return usageErrorf("serve takes no arguments")
+			}
+
+			cfg, err := e.config()
+			if err != nil {
+				return err
+			}
+			db, err := e.db()
+			if err != nil {
+				return err
+			}
+
+			return e.serve(cfg, db)
+		},
+	}
+}
+
+func versionCommand() *command {
+	return &command{
+		name:    "version",
+		summary: "Print the version, commit and build time of this binary.",
+		run: func(e *env, args []string) error {
+			if len(args) > 0 {
+				return usageErrorf("version takes no arguments")
+			}
+
+			info := buildinfo.Get()
+			return e.result(dto.MESSAGE_SUCCESS_GET_VERSION, dto.VersionResponse{
+				Version:   info.Version,
+				Commit:    info.Commit,
+				BuildTime: info.BuildTime,
+				GoVersion: info.GoVersion,
+				Modified:  info.Modified,
+			}, func(w io.Writer) {
+				fmt.Fprintf(w, "version %s\ncommit %s\nbuilt %s with %s\n", info.Version, info.Commit, info.BuildTime, info.GoVersion)
+			})
+		},
+	}
+}
+
+func configCommand() *command {
+	return &command{
+		name:    "config",
+		summary: "Inspect the configuration.",
+		children: []*command{{
+			name:    "print",
+			summary: "Print the effective configuration as YAML with secrets redacted. Fails when it does not validate.",
+			failed:  "failed load config",
+			run: func(e *env, args []string) error {
+				if len(args) > 0 {
+					return usageErrorf("config print takes no arguments")
+				}
+
+				// A config that fails validation is still printed so the
+				// problems can be seen in context.
+				cfg, err := config.Load()
+				if !e.json {
+					if printErr := cfg.Print(e.stdout); printErr != nil {
+						return printErr
+					}
+					return err
+				}
+
+				// Going through the YAML keeps its key names and readable
+				// durations in the JSON output.
+				var buf bytes.Buffer
+				var doc map[string]any
+				if printErr := cfg.Print(&buf); printErr != nil {
+					return printErr
+				}
+				if yamlErr := yaml.Unmarshal(buf.Bytes(), &doc); yamlErr != nil {
+					return yamlErr
+				}
+				if err != nil {
+					return resultError{err, doc}
+				}
+				return e.result("success load config", doc, nil)
+			},
+		}},
 	}
 }
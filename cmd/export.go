@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/Amierza/e-wallet/dto"
+	"github.com/Amierza/e-wallet/utils"
+)
+
+var transactionExportHeader = []string{
+	"type", "id", "user_id", "target_user_id", "currency", "amount",
+	"target_currency", "target_amount", "balance_before", "balance_after", "remarks", "created_at",
+}
+
+func exportCommand() *command {
+	return &command{
+		name:    "export",
+		summary: "Export records for reporting.",
+		children: []*command{
+			exportTransactionsCommand(),
+		},
+	}
+}
+
+func exportTransactionsCommand() *command {
+	var (
+		req    dto.ExportTransactionsRequest
+		output string
+	)
+
+	return &command{
+		name:        "transactions",
+		summary:     "Export top-ups, payments and transfers.",
+		description: "Rows are written as CSV, or as the JSON response with --json. Amounts are in major units of their currency.",
+		failed:      dto.MESSAGE_FAILED_EXPORT_TRANSACTIONS,
+		setup: func(fs *flag.FlagSet) {
+			fs.StringVar(&req.UserID, "id", "", "only export this user ID, including transfers they received")
+			fs.StringVar(&req.PhoneNumber, "phone", "", "only export the user with this phone number")
+			fs.StringVar(&req.From, "from", "", "first day to include, YYYY-MM-DD")
+			fs.StringVar(&req.To, "to", "", "last day to include, YYYY-MM-DD")
+			fs.StringVar(&output, "output", "", "write to this file instead of stdout")
+		},
+		run: func(e *env, args []string) error {
+			if len(args) > 0 {
+				return usageErrorf("unexpected argument %q", args[0])
+			}
+
+			adminService, err := newAdminService(e)
+			if err != nil {
+				return err
+			}
+
+			rows, err := adminService.ExportTransactions(e.ctx, req)
+			if err != nil {
+				return err
+			}
+
+			var file *os.File
+			w := e.stdout
+			if output != "" {
+				if file, err = os.Create(output); err != nil {
+					return err
+				}
+				w = file
+			}
+
+			if e.json {
+				err = writeJSON(w, utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_EXPORT_TRANSACTIONS, rows))
+			} else {
+				err = writeTransactionsCSV(w, rows)
+			}
+			if file != nil {
+				if closeErr := file.Close(); err == nil {
+					err = closeErr
+				}
+			}
+			if err != nil {
+				return err
+			}
+
+			if output != "" {
+				fmt.Fprintf(e.stderr, "exported %d transactions to %s\n", len(rows), output)
+			}
+			return nil
+		},
+	}
+}
+
+func writeTransactionsCSV(w io.Writer, rows []dto.TransactionExportResponse) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(transactionExportHeader); err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		targetAmount := ""
+		if row.TargetAmount != nil {
+			targetAmount = row.TargetAmount.String()
+		}
+
+		record := []string{
+			row.Type,
+			row.ID,
+			row.UserID,
+			row.TargetUserID,
+			row.Currency,
+			row.Amount.String(),
+			row.TargetCurrency,
+			targetAmount,
+			row.BalanceBefore.String(),
+			row.BalanceAfter.String(),
+			row.Remarks,
+			row.CreatedAt.UTC().Format(time.RFC3339),
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/Amierza/e-wallet/dto"
+	"github.com/Amierza/e-wallet/repository"
+	"github.com/Amierza/e-wallet/service"
+)
+
+func ledgerCommand() *command {
+	return &command{
+		name:    "ledger",
+		summary: "Check the money records.",
+		children: []*command{
+			ledgerVerifyCommand(),
+		},
+	}
+}
+
+func ledgerVerifyCommand() *command {
+	var lookup dto.UserLookupRequest
+
+	return &command{
+		name:        "verify",
+		summary:     "Check wallet balances against their transaction history.",
+		description: "Every wallet balance must equal what its top-ups, payments and transfers add up to, and every transaction's balance_after must follow from its balance_before and amount. Exits with 3 when they do not.",
+		failed:      dto.MESSAGE_FAILED_VERIFY_LEDGER,
+		setup: func(fs *flag.FlagSet) {
+			fs.StringVar(&lookup.UserID, "id", "", "only check this user ID")
+			fs.StringVar(&lookup.PhoneNumber, "phone", "", "only check the user with this phone number")
+		},
+		run: func(e *env, args []string) error {
+			if len(args) > 0 {
+				return usageErrorf("unexpected argument %q", args[0])
+			}
+
+			db, err := e.db()
+			if err != nil {
+				return err
+			}
+
+			var req dto.LedgerVerifyRequest
+			if lookup.UserID != "" || lookup.PhoneNumber != "" {
+				adminService, err := newAdminService(e)
+				if err != nil {
+					return err
+				}
+				user, err := adminService.GetUser(e.ctx, lookup)
+				if err != nil {
+					return err
+				}
+				req.UserID = user.ID
+			}
+
+			ledgerService := service.NewLedgerService(repository.NewLedgerRepository(db), repository.NewTransactor(db))
+			report, err := ledgerService.Verify(e.ctx, req)
+			if err != nil {
+				return err
+			}
+
+			if !report.Consistent() {
+				if !e.json {
+					printLedgerReport(e.stdout, report)
+				}
+				return resultError{dto.ErrLedgerInconsistent, report}
+			}
+
+			return e.result(dto.MESSAGE_SUCCESS_VERIFY_LEDGER, report, func(w io.Writer) {
+				printLedgerReport(w, report)
+			})
+		},
+	}
+}
+
+func printLedgerReport(w io.Writer, report dto.LedgerReportResponse) {
+	fmt.Fprintf(w, "checked %d wallets and %d transactions\n", report.WalletsChecked, report.EntriesChecked)
+
+	if len(report.WalletMismatches) > 0 {
+		fmt.Fprintf(w, "\n%d wallets do not match their history:\n", len(report.WalletMismatches))
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "WALLET\tUSER\tCURRENCY\tBALANCE\tEXPECTED")
+		for _, wallet := range report.WalletMismatches {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", wallet.WalletID, wallet.UserID, wallet.Currency, wallet.Balance, wallet.Expected)
+		}
+		tw.Flush()
+	}
+
+	if len(report.EntryMismatches) > 0 {
+		fmt.Fprintf(w, "\n%d transactions have balances that do not add up:\n", len(report.EntryMismatches))
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "TYPE\tID\tUSER\tAMOUNT\tBEFORE\tAFTER")
+		for _, entry := range report.EntryMismatches {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s %s\t%s\t%s\n", entry.Type, entry.ID, entry.UserID, entry.Amount, entry.Currency, entry.BalanceBefore, entry.BalanceAfter)
+		}
+		tw.Flush()
+	}
+}
@@ -0,0 +1,208 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Amierza/e-wallet/migrations"
+)
+
+type migrationResult struct {
+	Version int64  `json:"version"`
+	Name    string `json:"name"`
+}
+
+type migrationStatusResult struct {
+	Version   int64      `json:"version"`
+	Name      string     `json:"name"`
+	AppliedAt *time.Time `json:"applied_at"`
+	Missing   bool       `json:"missing,omitempty"`
+}
+
+func migrateCommand() *command {
+	return &command{
+		name:    "migrate",
+		summary: "Manage the versioned SQL schema migrations.",
+		children: []*command{
+			migrateUpCommand(),
+			migrateDownCommand(),
+			migrateStatusCommand(),
+			migrateCreateCommand(),
+		},
+	}
+}
+
+func migrateUpCommand() *command {
+	return &command{
+		name:    "up",
+		args:    "[N]",
+		summary: "Apply pending migrations, all of them or only the next N.",
+		failed:  "failed migrate up",
+		run: func(e *env, args []string) error {
+			n, err := migrationCount(args, 0)
+			if err != nil {
+				return err
+			}
+
+			migrator, err := newMigrator(e)
+			if err != nil {
+				return err
+			}
+
+			applied, err := migrator.Up(e.ctx, n)
+			if err != nil {
+				return resultError{err, migrationResults(applied)}
+			}
+
+			return e.result("success migrate up", migrationResults(applied), func(w io.Writer) {
+				for _, migration := range applied {
+					fmt.Fprintf(w, "applied %04d_%s\n", migration.Version, migration.Name)
+				}
+				fmt.Fprintf(w, "migration completed successfully! %d applied\n", len(applied))
+			})
+		},
+	}
+}
+
+func migrateDownCommand() *command {
+	return &command{
+		name:    "down",
+		args:    "[N]",
+		summary: "Roll back the last N applied migrations, one by default.",
+		failed:  "failed migrate down",
+		run: func(e *env, args []string) error {
+			n, err := migrationCount(args, 1)
+			if err != nil {
+				return err
+			}
+
+			migrator, err := newMigrator(e)
+			if err != nil {
+				return err
+			}
+
+			reverted, err := migrator.Down(e.ctx, n)
+			if err != nil {
+				return resultError{err, migrationResults(reverted)}
+			}
+
+			return e.result("success migrate down", migrationResults(reverted), func(w io.Writer) {
+				for _, migration := range reverted {
+					fmt.Fprintf(w, "reverted %04d_%s\n", migration.Version, migration.Name)
+				}
+				fmt.Fprintf(w, "rollback completed successfully! %d reverted\n", len(reverted))
+			})
+		},
+	}
+}
+
+func migrateStatusCommand() *command {
+	return &command{
+		name:    "status",
+		summary: "List every migration and when it was applied.",
+		failed:  "failed migrate status",
+		run: func(e *env, args []string) error {
+			if len(args) > 0 {
+				return usageErrorf("migrate status takes no arguments")
+			}
+
+			migrator, err := newMigrator(e)
+			if err != nil {
+				return err
+			}
+
+			statuses, err := migrator.Status(e.ctx)
+			if err != nil {
+				return err
+			}
+
+			results := []migrationStatusResult{}
+			for _, status := range statuses {
+				results = append(results, migrationStatusResult(status))
+			}
+
+			return e.result("success migrate status", results, func(w io.Writer) {
+				tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+				fmt.Fprintln(tw, "VERSION\tNAME\tAPPLIED AT")
+				for _, status := range statuses {
+					appliedAt := "pending"
+					if status.AppliedAt != nil {
+						appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05 MST")
+					}
+					if status.Missing {
+						appliedAt += " (file missing)"
+					}
+					fmt.Fprintf(tw, "%04d\t%s\t%s\n", status.Version, status.Name, appliedAt)
+				}
+				tw.Flush()
+			})
+		},
+	}
+}
+
+func migrateCreateCommand() *command {
+	var dir string
+
+	return &command{
+		name:        "create",
+		args:        "NAME",
+		summary:     "Write an empty up/down migration pair numbered after the latest one.",
+		description: "Run it from a source checkout: the binary only applies the migrations embedded when it was built.",
+		failed:      "failed migrate create",
+		setup: func(fs *flag.FlagSet) {
+			fs.StringVar(&dir, "dir", migrations.SourceDir, "directory holding the migration files")
+		},
+		run: func(e *env, args []string) error {
+			if len(args) != 1 {
+				return usageErrorf("migrate create takes exactly one NAME")
+			}
+
+			paths, err := migrations.Create(dir, args[0])
+			if err != nil {
+				return err
+			}
+
+			return e.result("success migrate create", paths, func(w io.Writer) {
+				for _, path := range paths {
+					fmt.Fprintf(w, "created %s\n", path)
+				}
+			})
+		},
+	}
+}
+
+func newMigrator(e *env) (*migrations.Migrator, error) {
+	db, err := e.db()
+	if err != nil {
+		return nil, err
+	}
+
+	return migrations.NewMigrator(db)
+}
+
+func migrationCount(args []string, fallback int) (int, error) {
+	switch len(args) {
+	case 0:
+		return fallback, nil
+	case 1:
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n < 1 {
+			return 0, usageErrorf("invalid migration count %q", args[0])
+		}
+		return n, nil
+	default:
+		return 0, usageErrorf("expected at most one migration count")
+	}
+}
+
+func migrationResults(applied []migrations.Migration) []migrationResult {
+	results := []migrationResult{}
+	for _, migration := range applied {
+		results = append(results, migrationResult{Version: migration.Version, Name: migration.Name})
+	}
+	return results
+}
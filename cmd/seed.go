@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Amierza/e-wallet/migrations/seeds"
+)
+
+var errFakeInProduction = errors.New("refusing to generate fake data in production")
+
+func seedCommand() *command {
+	return &command{
+		name:    "seed",
+		summary: "Load fixtures or generate fake data.",
+		children: []*command{
+			seedFixturesCommand(),
+			seedFakeCommand(),
+		},
+	}
+}
+
+func seedFixturesCommand() *command {
+	var (
+		set  seeds.FixtureSet
+		only string
+	)
+
+	return &command{
+		name:        "fixtures",
+		summary:     "Load the fixture set of an environment. Safe to run again.",
+		description: fmt.Sprintf("Seeders run in this order: %s. A file in DIR/ENV replaces the shared one of the same name in DIR, and a seeder without a file does nothing. Fixtures already in the database are skipped.", strings.Join(seeds.Names(), ", ")),
+		failed:      "failed seed",
+		setup: func(fs *flag.FlagSet) {
+			fs.StringVar(&set.Env, "env", "", "fixture set to load, defaults to APP_ENV")
+			fs.StringVar(&set.Dir, "dir", seeds.BaseDir, "directory holding the fixture sets")
+			fs.StringVar(&only, "only", "", "comma separated seeders to run, all by default")
+		},
+		run: func(e *env, args []string) error {
+			if len(args) > 0 {
+				return usageErrorf("seed fixtures takes no arguments")
+			}
+
+			var names []string
+			if only != "" {
+				names = strings.Split(only, ",")
+			}
+			for _, name := range names {
+				if !slices.Contains(seeds.Names(), name) {
+					return usageErrorf("unknown seeder %q", name)
+				}
+			}
+
+			db, err := e.db()
+			if err != nil {
+				return err
+			}
+			if set.Env == "" {
+				cfg, _ := e.config()
+				set.Env = cfg.App.Env
+			}
+
+			results, err := seeds.Run(e.ctx, db, set, names...)
+			if err != nil {
+				return resultError{err, results}
+			}
+
+			return e.result("success seed", results, func(w io.Writer) {
+				tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+				fmt.Fprintln(tw, "SEEDER\tFILE\tCREATED\tSKIPPED")
+				for _, result := range results {
+					file := result.File
+					if file == "" {
+						file = "-"
+					}
+					fmt.Fprintf(tw, "%s\t%s\t%d\t%d\n", result.Name, file, result.Created, result.Skipped)
+				}
+				tw.Flush()
+				fmt.Fprintf(w, "seeder completed successfully! fixture set %q\n", set.Env)
+			})
+		},
+	}
+}
+
+func seedFakeCommand() *command {
+	var opts seeds.FakeOptions
+
+	return &command{
+		name:        "fake",
+		summary:     "Generate users and a transaction history between them.",
+		description: "Every generated balance matches its generated history, so the new wallets pass `ledger verify`. Generated phone numbers start with 0899. Refused when APP_ENV is production.",
+		failed:      "failed seed fake",
+		setup: func(fs *flag.FlagSet) {
+			fs.IntVar(&opts.Users, "users", 100, "number of users to create")
+			fs.IntVar(&opts.Transactions, "transactions", 1000, "number of top-ups, payments and transfers to create")
+			fs.IntVar(&opts.Days, "days", 90, "how many days back the history starts")
+			fs.StringVar(&opts.Pin, "pin", "482913", "PIN of every generated user")
+			fs.Int64Var(&opts.Seed, "rand-seed", 0, "seed of the generator, to repeat a run; random by default")
+		},
+		run: func(e *env, args []string) error {
+			if len(args) > 0 {
+				return usageErrorf("seed fake takes no arguments")
+			}
+			if opts.Users < 1 {
+				return usageErrorf("--users must be at least 1")
+			}
+			if opts.Transactions < 0 {
+				return usageErrorf("--transactions must not be negative")
+			}
+			if opts.Days < 1 {
+				return usageErrorf("--days must be at least 1")
+			}
+			if opts.Seed == 0 {
+				opts.Seed = time.Now().UnixNano()
+			}
+
+			db, err := e.db()
+			if err != nil {
+				return err
+			}
+			if cfg, _ := e.config(); cfg.IsProduction() {
+				return errFakeInProduction
+			}
+
+			result, err := seeds.Fake(e.ctx, db, opts)
+			if err != nil {
+				return err
+			}
+
+			return e.result("success seed fake", result, func(w io.Writer) {
+				fmt.Fprintf(w, "created %d users with %d wallets\n", result.Users, result.Wallets)
+				fmt.Fprintf(w, "created %d top-ups, %d payments and %d transfers between %s and %s\n",
+					result.TopUps, result.Payments, result.Transfers, result.From.Format(time.DateOnly), result.To.Format(time.DateOnly))
+				fmt.Fprintf(w, "repeat with --rand-seed %d\n", result.Seed)
+			})
+		},
+	}
+}
@@ -0,0 +1,265 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Amierza/e-wallet/dto"
+	"github.com/Amierza/e-wallet/repository"
+	"github.com/Amierza/e-wallet/service"
+)
+
+func userCommand() *command {
+	return &command{
+		name:    "user",
+		summary: "Manage user accounts.",
+		children: []*command{
+			userCreateAdminCommand(),
+			userShowCommand(),
+			userFreezeCommand(),
+			userUnfreezeCommand(),
+		},
+	}
+}
+
+func tokensCommand() *command {
+	return &command{
+		name:    "tokens",
+		summary: "Manage issued tokens.",
+		children: []*command{
+			tokensRevokeCommand(),
+		},
+	}
+}
+
+func userCreateAdminCommand() *command {
+	var req dto.AdminCreateRequest
+
+	return &command{
+		name:        "create-admin",
+		summary:     "Create an admin account with a verified phone number.",
+		description: "The PIN is read from --pin or, to keep it out of the process list, from the ADMIN_PIN environment variable.",
+		failed:      dto.MESSAGE_FAILED_CREATE_ADMIN,
+		setup: func(fs *flag.FlagSet) {
+			fs.StringVar(&req.PhoneNumber, "phone", "", "phone number (required)")
+			fs.StringVar(&req.FirstName, "first-name", "", "first name")
+			fs.StringVar(&req.LastName, "last-name", "", "last name")
+			fs.StringVar(&req.Email, "email", "", "email, stored as verified")
+			fs.StringVar(&req.Pin, "pin", "", "PIN, defaults to $ADMIN_PIN")
+		},
+		run: func(e *env, args []string) error {
+			if len(args) > 0 {
+				return usageErrorf("unexpected argument %q", args[0])
+			}
+			if req.PhoneNumber == "" {
+				return usageErrorf("--phone is required")
+			}
+			if req.Pin == "" {
+				req.Pin = os.Getenv("ADMIN_PIN")
+			}
+			if req.Pin == "" {
+				return usageErrorf("--pin or ADMIN_PIN is required")
+			}
+
+			adminService, err := newAdminService(e)
+			if err != nil {
+				return err
+			}
+
+			user, err := adminService.CreateAdmin(e.ctx, req)
+			if err != nil {
+				return err
+			}
+
+			return e.result(dto.MESSAGE_SUCCESS_CREATE_ADMIN, user, func(w io.Writer) {
+				fmt.Fprintf(w, "created admin %s (%s)\n", user.ID, user.PhoneNumber)
+			})
+		},
+	}
+}
+
+func userShowCommand() *command {
+	var lookup dto.UserLookupRequest
+
+	return &command{
+		name:    "show",
+		summary: "Show a user with their wallets.",
+		failed:  dto.MESSAGE_FAILED_GET_DETAIL_USER,
+		setup: func(fs *flag.FlagSet) {
+			lookupFlags(fs, &lookup)
+		},
+		run: func(e *env, args []string) error {
+			if err := requireLookup(args, lookup); err != nil {
+				return err
+			}
+
+			adminService, err := newAdminService(e)
+			if err != nil {
+				return err
+			}
+
+			user, err := adminService.GetUser(e.ctx, lookup)
+			if err != nil {
+				return err
+			}
+
+			return e.result(dto.MESSAGE_SUCCESS_GET_DETAIL_USER, user, func(w io.Writer) {
+				tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+				fmt.Fprintf(tw, "ID\t%s\n", user.ID)
+				fmt.Fprintf(tw, "Name\t%s %s\n", user.FirstName, user.LastName)
+				fmt.Fprintf(tw, "Phone\t%s\t%s\n", user.PhoneNumber, verified(user.PhoneVerifiedAt))
+				if user.Email != nil {
+					fmt.Fprintf(tw, "Email\t%s\t%s\n", *user.Email, verified(user.EmailVerifiedAt))
+				}
+				fmt.Fprintf(tw, "Role\t%s\n", user.Role)
+				if user.FrozenAt != nil {
+					fmt.Fprintf(tw, "Frozen\tsince %s\n", user.FrozenAt.Format(time.RFC3339))
+				}
+				fmt.Fprintf(tw, "Active devices\t%d\n", user.ActiveDevices)
+				for _, wallet := range user.Wallets {
+					fmt.Fprintf(tw, "Wallet %s\t%s\n", wallet.Currency, wallet.Balance)
+				}
+				if user.Timestamp != nil {
+					fmt.Fprintf(tw, "Created\t%s\n", user.CreatedAt.Format(time.RFC3339))
+				}
+				tw.Flush()
+			})
+		},
+	}
+}
+
+func userFreezeCommand() *command {
+	var lookup dto.UserLookupRequest
+
+	return &command{
+		name:    "freeze",
+		summary: "Block an account from logging in and sign it out of every device.",
+		failed:  dto.MESSAGE_FAILED_FREEZE_USER,
+		setup: func(fs *flag.FlagSet) {
+			lookupFlags(fs, &lookup)
+		},
+		run: func(e *env, args []string) error {
+			if err := requireLookup(args, lookup); err != nil {
+				return err
+			}
+
+			adminService, err := newAdminService(e)
+			if err != nil {
+				return err
+			}
+
+			user, err := adminService.FreezeUser(e.ctx, lookup)
+			if err != nil {
+				return err
+			}
+
+			return e.result(dto.MESSAGE_SUCCESS_FREEZE_USER, user, func(w io.Writer) {
+				fmt.Fprintf(w, "user %s frozen since %s\n", user.ID, user.FrozenAt.Format(time.RFC3339))
+			})
+		},
+	}
+}
+
+func userUnfreezeCommand() *command {
+	var lookup dto.UserLookupRequest
+
+	return &command{
+		name:    "unfreeze",
+		summary: "Lift a freeze. The user has to log in again.",
+		failed:  dto.MESSAGE_FAILED_UNFREEZE_USER,
+		setup: func(fs *flag.FlagSet) {
+			lookupFlags(fs, &lookup)
+		},
+		run: func(e *env, args []string) error {
+			if err := requireLookup(args, lookup); err != nil {
+				return err
+			}
+
+			adminService, err := newAdminService(e)
+			if err != nil {
+				return err
+			}
+
+			user, err := adminService.UnfreezeUser(e.ctx, lookup)
+			if err != nil {
+				return err
+			}
+
+			return e.result(dto.MESSAGE_SUCCESS_UNFREEZE_USER, user, func(w io.Writer) {
+				fmt.Fprintf(w, "user %s unfrozen\n", user.ID)
+			})
+		},
+	}
+}
+
+func tokensRevokeCommand() *command {
+	var req dto.RevokeTokensRequest
+
+	return &command{
+		name:    "revoke",
+		summary: "Invalidate every token of a user, or only those of one device with --device.",
+		failed:  dto.MESSAGE_FAILED_REVOKE_TOKENS,
+		setup: func(fs *flag.FlagSet) {
+			lookupFlags(fs, &req.UserLookupRequest)
+			fs.StringVar(&req.DeviceID, "device", "", "only sign out this device ID")
+		},
+		run: func(e *env, args []string) error {
+			if err := requireLookup(args, req.UserLookupRequest); err != nil {
+				return err
+			}
+
+			adminService, err := newAdminService(e)
+			if err != nil {
+				return err
+			}
+
+			res, err := adminService.RevokeTokens(e.ctx, req)
+			if err != nil {
+				return err
+			}
+
+			return e.result(dto.MESSAGE_SUCCESS_REVOKE_TOKENS, res, func(w io.Writer) {
+				if res.DeviceID != "" {
+					fmt.Fprintf(w, "revoked tokens of device %s of user %s\n", res.DeviceID, res.UserID)
+					return
+				}
+				fmt.Fprintf(w, "revoked every token of user %s\n", res.UserID)
+			})
+		},
+	}
+}
+
+func newAdminService(e *env) (service.AdminService, error) {
+	db, err := e.db()
+	if err != nil {
+		return nil, err
+	}
+
+	return service.NewAdminService(repository.NewUserRepository(db), repository.NewDeviceRepository(db), repository.NewOutboxRepository(db), repository.NewTransactor(db)), nil
+}
+
+func lookupFlags(fs *flag.FlagSet, lookup *dto.UserLookupRequest) {
+	fs.StringVar(&lookup.UserID, "id", "", "user ID")
+	fs.StringVar(&lookup.PhoneNumber, "phone", "", "phone number, when --id is not given")
+}
+
+func requireLookup(args []string, lookup dto.UserLookupRequest) error {
+	if len(args) > 0 {
+		return usageErrorf("unexpected argument %q", args[0])
+	}
+	if lookup.UserID == "" && lookup.PhoneNumber == "" {
+		return usageErrorf("--id or --phone is required")
+	}
+	return nil
+}
+
+func verified(at *time.Time) string {
+	if at == nil {
+		return "unverified"
+	}
+	return "verified " + at.Format(time.RFC3339)
+}
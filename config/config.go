@@ -0,0 +1,166 @@
+package config
+
+import (
+	"time"
+
+	"github.com/Amierza/e-wallet/constants"
+)
+
+// Config is every setting the service reads. Each leaf field names its
+// environment variable, its key in the optional YAML file and its default;
+// fields tagged secret are redacted when the config is printed.
+type Config struct {
+	App       App       `yaml:"app"`
+	Log       Log       `yaml:"log"`
+	Metrics   Metrics   `yaml:"metrics"`
+	Tracing   Tracing   `yaml:"tracing"`
+	RateLimit RateLimit `yaml:"rate_limit"`
+	Server    Server    `yaml:"server"`
+	Database  Database  `yaml:"database"`
+	JWT       JWT       `yaml:"jwt"`
+	Secrets   Secrets   `yaml:"secrets"`
+	PIN       PIN       `yaml:"pin"`
+	Notifier  Notifier  `yaml:"notifier"`
+	Realtime  Realtime  `yaml:"realtime"`
+	FX        FX        `yaml:"fx"`
+}
+
+type App struct {
+	Env  string `yaml:"env" env:"APP_ENV" default:"localhost"`
+	URL  string `yaml:"url" env:"APP_URL" default:"http://localhost:8080"`
+	Port int    `yaml:"port" env:"PORT" default:"8888"`
+}
+
+// Log sets how much is logged and in which format. Queries slower than
+// SlowQuery are logged as warnings.
+type Log struct {
+	Level     string        `yaml:"level" env:"LOG_LEVEL" default:"info"`
+	Format    string        `yaml:"format" env:"LOG_FORMAT" default:"json"`
+	SlowQuery time.Duration `yaml:"slow_query" env:"LOG_SLOW_QUERY" default:"200ms"`
+}
+
+// Metrics guards /metrics: when Token is set, scrapers must send it as a
+// bearer token.
+type Metrics struct {
+	Token string `yaml:"token" env:"METRICS_TOKEN" secret:"true"`
+}
+
+// Tracing picks where spans go: nowhere, stdout for local use, or an
+// OTLP/HTTP collector at OTLPEndpoint. SampleRatio is the share of new traces
+// kept; a request whose traceparent is sampled is always traced.
+type Tracing struct {
+	Exporter     string  `yaml:"exporter" env:"TRACING_EXPORTER" default:"none"`
+	OTLPEndpoint string  `yaml:"otlp_endpoint" env:"TRACING_OTLP_ENDPOINT" default:"http://localhost:4318"`
+	SampleRatio  float64 `yaml:"sample_ratio" env:"TRACING_SAMPLE_RATIO" default:"1"`
+	ServiceName  string  `yaml:"service_name" env:"TRACING_SERVICE_NAME" default:"e-wallet"`
+}
+
+// Server bounds how long a client may take per request and how long a
+// shutdown waits for in-flight requests and background workers. On SIGTERM
+// readiness fails for ShutdownDelay before connections stop being accepted,
+// giving load balancers time to stop routing traffic here.
+type Server struct {
+	ReadTimeout       time.Duration `yaml:"read_timeout" env:"SERVER_READ_TIMEOUT" default:"15s"`
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout" env:"SERVER_READ_HEADER_TIMEOUT" default:"5s"`
+	WriteTimeout      time.Duration `yaml:"write_timeout" env:"SERVER_WRITE_TIMEOUT" default:"30s"`
+	IdleTimeout       time.Duration `yaml:"idle_timeout" env:"SERVER_IDLE_TIMEOUT" default:"60s"`
+	MaxHeaderBytes    int           `yaml:"max_header_bytes" env:"SERVER_MAX_HEADER_BYTES" default:"1048576"`
+	ShutdownTimeout   time.Duration `yaml:"shutdown_timeout" env:"SERVER_SHUTDOWN_TIMEOUT" default:"30s"`
+	ShutdownDelay     time.Duration `yaml:"shutdown_delay" env:"SERVER_SHUTDOWN_DELAY" default:"5s"`
+	TrustedProxies    []string      `yaml:"trusted_proxies" env:"SERVER_TRUSTED_PROXIES" default:"127.0.0.1,::1,10.0.0.0/8,172.16.0.0/12,192.168.0.0/16"`
+}
+
+type Database struct {
+	Host     string `yaml:"host" env:"DB_HOST" required:"true"`
+	Port     int    `yaml:"port" env:"DB_PORT" default:"5432"`
+	User     string `yaml:"user" env:"DB_USER" required:"true"`
+	Password string `yaml:"password" env:"DB_PASS" secret:"true"`
+	Name     string `yaml:"name" env:"DB_NAME" required:"true"`
+	TimeZone string `yaml:"time_zone" env:"DB_TIMEZONE" default:"Asia/Jakarta"`
+}
+
+type JWT struct {
+	SigningKeyFile       string        `yaml:"signing_key_file" env:"JWT_SIGNING_KEY_FILE"`
+	SigningKeyID         string        `yaml:"signing_key_id" env:"JWT_SIGNING_KEY_ID"`
+	VerificationKeyFiles []string      `yaml:"verification_key_files" env:"JWT_VERIFICATION_KEY_FILES"`
+	Issuer               string        `yaml:"issuer" env:"JWT_ISSUER" default:"Template"`
+	AccessTTL            time.Duration `yaml:"access_ttl" env:"JWT_ACCESS_TTL" default:"2m"`
+	RefreshTTL           time.Duration `yaml:"refresh_ttl" env:"JWT_REFRESH_TTL" default:"168h"`
+}
+
+// Secrets key the HMAC-signed links and one-time codes. OTP and TOTPKey fall
+// back to Signing when unset.
+type Secrets struct {
+	Signing string `yaml:"signing" env:"JWT_SECRET" secret:"true"`
+	OTP     string `yaml:"otp" env:"OTP_SECRET" secret:"true"`
+	TOTPKey string `yaml:"totp_key" env:"TOTP_ENCRYPTION_KEY" secret:"true"`
+}
+
+type PIN struct {
+	HashAlgorithm  string `yaml:"hash_algorithm" env:"PIN_HASH_ALGORITHM" default:"bcrypt"`
+	BcryptCost     int    `yaml:"bcrypt_cost" env:"PIN_BCRYPT_COST" default:"12"`
+	Argon2MemoryKB int    `yaml:"argon2_memory_kb" env:"PIN_ARGON2_MEMORY_KB" default:"65536"`
+	Argon2Time     int    `yaml:"argon2_time" env:"PIN_ARGON2_TIME" default:"3"`
+	Argon2Threads  int    `yaml:"argon2_threads" env:"PIN_ARGON2_THREADS" default:"2"`
+	Pepper         string `yaml:"pepper" env:"PIN_PEPPER" secret:"true"`
+}
+
+type Notifier struct {
+	SMS        string     `yaml:"sms" env:"NOTIFIER_SMS" default:"log"`
+	Email      string     `yaml:"email" env:"NOTIFIER_EMAIL" default:"log"`
+	LogFile    string     `yaml:"log_file" env:"NOTIFIER_LOG_FILE"`
+	SMSGateway SMSGateway `yaml:"sms_gateway"`
+	SMTP       SMTP       `yaml:"smtp"`
+}
+
+type SMSGateway struct {
+	URL      string `yaml:"url" env:"SMS_GATEWAY_URL"`
+	Token    string `yaml:"token" env:"SMS_GATEWAY_TOKEN" secret:"true"`
+	SenderID string `yaml:"sender_id" env:"SMS_SENDER_ID" default:"E-Wallet"`
+}
+
+type SMTP struct {
+	Host       string `yaml:"host" env:"SMTP_HOST"`
+	Port       string `yaml:"port" env:"SMTP_PORT" default:"587"`
+	SenderName string `yaml:"sender_name" env:"SMTP_SENDER_NAME"`
+	AuthEmail  string `yaml:"auth_email" env:"SMTP_AUTH_EMAIL"`
+	Password   string `yaml:"auth_password" env:"SMTP_AUTH_PASSWORD" secret:"true"`
+}
+
+type Realtime struct {
+	Backend string `yaml:"backend" env:"REALTIME_BACKEND" default:"postgres"`
+}
+
+// RateLimit holds a limit/period policy, such as 5/1m, per route group and
+// key; "off" disables one. LoginAccount keys on the email or phone number a
+// login names, and on the challenge token of a two-factor login, so guessing
+// PINs or codes from many IPs is still limited. OTPPhone keys on the phone
+// number in the request body. EmailUser caps the emails a signed-in user can
+// have sent to their own address, verification links and statements alike.
+// Store is memory for a single instance or postgres to share buckets between
+// them.
+type RateLimit struct {
+	Enabled         bool   `yaml:"enabled" env:"RATE_LIMIT_ENABLED" default:"true"`
+	Store           string `yaml:"store" env:"RATE_LIMIT_STORE" default:"memory"`
+	LoginIP         string `yaml:"login_ip" env:"RATE_LIMIT_LOGIN_IP" default:"30/1m"`
+	LoginAccount    string `yaml:"login_account" env:"RATE_LIMIT_LOGIN_ACCOUNT" default:"5/15m"`
+	RegisterIP      string `yaml:"register_ip" env:"RATE_LIMIT_REGISTER_IP" default:"10/1h"`
+	OTPIP           string `yaml:"otp_ip" env:"RATE_LIMIT_OTP_IP" default:"20/10m"`
+	OTPPhone        string `yaml:"otp_phone" env:"RATE_LIMIT_OTP_PHONE" default:"5/10m"`
+	TransactionUser string `yaml:"transaction_user" env:"RATE_LIMIT_TRANSACTION_USER" default:"30/1m"`
+	EmailUser       string `yaml:"email_user" env:"RATE_LIMIT_EMAIL_USER" default:"5/10m"`
+}
+
+type FX struct {
+	RatesFile string `yaml:"rates_file" env:"FX_RATES_FILE" default:"./migrations/json/fx_rates.json"`
+}
+
+func (c Config) IsProduction() bool {
+	return c.App.Env == constants.ENUM_RUN_PRODUCTION
+}
+
+// IsDevelopment is a run on a developer's machine, where plain http and
+// throwaway keys are acceptable.
+func (c Config) IsDevelopment() bool {
+	return c.App.Env == constants.ENUM_RUN_LOCALHOST
+}
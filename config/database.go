@@ -2,39 +2,18 @@ package config
 
 import (
 	"fmt"
-	"os"
 
-	"github.com/Amierza/e-wallet/constants"
-	"github.com/joho/godotenv"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
-func SetUpDatabaseConnection() *gorm.DB {
-	if os.Getenv("APP_ENV") != constants.ENUM_RUN_PRODUCTION {
-		err := godotenv.Load(".env")
-		if err != nil {
-			panic(err)
-		}
-	}
-
-	dbUser := os.Getenv("DB_USER")
-	dbPass := os.Getenv("DB_PASS")
-	dbHost := os.Getenv("DB_HOST")
-	dbName := os.Getenv("DB_NAME")
-	dbPort := os.Getenv("DB_PORT")
+func SetUpDatabaseConnection(cfg Database) (*gorm.DB, error) {
+	dsn := fmt.Sprintf("host=%v user=%v password=%v dbname=%v port=%v TimeZone=%v", cfg.Host, cfg.User, cfg.Password, cfg.Name, cfg.Port, cfg.TimeZone)
 
-	dsn := fmt.Sprintf("host=%v user=%v password=%v dbname=%v port=%v TimeZone=Asia/Jakarta", dbHost, dbUser, dbPass, dbName, dbPort)
-
-	db, err := gorm.Open(postgres.New(postgres.Config{
+	return gorm.Open(postgres.New(postgres.Config{
 		DSN:                  dsn,
 		PreferSimpleProtocol: true,
 	}), &gorm.Config{})
-	if err != nil {
-		panic(err)
-	}
-
-	return db
 }
 
 func CloseDatabaseConnection(db *gorm.DB) {
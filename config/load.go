@@ -0,0 +1,160 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Amierza/e-wallet/constants"
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+const devSigningSecret = "Template"
+
+// Load builds the config from, in increasing precedence: the defaults on the
+// struct tags, the YAML file named by CONFIG_FILE, the .env file and finally
+// the process environment. .env never overrides a variable that is already
+// set and is skipped entirely in production.
+//
+// The returned config is filled in even when validation fails, so callers can
+// still print it; the error then lists every problem at once.
+func Load() (Config, error) {
+	var (
+		cfg      Config
+		problems []string
+	)
+
+	if os.Getenv("APP_ENV") != constants.ENUM_RUN_PRODUCTION {
+		if err := godotenv.Load(".env"); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			problems = append(problems, fmt.Sprintf(".env: %v", err))
+		}
+	}
+
+	walk(reflect.ValueOf(&cfg).Elem(), func(field reflect.Value, tag reflect.StructTag) {
+		if value, ok := tag.Lookup("default"); ok {
+			if err := setField(field, value); err != nil {
+				panic(fmt.Sprintf("config: bad default for %s: %v", tag.Get("env"), err))
+			}
+		}
+	})
+
+	if path := os.Getenv("CONFIG_FILE"); path != "" {
+		if err := loadYAML(path, &cfg); err != nil {
+			problems = append(problems, fmt.Sprintf("CONFIG_FILE %s: %v", path, err))
+		}
+	}
+
+	walk(reflect.ValueOf(&cfg).Elem(), func(field reflect.Value, tag reflect.StructTag) {
+		key := tag.Get("env")
+		value, ok := os.LookupEnv(key)
+		if key == "" || !ok {
+			return
+		}
+		if err := setField(field, value); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", key, err))
+		}
+	})
+
+	cfg.resolveFallbacks()
+	problems = append(problems, cfg.validate()...)
+
+	if len(problems) > 0 {
+		return cfg, &ValidationError{Problems: problems}
+	}
+	return cfg, nil
+}
+
+func loadYAML(path string, cfg *Config) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return nil
+}
+
+// resolveFallbacks fills settings that default to another setting rather than
+// to a constant.
+func (c *Config) resolveFallbacks() {
+	if c.Secrets.Signing == "" && !c.IsProduction() {
+		c.Secrets.Signing = devSigningSecret
+	}
+	if c.Secrets.OTP == "" {
+		c.Secrets.OTP = c.Secrets.Signing
+	}
+	if c.Secrets.TOTPKey == "" {
+		c.Secrets.TOTPKey = c.Secrets.Signing
+	}
+}
+
+// walk calls fn for every leaf field of a config struct, depth first in
+// declaration order.
+func walk(v reflect.Value, fn func(field reflect.Value, tag reflect.StructTag)) {
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+		info := v.Type().Field(i)
+
+		if field.Kind() == reflect.Struct && field.Type() != durationType {
+			walk(field, fn)
+			continue
+		}
+		fn(field, info.Tag)
+	}
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+func setField(field reflect.Value, value string) error {
+	switch {
+	case field.Type() == durationType:
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("%q is not a duration such as 90s or 2h", value)
+		}
+		field.SetInt(int64(d))
+	case field.Kind() == reflect.String:
+		field.SetString(value)
+	case field.Kind() == reflect.Int:
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			return fmt.Errorf("%q is not an integer", value)
+		}
+		field.SetInt(int64(n))
+	case field.Kind() == reflect.Float64:
+		f, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return fmt.Errorf("%q is not a number", value)
+		}
+		field.SetFloat(f)
+	case field.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(strings.TrimSpace(value))
+		if err != nil {
+			return fmt.Errorf("%q is not a boolean", value)
+		}
+		field.SetBool(b)
+	case field.Kind() == reflect.Slice && field.Type().Elem().Kind() == reflect.String:
+		items := []string{}
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		field.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported config field type %s", field.Type())
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package config
+
+import (
+	"io"
+	"reflect"
+
+	"gopkg.in/yaml.v3"
+)
+
+const redacted = "[redacted]"
+
+// Redacted returns a copy with every secret that is set replaced, so it is
+// safe to log or print.
+func (c Config) Redacted() Config {
+	walk(reflect.ValueOf(&c).Elem(), func(field reflect.Value, tag reflect.StructTag) {
+		if tag.Get("secret") == "true" && field.Kind() == reflect.String && field.String() != "" {
+			field.SetString(redacted)
+		}
+	})
+	return c
+}
+
+// Print writes the redacted config as YAML, in the same shape CONFIG_FILE
+// accepts.
+func (c Config) Print(w io.Writer) error {
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(c.Redacted()); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
@@ -0,0 +1,171 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Amierza/e-wallet/constants"
+	"github.com/Amierza/e-wallet/ratelimit"
+)
+
+// ValidationError lists every missing or invalid setting, so a bad deploy is
+// fixed in one go instead of one restart per key.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+func (c Config) validate() []string {
+	var problems []string
+	add := func(format string, args ...any) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+	oneOf := func(key string, value string, allowed ...string) {
+		if !slices.Contains(allowed, value) {
+			add("%s: %q must be one of %s", key, value, strings.Join(allowed, ", "))
+		}
+	}
+	required := func(key string, value string) {
+		if strings.TrimSpace(value) == "" {
+			add("%s is required", key)
+		}
+	}
+
+	walk(reflect.ValueOf(&c).Elem(), func(field reflect.Value, tag reflect.StructTag) {
+		if tag.Get("required") == "true" && field.Kind() == reflect.String {
+			required(tag.Get("env"), field.String())
+		}
+	})
+
+	if c.App.Port < 1 || c.App.Port > 65535 {
+		add("PORT: %d is not a valid port", c.App.Port)
+	}
+	oneOf("LOG_LEVEL", c.Log.Level, constants.ENUM_LOG_LEVEL_DEBUG, constants.ENUM_LOG_LEVEL_INFO, constants.ENUM_LOG_LEVEL_WARN, constants.ENUM_LOG_LEVEL_ERROR)
+	oneOf("LOG_FORMAT", c.Log.Format, constants.ENUM_LOG_FORMAT_JSON, constants.ENUM_LOG_FORMAT_TEXT)
+	if c.Log.SlowQuery <= 0 {
+		add("LOG_SLOW_QUERY must be positive")
+	}
+	oneOf("TRACING_EXPORTER", c.Tracing.Exporter, constants.ENUM_TRACING_EXPORTER_NONE, constants.ENUM_TRACING_EXPORTER_STDOUT, constants.ENUM_TRACING_EXPORTER_OTLP)
+	if c.Tracing.Exporter == constants.ENUM_TRACING_EXPORTER_OTLP {
+		if endpoint, err := url.Parse(c.Tracing.OTLPEndpoint); err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+			add("TRACING_OTLP_ENDPOINT: %q must be an http or https URL", c.Tracing.OTLPEndpoint)
+		}
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		add("TRACING_SAMPLE_RATIO: %v must be between 0 and 1", c.Tracing.SampleRatio)
+	}
+	timeouts := []struct {
+		key   string
+		value time.Duration
+	}{
+		{"SERVER_READ_TIMEOUT", c.Server.ReadTimeout},
+		{"SERVER_READ_HEADER_TIMEOUT", c.Server.ReadHeaderTimeout},
+		{"SERVER_WRITE_TIMEOUT", c.Server.WriteTimeout},
+		{"SERVER_IDLE_TIMEOUT", c.Server.IdleTimeout},
+		{"SERVER_SHUTDOWN_TIMEOUT", c.Server.ShutdownTimeout},
+	}
+	for _, timeout := range timeouts {
+		if timeout.value <= 0 {
+			add("%s must be positive", timeout.key)
+		}
+	}
+	if c.Server.ShutdownDelay < 0 {
+		add("SERVER_SHUTDOWN_DELAY must not be negative")
+	}
+	for _, proxy := range c.Server.TrustedProxies {
+		if _, _, err := net.ParseCIDR(proxy); err != nil && net.ParseIP(proxy) == nil {
+			add("SERVER_TRUSTED_PROXIES: %q is not an IP or CIDR", proxy)
+		}
+	}
+	if c.Server.MaxHeaderBytes < 4096 {
+		add("SERVER_MAX_HEADER_BYTES: %d is too small", c.Server.MaxHeaderBytes)
+	}
+	if c.Database.Port < 1 || c.Database.Port > 65535 {
+		add("DB_PORT: %d is not a valid port", c.Database.Port)
+	}
+	if _, err := time.LoadLocation(c.Database.TimeZone); err != nil {
+		add("DB_TIMEZONE: %q is not a known time zone", c.Database.TimeZone)
+	}
+
+	if c.JWT.AccessTTL <= 0 {
+		add("JWT_ACCESS_TTL must be positive")
+	}
+	if c.JWT.RefreshTTL <= c.JWT.AccessTTL {
+		add("JWT_REFRESH_TTL must be longer than JWT_ACCESS_TTL")
+	}
+
+	if c.IsProduction() {
+		required("APP_URL", c.App.URL)
+		required("JWT_SIGNING_KEY_FILE", c.JWT.SigningKeyFile)
+		required("JWT_SECRET", c.Secrets.Signing)
+	}
+
+	oneOf("PIN_HASH_ALGORITHM", c.PIN.HashAlgorithm, "bcrypt", "argon2id")
+	if c.PIN.BcryptCost < 4 || c.PIN.BcryptCost > 31 {
+		add("PIN_BCRYPT_COST: %d must be between 4 and 31", c.PIN.BcryptCost)
+	}
+	if c.PIN.Argon2MemoryKB < 8*c.PIN.Argon2Threads || c.PIN.Argon2MemoryKB <= 0 {
+		add("PIN_ARGON2_MEMORY_KB: %d is too small", c.PIN.Argon2MemoryKB)
+	}
+	if c.PIN.Argon2Time < 1 {
+		add("PIN_ARGON2_TIME must be at least 1")
+	}
+	if c.PIN.Argon2Threads < 1 || c.PIN.Argon2Threads > 255 {
+		add("PIN_ARGON2_THREADS: %d must be between 1 and 255", c.PIN.Argon2Threads)
+	}
+
+	// The log sink prints one-time codes and links, so production must
+	// deliver them for real.
+	if c.IsProduction() {
+		oneOf("NOTIFIER_SMS", c.Notifier.SMS, constants.ENUM_NOTIFIER_HTTP)
+		oneOf("NOTIFIER_EMAIL", c.Notifier.Email, constants.ENUM_NOTIFIER_SMTP)
+	} else {
+		oneOf("NOTIFIER_SMS", c.Notifier.SMS, constants.ENUM_NOTIFIER_LOG, constants.ENUM_NOTIFIER_HTTP)
+		oneOf("NOTIFIER_EMAIL", c.Notifier.Email, constants.ENUM_NOTIFIER_LOG, constants.ENUM_NOTIFIER_SMTP)
+	}
+	if c.Notifier.SMS == constants.ENUM_NOTIFIER_HTTP {
+		required("SMS_GATEWAY_URL", c.Notifier.SMSGateway.URL)
+		if c.IsProduction() {
+			required("SMS_GATEWAY_TOKEN", c.Notifier.SMSGateway.Token)
+		}
+	}
+	if c.Notifier.Email == constants.ENUM_NOTIFIER_SMTP {
+		required("SMTP_HOST", c.Notifier.SMTP.Host)
+		required("SMTP_PORT", c.Notifier.SMTP.Port)
+		required("SMTP_AUTH_EMAIL", c.Notifier.SMTP.AuthEmail)
+		if c.IsProduction() {
+			required("SMTP_AUTH_PASSWORD", c.Notifier.SMTP.Password)
+		}
+	}
+
+	oneOf("REALTIME_BACKEND", c.Realtime.Backend, constants.ENUM_REALTIME_BACKEND_MEMORY, constants.ENUM_REALTIME_BACKEND_POSTGRES)
+
+	oneOf("RATE_LIMIT_STORE", c.RateLimit.Store, constants.ENUM_RATE_LIMIT_STORE_MEMORY, constants.ENUM_RATE_LIMIT_STORE_POSTGRES)
+	policies := []struct {
+		key  string
+		spec string
+	}{
+		{"RATE_LIMIT_LOGIN_IP", c.RateLimit.LoginIP},
+		{"RATE_LIMIT_LOGIN_ACCOUNT", c.RateLimit.LoginAccount},
+		{"RATE_LIMIT_REGISTER_IP", c.RateLimit.RegisterIP},
+		{"RATE_LIMIT_OTP_IP", c.RateLimit.OTPIP},
+		{"RATE_LIMIT_OTP_PHONE", c.RateLimit.OTPPhone},
+		{"RATE_LIMIT_TRANSACTION_USER", c.RateLimit.TransactionUser},
+		{"RATE_LIMIT_EMAIL_USER", c.RateLimit.EmailUser},
+	}
+	for _, policy := range policies {
+		if _, err := ratelimit.ParsePolicy(policy.key, policy.spec); err != nil {
+			add("%s: %v", policy.key, err)
+		}
+	}
+
+	return problems
+}
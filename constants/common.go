@@ -6,7 +6,107 @@ const (
 
 	ENUM_RUN_PRODUCTION = "production"
 	ENUM_RUN_TESTING    = "testing"
+	ENUM_RUN_LOCALHOST  = "localhost"
+
+	ENUM_LOG_LEVEL_DEBUG = "debug"
+	ENUM_LOG_LEVEL_INFO  = "info"
+	ENUM_LOG_LEVEL_WARN  = "warn"
+	ENUM_LOG_LEVEL_ERROR = "error"
+
+	ENUM_LOG_FORMAT_JSON = "json"
+	ENUM_LOG_FORMAT_TEXT = "text"
+
+	ENUM_TRACING_EXPORTER_NONE   = "none"
+	ENUM_TRACING_EXPORTER_STDOUT = "stdout"
+	ENUM_TRACING_EXPORTER_OTLP   = "otlp"
+
+	ENUM_RATE_LIMIT_STORE_MEMORY   = "memory"
+	ENUM_RATE_LIMIT_STORE_POSTGRES = "postgres"
+
+	ENUM_REQUEST_ID_HEADER     = "X-Request-ID"
+	ENUM_REQUEST_ID_MAX_LENGTH = 128
 
 	ENUM_PAGINATION_LIMIT = 10
 	ENUM_PAGINATION_PAGE  = 1
+
+	ENUM_CURRENCY_DEFAULT = "IDR"
+
+	ENUM_PIN_LENGTH  = 6
+	ENUM_PIN_HISTORY = 5
+
+	ENUM_FX_QUOTE_TTL_SECOND = 30
+	ENUM_FX_RATES_FILE       = "./migrations/json/fx_rates.json"
+
+	ENUM_TRANSACTION_TOPUP    = "top_up"
+	ENUM_TRANSACTION_PAYMENT  = "payment"
+	ENUM_TRANSACTION_TRANSFER = "transfer"
+
+	ENUM_EVENT_TOPUP_COMPLETED    = "topup.completed"
+	ENUM_EVENT_PAYMENT_COMPLETED  = "payment.completed"
+	ENUM_EVENT_TRANSFER_COMPLETED = "transfer.completed"
+	ENUM_EVENT_TRANSFER_RECEIVED  = "transfer.received"
+	ENUM_EVENT_SESSION_REVOKED    = "session.revoked"
+
+	ENUM_HEALTH_STATUS_OK            = "ok"
+	ENUM_HEALTH_STATUS_FAIL          = "fail"
+	ENUM_HEALTH_CHECK_TIMEOUT_SECOND = 2
+
+	ENUM_OUTBOX_STATUS_PENDING   = "pending"
+	ENUM_OUTBOX_STATUS_PUBLISHED = "published"
+	ENUM_OUTBOX_STATUS_FAILED    = "failed"
+
+	ENUM_OUTBOX_MAX_ATTEMPT          = 20
+	ENUM_OUTBOX_POLL_INTERVAL_SECOND = 1
+	ENUM_OUTBOX_CLAIM_LEASE_SECOND   = 60
+	ENUM_OUTBOX_RELAY_BATCH          = 100
+
+	ENUM_REALTIME_BACKEND_MEMORY   = "memory"
+	ENUM_REALTIME_BACKEND_POSTGRES = "postgres"
+	ENUM_REALTIME_CHANNEL          = "wallet_realtime"
+
+	ENUM_REALTIME_PING_INTERVAL_SECOND = 30
+	ENUM_REALTIME_PONG_WAIT_SECOND     = 60
+	ENUM_REALTIME_WRITE_WAIT_SECOND    = 10
+
+	ENUM_OTP_PURPOSE_PHONE_VERIFICATION = "phone_verification"
+	ENUM_OTP_PURPOSE_PIN_RESET          = "pin_reset"
+
+	ENUM_OTP_LENGTH                 = 6
+	ENUM_OTP_TTL_SECOND             = 300
+	ENUM_OTP_MAX_ATTEMPT            = 5
+	ENUM_OTP_RESEND_COOLDOWN_SECOND = 60
+
+	ENUM_SCOPE_MFA_CHALLENGE = "mfa_challenge"
+
+	ENUM_TOTP_ISSUER              = "E-Wallet"
+	ENUM_TOTP_SKEW_STEP           = 1
+	ENUM_TOTP_RECOVERY_CODES      = 10
+	ENUM_TOTP_MAX_ATTEMPT         = 5
+	ENUM_TOTP_LOCK_SECOND         = 900
+	ENUM_MFA_CHALLENGE_TTL_SECOND = 300
+
+	ENUM_DEVICE_TRUST_SECOND     = 86400
+	ENUM_DEVICE_LAST_SEEN_SECOND = 60
+
+	ENUM_EMAIL_VERIFICATION_TTL_SECOND = 86400
+	ENUM_PIN_RESET_TOKEN_TTL_SECOND    = 600
+
+	ENUM_STATEMENT_DEFAULT_DAYS = 30
+	ENUM_STATEMENT_MAX_DAYS     = 366
+
+	ENUM_NOTIFIER_LOG  = "log"
+	ENUM_NOTIFIER_SMTP = "smtp"
+	ENUM_NOTIFIER_HTTP = "http"
+
+	ENUM_WEBHOOK_STATUS_PENDING   = "pending"
+	ENUM_WEBHOOK_STATUS_SUCCEEDED = "succeeded"
+	ENUM_WEBHOOK_STATUS_FAILED    = "failed"
+
+	ENUM_WEBHOOK_MAX_ATTEMPT          = 8
+	ENUM_WEBHOOK_BACKOFF_BASE_SECOND  = 10
+	ENUM_WEBHOOK_BACKOFF_MAX_SECOND   = 3600
+	ENUM_WEBHOOK_POLL_INTERVAL_SECOND = 5
+	ENUM_WEBHOOK_TIMEOUT_SECOND       = 10
+	ENUM_WEBHOOK_CLAIM_LEASE_SECOND   = 60
+	ENUM_WEBHOOK_DISPATCH_BATCH       = 50
 )
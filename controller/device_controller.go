@@ -0,0 +1,54 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Amierza/e-wallet/dto"
+	"github.com/Amierza/e-wallet/service"
+	"github.com/Amierza/e-wallet/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type (
+	DeviceController interface {
+		GetAllDevice(ctx *gin.Context)
+		RevokeDevice(ctx *gin.Context)
+	}
+	deviceController struct {
+		deviceService service.DeviceService
+	}
+)
+
+func NewDeviceController(ds service.DeviceService) DeviceController {
+	return &deviceController{
+		deviceService: ds,
+	}
+}
+
+func (c *deviceController) GetAllDevice(ctx *gin.Context) {
+	result, err := c.deviceService.GetAllDevice(ctx.Request.Context())
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_LIST_DEVICE, err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_GET_LIST_DEVICE, result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *deviceController) RevokeDevice(ctx *gin.Context) {
+	if err := c.deviceService.RevokeDevice(ctx.Request.Context(), ctx.Param("id")); err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, dto.ErrDeviceNotFound) {
+			status = http.StatusNotFound
+		}
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_REVOKE_DEVICE, err.Error(), nil)
+		ctx.JSON(status, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_REVOKE_DEVICE, nil)
+	ctx.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,90 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/Amierza/e-wallet/dto"
+	"github.com/Amierza/e-wallet/service"
+	"github.com/Amierza/e-wallet/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type (
+	FXController interface {
+		GetAllRate(ctx *gin.Context)
+		UpsertRate(ctx *gin.Context)
+		CreateQuote(ctx *gin.Context)
+		GetRevenue(ctx *gin.Context)
+	}
+	fxController struct {
+		fxService service.FXService
+	}
+)
+
+func NewFXController(fs service.FXService) FXController {
+	return &fxController{
+		fxService: fs,
+	}
+}
+
+func (c *fxController) GetAllRate(ctx *gin.Context) {
+	result, err := c.fxService.GetAllRates(ctx.Request.Context())
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_LIST_FX_RATE, err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_GET_LIST_FX_RATE, result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *fxController) UpsertRate(ctx *gin.Context) {
+	var req dto.FXRateUpsertRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := c.fxService.UpsertRates(ctx.Request.Context(), req)
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_UPDATE_FX_RATE, err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_UPDATE_FX_RATE, result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *fxController) CreateQuote(ctx *gin.Context) {
+	var req dto.FXQuoteRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := c.fxService.CreateQuote(ctx.Request.Context(), req)
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_CREATE_FX_QUOTE, err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_CREATE_FX_QUOTE, result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *fxController) GetRevenue(ctx *gin.Context) {
+	result, err := c.fxService.GetRevenueSummary(ctx.Request.Context())
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_FX_REVENUE, err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_GET_FX_REVENUE, result)
+	ctx.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,52 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/Amierza/e-wallet/constants"
+	"github.com/Amierza/e-wallet/dto"
+	"github.com/Amierza/e-wallet/service"
+	"github.com/Amierza/e-wallet/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type (
+	HealthController interface {
+		Healthz(ctx *gin.Context)
+		Readyz(ctx *gin.Context)
+		Version(ctx *gin.Context)
+	}
+	healthController struct {
+		healthService service.HealthService
+	}
+)
+
+func NewHealthController(hs service.HealthService) HealthController {
+	return &healthController{
+		healthService: hs,
+	}
+}
+
+// Healthz only tells the process is up and serving; it checks nothing else,
+// so a database outage never gets the pod restarted.
+func (c *healthController) Healthz(ctx *gin.Context) {
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_ALIVE, dto.LivenessResponse{Status: constants.ENUM_HEALTH_STATUS_OK})
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *healthController) Readyz(ctx *gin.Context) {
+	result, ready := c.healthService.Ready(ctx.Request.Context())
+	if !ready {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_NOT_READY, dto.ErrNotReady.Error(), result)
+		ctx.JSON(http.StatusServiceUnavailable, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_READY, result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *healthController) Version(ctx *gin.Context) {
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_GET_VERSION, c.healthService.Version())
+	ctx.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,141 @@
+package controller
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/Amierza/e-wallet/constants"
+	"github.com/Amierza/e-wallet/dto"
+	"github.com/Amierza/e-wallet/service"
+	"github.com/Amierza/e-wallet/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+type (
+	RealtimeController interface {
+		WebSocket(ctx *gin.Context)
+		Stream(ctx *gin.Context)
+	}
+	realtimeController struct {
+		realtimeService service.RealtimeService
+		upgrader        websocket.Upgrader
+	}
+)
+
+func NewRealtimeController(rs service.RealtimeService) RealtimeController {
+	return &realtimeController{
+		realtimeService: rs,
+		upgrader: websocket.Upgrader{
+			ReadBufferSize:  1024,
+			WriteBufferSize: 1024,
+			// Same policy as CORSMiddleware; the bearer token is what authenticates.
+			CheckOrigin: func(r *http.Request) bool { return true },
+		},
+	}
+}
+
+func (c *realtimeController) WebSocket(ctx *gin.Context) {
+	messages, cancel, err := c.realtimeService.Subscribe(ctx.Request.Context())
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_SUBSCRIBE_REALTIME, err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+	defer cancel()
+
+	conn, err := c.upgrader.Upgrade(ctx.Writer, ctx.Request, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	pongWait := constants.ENUM_REALTIME_PONG_WAIT_SECOND * time.Second
+	writeWait := constants.ENUM_REALTIME_WRITE_WAIT_SECOND * time.Second
+
+	// Clients only send control frames; the read loop exists to process pongs
+	// and notice when the connection goes away.
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		conn.SetReadLimit(512)
+		conn.SetReadDeadline(time.Now().Add(pongWait))
+		conn.SetPongHandler(func(string) error {
+			return conn.SetReadDeadline(time.Now().Add(pongWait))
+		})
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(constants.ENUM_REALTIME_PING_INTERVAL_SECOND * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case msg, ok := <-messages:
+			conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if !ok {
+				conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "reconnect"))
+				return
+			}
+			if err := conn.WriteJSON(msg); err != nil {
+				return
+			}
+			if msg.Final() {
+				conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.ClosePolicyViolation, msg.Type))
+				return
+			}
+		case <-ticker.C:
+			conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		case <-closed:
+			return
+		}
+	}
+}
+
+func (c *realtimeController) Stream(ctx *gin.Context) {
+	messages, cancel, err := c.realtimeService.Subscribe(ctx.Request.Context())
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_SUBSCRIBE_REALTIME, err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+	defer cancel()
+
+	ctx.Header("Content-Type", "text/event-stream")
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("Connection", "keep-alive")
+	ctx.Header("X-Accel-Buffering", "no")
+
+	heartbeat := time.NewTicker(constants.ENUM_REALTIME_PING_INTERVAL_SECOND * time.Second)
+	defer heartbeat.Stop()
+
+	// The server's WriteTimeout would cut the stream off, so each write gets
+	// its own deadline instead.
+	writeWait := constants.ENUM_REALTIME_WRITE_WAIT_SECOND * time.Second
+	rc := http.NewResponseController(ctx.Writer)
+
+	ctx.Stream(func(w io.Writer) bool {
+		rc.SetWriteDeadline(time.Now().Add(writeWait))
+		select {
+		case msg, ok := <-messages:
+			if !ok {
+				return false
+			}
+			ctx.SSEvent(msg.Type, msg)
+			return !msg.Final()
+		case <-heartbeat.C:
+			_, err := io.WriteString(w, ": ping\n\n")
+			return err == nil
+		case <-ctx.Request.Context().Done():
+			return false
+		}
+	})
+}
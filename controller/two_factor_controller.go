@@ -0,0 +1,127 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Amierza/e-wallet/dto"
+	"github.com/Amierza/e-wallet/service"
+	"github.com/Amierza/e-wallet/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type (
+	TwoFactorController interface {
+		Enroll(ctx *gin.Context)
+		Confirm(ctx *gin.Context)
+		Disable(ctx *gin.Context)
+		RegenerateRecoveryCodes(ctx *gin.Context)
+		CompleteLogin(ctx *gin.Context)
+	}
+	twoFactorController struct {
+		twoFactorService service.TwoFactorService
+	}
+)
+
+func NewTwoFactorController(ts service.TwoFactorService) TwoFactorController {
+	return &twoFactorController{
+		twoFactorService: ts,
+	}
+}
+
+func twoFactorStatus(err error) int {
+	if errors.Is(err, dto.ErrTwoFactorLocked) {
+		return http.StatusTooManyRequests
+	}
+	return http.StatusBadRequest
+}
+
+func (c *twoFactorController) Enroll(ctx *gin.Context) {
+	result, err := c.twoFactorService.Enroll(ctx.Request.Context())
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_ENROLL_TWO_FACTOR, err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_ENROLL_TWO_FACTOR, result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *twoFactorController) Confirm(ctx *gin.Context) {
+	var req dto.TwoFactorCodeRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := c.twoFactorService.Confirm(ctx.Request.Context(), req)
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_CONFIRM_TWO_FACTOR, err.Error(), nil)
+		ctx.JSON(twoFactorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_CONFIRM_TWO_FACTOR, result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *twoFactorController) Disable(ctx *gin.Context) {
+	var req dto.TwoFactorDisableRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	if err := c.twoFactorService.Disable(ctx.Request.Context(), req); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_DISABLE_TWO_FACTOR, err.Error(), nil)
+		ctx.JSON(twoFactorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_DISABLE_TWO_FACTOR, nil)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *twoFactorController) RegenerateRecoveryCodes(ctx *gin.Context) {
+	var req dto.TwoFactorCodeRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := c.twoFactorService.RegenerateRecoveryCodes(ctx.Request.Context(), req)
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_REGENERATE_RECOVERY, err.Error(), nil)
+		ctx.JSON(twoFactorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_REGENERATE_RECOVERY, result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *twoFactorController) CompleteLogin(ctx *gin.Context) {
+	var req dto.TwoFactorLoginRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	req.IPAddress = ctx.ClientIP()
+	req.UserAgent = ctx.Request.UserAgent()
+
+	result, err := c.twoFactorService.CompleteLogin(ctx.Request.Context(), req)
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_COMPLETE_TWO_FACTOR, err.Error(), nil)
+		ctx.JSON(twoFactorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_LOGIN_USER, result)
+	ctx.JSON(http.StatusOK, res)
+}
@@ -1,6 +1,7 @@
 package controller
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/Amierza/e-wallet/dto"
@@ -19,6 +20,17 @@ type (
 		Transfer(ctx *gin.Context)
 		GetAllTransaction(ctx *gin.Context)
 		UpdateProfile(ctx *gin.Context)
+		GetAllWallet(ctx *gin.Context)
+		OpenWallet(ctx *gin.Context)
+		VerifyPhone(ctx *gin.Context)
+		ResendPhoneOTP(ctx *gin.Context)
+		VerifyEmail(ctx *gin.Context)
+		ResendEmailVerification(ctx *gin.Context)
+		SendStatement(ctx *gin.Context)
+		ForgotPin(ctx *gin.Context)
+		VerifyPinReset(ctx *gin.Context)
+		ResetPin(ctx *gin.Context)
+		ChangePin(ctx *gin.Context)
 	}
 	userController struct {
 		userService service.UserService
@@ -58,6 +70,9 @@ func (c *userController) Login(ctx *gin.Context) {
 		return
 	}
 
+	req.IPAddress = ctx.ClientIP()
+	req.UserAgent = ctx.Request.UserAgent()
+
 	result, err := c.userService.LoginUser(ctx.Request.Context(), req)
 	if err != nil {
 		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_LOGIN_USER, err.Error(), nil)
@@ -194,3 +209,205 @@ func (c *userController) UpdateProfile(ctx *gin.Context) {
 	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_UPDATE_PROFILE_USER, result)
 	ctx.JSON(http.StatusOK, res)
 }
+
+func (c *userController) GetAllWallet(ctx *gin.Context) {
+	result, err := c.userService.GetAllWallet(ctx.Request.Context())
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_LIST_WALLET, err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_GET_LIST_WALLET, result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *userController) OpenWallet(ctx *gin.Context) {
+	var req dto.OpenWalletRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := c.userService.OpenWallet(ctx.Request.Context(), req)
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_OPEN_WALLET, err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_OPEN_WALLET, result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *userController) VerifyPhone(ctx *gin.Context) {
+	var req dto.VerifyPhoneRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := c.userService.VerifyPhone(ctx.Request.Context(), req)
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_VERIFY_PHONE, err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_VERIFY_PHONE, result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *userController) ResendPhoneOTP(ctx *gin.Context) {
+	var req dto.ResendOTPRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := c.userService.ResendPhoneOTP(ctx.Request.Context(), req)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, dto.ErrOTPResendCooldown) {
+			status = http.StatusTooManyRequests
+		}
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_RESEND_OTP, err.Error(), nil)
+		ctx.JSON(status, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_RESEND_OTP, result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *userController) VerifyEmail(ctx *gin.Context) {
+	var req dto.VerifyEmailRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := c.userService.VerifyEmail(ctx.Request.Context(), req)
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_VERIFY_EMAIL, err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_VERIFY_EMAIL, result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *userController) ResendEmailVerification(ctx *gin.Context) {
+	result, err := c.userService.ResendEmailVerification(ctx.Request.Context())
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_SEND_VERIFY_EMAIL, err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_SEND_VERIFY_EMAIL, result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *userController) SendStatement(ctx *gin.Context) {
+	var req dto.StatementRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := c.userService.SendStatement(ctx.Request.Context(), req)
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_SEND_STATEMENT, err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_SEND_STATEMENT, result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *userController) ForgotPin(ctx *gin.Context) {
+	var req dto.ForgotPinRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := c.userService.ForgotPin(ctx.Request.Context(), req)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, dto.ErrOTPResendCooldown) {
+			status = http.StatusTooManyRequests
+		}
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_FORGOT_PIN, err.Error(), nil)
+		ctx.JSON(status, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_FORGOT_PIN, result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *userController) VerifyPinReset(ctx *gin.Context) {
+	var req dto.VerifyPinResetRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := c.userService.VerifyPinReset(ctx.Request.Context(), req)
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_VERIFY_RESET, err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_VERIFY_RESET, result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *userController) ResetPin(ctx *gin.Context) {
+	var req dto.ResetPinRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	if err := c.userService.ResetPin(ctx.Request.Context(), req); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_RESET_PIN, err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_RESET_PIN, nil)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *userController) ChangePin(ctx *gin.Context) {
+	var req dto.ChangePinRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := c.userService.ChangePin(ctx.Request.Context(), req)
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_CHANGE_PIN, err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_CHANGE_PIN, result)
+	ctx.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,108 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/Amierza/e-wallet/dto"
+	"github.com/Amierza/e-wallet/service"
+	"github.com/Amierza/e-wallet/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type (
+	WebhookController interface {
+		CreateEndpoint(ctx *gin.Context)
+		GetAllEndpoint(ctx *gin.Context)
+		DeleteEndpoint(ctx *gin.Context)
+		GetAllDelivery(ctx *gin.Context)
+		Redeliver(ctx *gin.Context)
+	}
+	webhookController struct {
+		webhookService service.WebhookService
+	}
+)
+
+func NewWebhookController(ws service.WebhookService) WebhookController {
+	return &webhookController{
+		webhookService: ws,
+	}
+}
+
+func (c *webhookController) CreateEndpoint(ctx *gin.Context) {
+	var req dto.WebhookEndpointRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := c.webhookService.CreateEndpoint(ctx.Request.Context(), req)
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_CREATE_WEBHOOK_ENDPOINT, err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_CREATE_WEBHOOK_ENDPOINT, result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *webhookController) GetAllEndpoint(ctx *gin.Context) {
+	result, err := c.webhookService.GetAllEndpoint(ctx.Request.Context())
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_LIST_WEBHOOK_ENDPOINT, err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_GET_LIST_WEBHOOK_ENDPOINT, result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *webhookController) DeleteEndpoint(ctx *gin.Context) {
+	if err := c.webhookService.DeleteEndpoint(ctx.Request.Context(), ctx.Param("id")); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_DELETE_WEBHOOK_ENDPOINT, err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_DELETE_WEBHOOK_ENDPOINT, nil)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *webhookController) GetAllDelivery(ctx *gin.Context) {
+	var req dto.WebhookDeliveryPaginationRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := c.webhookService.GetAllDeliveryWithPagination(ctx.Request.Context(), req)
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_LIST_WEBHOOK_DELIVERY, err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	resp := utils.Response{
+		Status:  true,
+		Message: dto.MESSAGE_SUCCESS_GET_LIST_WEBHOOK_DELIVERY,
+		Data:    result.Data,
+		Meta:    result.PaginationResponse,
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+func (c *webhookController) Redeliver(ctx *gin.Context) {
+	result, err := c.webhookService.Redeliver(ctx.Request.Context(), ctx.Param("id"))
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_REDELIVER_WEBHOOK, err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_REDELIVER_WEBHOOK, result)
+	ctx.JSON(http.StatusOK, res)
+}
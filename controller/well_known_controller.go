@@ -0,0 +1,30 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/Amierza/e-wallet/service"
+	"github.com/gin-gonic/gin"
+)
+
+type (
+	WellKnownController interface {
+		JWKS(ctx *gin.Context)
+	}
+	wellKnownController struct {
+		jwtService service.JWTService
+	}
+)
+
+func NewWellKnownController(js service.JWTService) WellKnownController {
+	return &wellKnownController{
+		jwtService: js,
+	}
+}
+
+// JWKS is served bare rather than in the usual response envelope, because
+// JWT libraries expect a plain {"keys": [...]} document.
+func (c *wellKnownController) JWKS(ctx *gin.Context) {
+	ctx.Header("Cache-Control", "public, max-age=300")
+	ctx.JSON(http.StatusOK, c.jwtService.JWKS())
+}
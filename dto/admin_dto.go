@@ -0,0 +1,94 @@
+package dto
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Amierza/e-wallet/money"
+)
+
+const (
+	// Failed
+	MESSAGE_FAILED_CREATE_ADMIN        = "failed create admin"
+	MESSAGE_FAILED_FREEZE_USER         = "failed freeze user"
+	MESSAGE_FAILED_UNFREEZE_USER       = "failed unfreeze user"
+	MESSAGE_FAILED_GET_DETAIL_USER     = "failed get detail user"
+	MESSAGE_FAILED_REVOKE_TOKENS       = "failed revoke tokens"
+	MESSAGE_FAILED_EXPORT_TRANSACTIONS = "failed export transactions"
+
+	// Success
+	MESSAGE_SUCCESS_CREATE_ADMIN        = "success create admin"
+	MESSAGE_SUCCESS_FREEZE_USER         = "success freeze user"
+	MESSAGE_SUCCESS_UNFREEZE_USER       = "success unfreeze user"
+	MESSAGE_SUCCESS_GET_DETAIL_USER     = "success get detail user"
+	MESSAGE_SUCCESS_REVOKE_TOKENS       = "success revoke tokens"
+	MESSAGE_SUCCESS_EXPORT_TRANSACTIONS = "success export transactions"
+)
+
+var (
+	ErrUserNotFound        = errors.New("user not found")
+	ErrUserLookupRequired  = errors.New("user id or phone number is required")
+	ErrAccountNotFrozen    = errors.New("account is not frozen")
+	ErrInvalidExportPeriod = errors.New("export period is invalid")
+)
+
+type (
+	// UserLookupRequest identifies a user for operator commands by ID or,
+	// when that is empty, by phone number.
+	UserLookupRequest struct {
+		UserID      string
+		PhoneNumber string
+	}
+
+	AdminCreateRequest struct {
+		FirstName   string
+		LastName    string
+		PhoneNumber string
+		Email       string
+		Pin         string
+	}
+
+	UserDetailResponse struct {
+		UserResponse
+		ActiveDevices int `json:"active_devices"`
+	}
+
+	// RevokeTokensRequest signs out a single device when DeviceID is set and
+	// every session of the user otherwise.
+	RevokeTokensRequest struct {
+		UserLookupRequest
+		DeviceID string
+	}
+
+	RevokeTokensResponse struct {
+		UserID    string    `json:"user_id"`
+		DeviceID  string    `json:"device_id,omitempty"`
+		RevokedAt time.Time `json:"revoked_at"`
+	}
+
+	// ExportTransactionsRequest takes inclusive YYYY-MM-DD dates; either may
+	// be empty for an open-ended period. Without a user every account is
+	// exported.
+	ExportTransactionsRequest struct {
+		UserLookupRequest
+		From string
+		To   string
+	}
+
+	// TransactionExportResponse is one stored top-up, payment or transfer.
+	// Balances are the sender's, as on the stored row.
+	TransactionExportResponse struct {
+		Type           string        `json:"type"`
+		ID             string        `json:"id"`
+		UserID         string        `json:"user_id"`
+		TargetUserID   string        `json:"target_user_id,omitempty"`
+		Currency       string        `json:"currency"`
+		Amount         money.Amount  `json:"amount"`
+		TargetCurrency string        `json:"target_currency,omitempty"`
+		TargetAmount   *money.Amount `json:"target_amount,omitempty"`
+		BalanceBefore  money.Amount  `json:"balance_before"`
+		BalanceAfter   money.Amount  `json:"balance_after"`
+		Remarks        string        `json:"remarks,omitempty"`
+		CreatedAt      time.Time     `json:"created_at"`
+	}
+)
@@ -0,0 +1,55 @@
+package dto
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// Failed
+	MESSAGE_FAILED_GET_LIST_DEVICE = "failed get list device"
+	MESSAGE_FAILED_REVOKE_DEVICE   = "failed revoke device"
+
+	// Success
+	MESSAGE_SUCCESS_GET_LIST_DEVICE = "success get list device"
+	MESSAGE_SUCCESS_REVOKE_DEVICE   = "success revoke device"
+)
+
+var (
+	ErrDeviceNotFound       = errors.New("device not found")
+	ErrDeviceRevoked        = errors.New("device session was revoked")
+	ErrDeviceRequired       = errors.New("token is not bound to a device, please login again")
+	ErrDeviceNotEstablished = errors.New("this device was added less than 24 hours ago and cannot do this yet")
+	ErrCreateDevice         = errors.New("failed to register device")
+	ErrGetDevice            = errors.New("failed to get device")
+	ErrRevokeDevice         = errors.New("failed to revoke device")
+)
+
+type (
+	// DeviceInfo describes the client logging in. DeviceID is the ID returned
+	// by an earlier login on the same client; without it a new device is
+	// registered. IPAddress and UserAgent are filled in from the request.
+	DeviceInfo struct {
+		DeviceID   string `json:"device_id" form:"device_id"`
+		DeviceName string `json:"device_name" form:"device_name"`
+		Platform   string `json:"platform" form:"platform"`
+		PushToken  string `json:"push_token" form:"push_token"`
+		IPAddress  string `json:"-" form:"-"`
+		UserAgent  string `json:"-" form:"-"`
+	}
+
+	DeviceResponse struct {
+		ID          uuid.UUID `json:"device_id"`
+		Name        string    `json:"name"`
+		Platform    string    `json:"platform"`
+		IPAddress   string    `json:"ip_address"`
+		UserAgent   string    `json:"user_agent"`
+		Current     bool      `json:"current"`
+		Trusted     bool      `json:"trusted"`
+		TrustedFrom time.Time `json:"trusted_from"`
+		LastSeenAt  time.Time `json:"last_seen_at"`
+		CreatedAt   time.Time `json:"created_at"`
+	}
+)
@@ -0,0 +1,44 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/Amierza/e-wallet/money"
+)
+
+// Payloads of the domain events written to the outbox; webhook endpoints
+// receive them unchanged as the "data" field.
+type (
+	TopUpCompletedEvent struct {
+		UserID string `json:"user_id"`
+		TopUpResponse
+	}
+
+	PaymentCompletedEvent struct {
+		UserID string `json:"user_id"`
+		PaymentResponse
+	}
+
+	TransferCompletedEvent struct {
+		UserID string `json:"user_id"`
+		TransferResponse
+	}
+
+	TransferReceivedEvent struct {
+		UserID       string       `json:"user_id"`
+		TransferID   string       `json:"transfer_id"`
+		SenderUserID string       `json:"sender_user_id"`
+		Amount       money.Amount `json:"amount"`
+		Remarks      string       `json:"remarks"`
+		BalanceAfter money.Amount `json:"balance_after"`
+		ReceivedAt   time.Time    `json:"received_at"`
+	}
+
+	// SessionRevokedEvent ends the realtime streams of the revoked tokens:
+	// those of DeviceID, or of every device when it is empty.
+	SessionRevokedEvent struct {
+		UserID    string    `json:"user_id"`
+		DeviceID  string    `json:"device_id,omitempty"`
+		RevokedAt time.Time `json:"revoked_at"`
+	}
+)
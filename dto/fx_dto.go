@@ -0,0 +1,86 @@
+package dto
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Amierza/e-wallet/money"
+)
+
+const (
+	// Failed
+	MESSAGE_FAILED_GET_LIST_FX_RATE  = "failed get list fx rate"
+	MESSAGE_FAILED_UPDATE_FX_RATE    = "failed update fx rate"
+	MESSAGE_FAILED_CREATE_FX_QUOTE   = "failed create fx quote"
+	MESSAGE_FAILED_GET_FX_REVENUE    = "failed get fx revenue"
+	MESSAGE_FAILED_TOKEN_NOT_ALLOWED = "role not allowed"
+
+	// Success
+	MESSAGE_SUCCESS_GET_LIST_FX_RATE = "success get list fx rate"
+	MESSAGE_SUCCESS_UPDATE_FX_RATE   = "success update fx rate"
+	MESSAGE_SUCCESS_CREATE_FX_QUOTE  = "success create fx quote"
+	MESSAGE_SUCCESS_GET_FX_REVENUE   = "success get fx revenue"
+)
+
+var (
+	ErrInvalidFXRate      = errors.New("fx rate must be a positive decimal")
+	ErrInvalidFXSpread    = errors.New("fx spread must be between 0 and 10000 bps")
+	ErrSameCurrencyPair   = errors.New("fx pair must use two different currencies")
+	ErrFXRateNotFound     = errors.New("fx rate not found for currency pair")
+	ErrGetFXRates         = errors.New("failed to get fx rates")
+	ErrUpdateFXRate       = errors.New("failed to update fx rate")
+	ErrReadFXRateFile     = errors.New("failed to read fx rate file")
+	ErrCreateFXQuote      = errors.New("failed to create fx quote")
+	ErrFXQuoteNotFound    = errors.New("fx quote not found")
+	ErrFXQuoteExpired     = errors.New("fx quote is expired")
+	ErrFXQuoteAlreadyUsed = errors.New("fx quote is already used")
+	ErrFXQuoteMismatch    = errors.New("transfer does not match fx quote")
+	ErrFXAmountTooSmall   = errors.New("amount is too small to convert")
+	ErrCreateFXRevenue    = errors.New("failed to record fx revenue")
+	ErrGetFXRevenue       = errors.New("failed to get fx revenue")
+)
+
+type (
+	FXRateRequest struct {
+		BaseCurrency  string `json:"base_currency" binding:"required"`
+		QuoteCurrency string `json:"quote_currency" binding:"required"`
+		Rate          string `json:"rate" binding:"required"`
+		SpreadBps     int64  `json:"spread_bps"`
+	}
+
+	FXRateUpsertRequest struct {
+		Rates []FXRateRequest `json:"rates" binding:"required,dive"`
+	}
+
+	FXRateResponse struct {
+		ID            string    `json:"fx_rate_id"`
+		BaseCurrency  string    `json:"base_currency"`
+		QuoteCurrency string    `json:"quote_currency"`
+		Rate          string    `json:"rate"`
+		SpreadBps     int64     `json:"spread_bps"`
+		UpdatedAt     time.Time `json:"updated_at"`
+	}
+
+	FXQuoteRequest struct {
+		Amount     money.Amount `json:"amount"`
+		ToCurrency string       `json:"to_currency" binding:"required"`
+	}
+
+	FXQuoteResponse struct {
+		ID           string       `json:"quote_id"`
+		FromCurrency string       `json:"from_currency"`
+		ToCurrency   string       `json:"to_currency"`
+		MidRate      string       `json:"mid_rate"`
+		Rate         string       `json:"rate"`
+		SpreadBps    int64        `json:"spread_bps"`
+		SourceAmount money.Amount `json:"source_amount"`
+		TargetAmount money.Amount `json:"target_amount"`
+		ExpiresAt    time.Time    `json:"expires_at"`
+	}
+
+	FXRevenueResponse struct {
+		Currency string       `json:"currency"`
+		Amount   money.Amount `json:"amount"`
+		Count    int64        `json:"count"`
+	}
+)
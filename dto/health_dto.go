@@ -0,0 +1,49 @@
+package dto
+
+import (
+	"errors"
+)
+
+const (
+	// Failed
+	MESSAGE_FAILED_NOT_READY = "failed not ready"
+
+	// Success
+	MESSAGE_SUCCESS_ALIVE       = "success alive"
+	MESSAGE_SUCCESS_READY       = "success ready"
+	MESSAGE_SUCCESS_GET_VERSION = "success get version"
+)
+
+var (
+	ErrShuttingDown        = errors.New("server is shutting down")
+	ErrWorkerNotRunning    = errors.New("worker is not running")
+	ErrPendingMigrations   = errors.New("migrations are not applied")
+	ErrDatabaseUnreachable = errors.New("database is unreachable")
+	ErrGetMigrations       = errors.New("failed to read applied migrations")
+	ErrNotReady            = errors.New("one or more readiness checks failed")
+)
+
+type (
+	LivenessResponse struct {
+		Status string `json:"status"`
+	}
+
+	HealthCheckResponse struct {
+		Status     string  `json:"status"`
+		Error      string  `json:"error,omitempty"`
+		DurationMs float64 `json:"duration_ms"`
+	}
+
+	ReadinessResponse struct {
+		Status string                         `json:"status"`
+		Checks map[string]HealthCheckResponse `json:"checks"`
+	}
+
+	VersionResponse struct {
+		Version   string `json:"version"`
+		Commit    string `json:"commit"`
+		BuildTime string `json:"build_time"`
+		GoVersion string `json:"go_version"`
+		Modified  bool   `json:"modified,omitempty"`
+	}
+)
@@ -0,0 +1,59 @@
+package dto
+
+import (
+	"errors"
+
+	"github.com/Amierza/e-wallet/money"
+)
+
+const (
+	// Failed
+	MESSAGE_FAILED_VERIFY_LEDGER = "failed verify ledger"
+
+	// Success
+	MESSAGE_SUCCESS_VERIFY_LEDGER = "success verify ledger"
+)
+
+var (
+	ErrVerifyLedger       = errors.New("failed to verify ledger")
+	ErrLedgerInconsistent = errors.New("ledger is inconsistent")
+)
+
+type (
+	LedgerVerifyRequest struct {
+		UserID string
+	}
+
+	// LedgerWalletResponse compares a wallet balance with the balance its
+	// transaction history adds up to.
+	LedgerWalletResponse struct {
+		WalletID string       `json:"wallet_id"`
+		UserID   string       `json:"user_id"`
+		Currency string       `json:"currency"`
+		Balance  money.Amount `json:"balance"`
+		Expected money.Amount `json:"expected"`
+	}
+
+	// LedgerEntryResponse is a transaction whose balance_after does not
+	// follow from its balance_before and amount.
+	LedgerEntryResponse struct {
+		Type          string       `json:"type"`
+		ID            string       `json:"id"`
+		UserID        string       `json:"user_id"`
+		Currency      string       `json:"currency"`
+		Amount        money.Amount `json:"amount"`
+		BalanceBefore money.Amount `json:"balance_before"`
+		BalanceAfter  money.Amount `json:"balance_after"`
+	}
+
+	LedgerReportResponse struct {
+		WalletsChecked   int                    `json:"wallets_checked"`
+		EntriesChecked   int64                  `json:"entries_checked"`
+		WalletMismatches []LedgerWalletResponse `json:"wallet_mismatches"`
+		EntryMismatches  []LedgerEntryResponse  `json:"entry_mismatches"`
+	}
+)
+
+func (r LedgerReportResponse) Consistent() bool {
+	return len(r.WalletMismatches) == 0 && len(r.EntryMismatches) == 0
+}
@@ -0,0 +1,74 @@
+package dto
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	// Failed
+	MESSAGE_FAILED_VERIFY_PHONE = "failed verify phone number"
+	MESSAGE_FAILED_RESEND_OTP   = "failed resend otp"
+	MESSAGE_FAILED_FORGOT_PIN   = "failed request pin reset"
+	MESSAGE_FAILED_VERIFY_RESET = "failed verify pin reset"
+	MESSAGE_FAILED_RESET_PIN    = "failed reset pin"
+
+	// Success
+	MESSAGE_SUCCESS_VERIFY_PHONE = "success verify phone number"
+	MESSAGE_SUCCESS_RESEND_OTP   = "success resend otp"
+	MESSAGE_SUCCESS_FORGOT_PIN   = "success request pin reset"
+	MESSAGE_SUCCESS_VERIFY_RESET = "success verify pin reset"
+	MESSAGE_SUCCESS_RESET_PIN    = "success reset pin"
+)
+
+var (
+	ErrCreateOTP            = errors.New("failed to create otp")
+	ErrSendOTP              = errors.New("failed to send otp")
+	ErrOTPNotFound          = errors.New("otp not found, request a new one")
+	ErrOTPExpired           = errors.New("otp is expired, request a new one")
+	ErrOTPInvalid           = errors.New("otp is invalid")
+	ErrOTPTooManyAttempts   = errors.New("too many wrong otp attempts, request a new one")
+	ErrOTPResendCooldown    = errors.New("otp was sent recently, please wait before requesting another")
+	ErrPhoneNotVerified     = errors.New("phone number is not verified")
+	ErrPhoneAlreadyVerified = errors.New("phone number is already verified")
+	ErrPinResetTokenInvalid = errors.New("pin reset token is invalid")
+	ErrPinResetTokenExpired = errors.New("pin reset token is expired, start over")
+	ErrSessionRevoked       = errors.New("session has been revoked, please log in again")
+)
+
+type (
+	OTPResponse struct {
+		Channel     string    `json:"channel"`
+		Target      string    `json:"target"`
+		ExpiresAt   time.Time `json:"expires_at"`
+		ResendAfter time.Time `json:"resend_after"`
+	}
+
+	VerifyPhoneRequest struct {
+		PhoneNumber string `json:"phone_number" form:"phone_number" binding:"required"`
+		Code        string `json:"code" form:"code" binding:"required"`
+	}
+
+	ResendOTPRequest struct {
+		PhoneNumber string `json:"phone_number" form:"phone_number" binding:"required"`
+	}
+
+	ForgotPinRequest struct {
+		PhoneNumber string `json:"phone_number" form:"phone_number" binding:"required"`
+	}
+
+	VerifyPinResetRequest struct {
+		PhoneNumber string `json:"phone_number" form:"phone_number" binding:"required"`
+		Code        string `json:"code" form:"code" binding:"required"`
+	}
+
+	VerifyPinResetResponse struct {
+		ResetToken string    `json:"reset_token"`
+		ExpiresAt  time.Time `json:"expires_at"`
+	}
+
+	ResetPinRequest struct {
+		ResetToken string `json:"reset_token" form:"reset_token" binding:"required"`
+		NewPin     string `json:"new_pin" form:"new_pin" binding:"required"`
+	}
+)
@@ -0,0 +1,14 @@
+package dto
+
+import (
+	"errors"
+)
+
+const (
+	// Failed
+	MESSAGE_FAILED_TOO_MANY_REQUESTS = "failed too many requests"
+)
+
+var (
+	ErrTooManyRequests = errors.New("too many requests, please retry later")
+)
@@ -0,0 +1,29 @@
+package dto
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/Amierza/e-wallet/money"
+)
+
+const (
+	MESSAGE_FAILED_SUBSCRIBE_REALTIME = "failed subscribe realtime"
+)
+
+var (
+	ErrSubscribeRealtime = errors.New("failed to subscribe realtime")
+)
+
+type (
+	RealtimeBalanceData struct {
+		Currency string       `json:"currency"`
+		Balance  money.Amount `json:"balance"`
+	}
+
+	RealtimeTransactionData struct {
+		EventID   string          `json:"event_id"`
+		EventType string          `json:"event_type"`
+		Payload   json.RawMessage `json:"payload"`
+	}
+)
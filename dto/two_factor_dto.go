@@ -0,0 +1,59 @@
+package dto
+
+import "errors"
+
+const (
+	// Failed
+	MESSAGE_FAILED_ENROLL_TWO_FACTOR   = "failed enroll two factor"
+	MESSAGE_FAILED_CONFIRM_TWO_FACTOR  = "failed confirm two factor"
+	MESSAGE_FAILED_DISABLE_TWO_FACTOR  = "failed disable two factor"
+	MESSAGE_FAILED_REGENERATE_RECOVERY = "failed regenerate recovery codes"
+	MESSAGE_FAILED_COMPLETE_TWO_FACTOR = "failed complete two factor login"
+
+	// Success
+	MESSAGE_SUCCESS_ENROLL_TWO_FACTOR   = "success enroll two factor"
+	MESSAGE_SUCCESS_CONFIRM_TWO_FACTOR  = "success confirm two factor"
+	MESSAGE_SUCCESS_DISABLE_TWO_FACTOR  = "success disable two factor"
+	MESSAGE_SUCCESS_REGENERATE_RECOVERY = "success regenerate recovery codes"
+)
+
+var (
+	ErrTwoFactorAlreadyEnabled = errors.New("two factor is already enabled")
+	ErrTwoFactorNotEnrolled    = errors.New("two factor is not enrolled")
+	ErrTwoFactorNotEnabled     = errors.New("two factor is not enabled")
+	ErrTwoFactorCodeInvalid    = errors.New("two factor code is invalid")
+	ErrTwoFactorCodeRequired   = errors.New("two factor code or recovery code is required")
+	ErrTwoFactorLocked         = errors.New("too many wrong two factor codes, try again later")
+	ErrTwoFactorChallenge      = errors.New("two factor challenge is invalid or expired")
+	ErrSaveTwoFactor           = errors.New("failed to save two factor")
+	ErrGetTwoFactor            = errors.New("failed to get two factor")
+	ErrCreateRecoveryCodes     = errors.New("failed to create recovery codes")
+)
+
+type (
+	TwoFactorEnrollResponse struct {
+		Secret     string `json:"secret" redact:"allow"`
+		OTPAuthURI string `json:"otpauth_uri"`
+	}
+
+	TwoFactorCodeRequest struct {
+		Code string `json:"code" form:"code" binding:"required"`
+	}
+
+	TwoFactorDisableRequest struct {
+		Pin          string `json:"pin" form:"pin" binding:"required"`
+		Code         string `json:"code" form:"code"`
+		RecoveryCode string `json:"recovery_code" form:"recovery_code"`
+	}
+
+	RecoveryCodesResponse struct {
+		RecoveryCodes []string `json:"recovery_codes"`
+	}
+
+	TwoFactorLoginRequest struct {
+		ChallengeToken string `json:"challenge_token" form:"challenge_token" binding:"required"`
+		Code           string `json:"code" form:"code"`
+		RecoveryCode   string `json:"recovery_code" form:"recovery_code"`
+		DeviceInfo
+	}
+)
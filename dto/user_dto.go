@@ -2,8 +2,10 @@ package dto
 
 import (
 	"errors"
+	"time"
 
 	"github.com/Amierza/e-wallet/entity"
+	"github.com/Amierza/e-wallet/money"
 	"github.com/google/uuid"
 )
 
@@ -22,6 +24,12 @@ const (
 	MESSAGE_FAILED_PAYMENT              = "failed payment"
 	MESSAGE_FAILED_TRANSFER             = "failed transfer"
 	MESSAGE_FAILED_UPDATE_PROFILE_USER  = "failed update profile user"
+	MESSAGE_FAILED_GET_LIST_WALLET      = "failed get list wallet"
+	MESSAGE_FAILED_OPEN_WALLET          = "failed open wallet"
+	MESSAGE_FAILED_VERIFY_EMAIL         = "failed verify email"
+	MESSAGE_FAILED_SEND_VERIFY_EMAIL    = "failed send verification email"
+	MESSAGE_FAILED_SEND_STATEMENT       = "failed send statement"
+	MESSAGE_FAILED_CHANGE_PIN           = "failed change pin"
 
 	// Success
 	MESSAGE_SUCCESS_REGISTER_USER        = "success create user"
@@ -32,6 +40,12 @@ const (
 	MESSAGE_SUCCESS_PAYMENT              = "success payment"
 	MESSAGE_SUCCESS_TRANSFER             = "success transfer"
 	MESSAGE_SUCCESS_UPDATE_PROFILE_USER  = "success update profile user"
+	MESSAGE_SUCCESS_GET_LIST_WALLET      = "success get list wallet"
+	MESSAGE_SUCCESS_OPEN_WALLET          = "success open wallet"
+	MESSAGE_SUCCESS_VERIFY_EMAIL         = "success verify email"
+	MESSAGE_SUCCESS_SEND_VERIFY_EMAIL    = "success send verification email"
+	MESSAGE_SUCCESS_SEND_STATEMENT       = "success send statement"
+	MESSAGE_SUCCESS_CHANGE_PIN           = "success change pin"
 )
 
 var (
@@ -49,6 +63,33 @@ var (
 	ErrGetTargetUser              = errors.New("failed to get target user")
 	ErrCannotTransferToOwnAccount = errors.New("failed transfer to own account")
 	ErrCreateTransfer             = errors.New("failed to create transfer")
+	ErrUnsupportedCurrency        = errors.New("currency is not supported")
+	ErrWalletNotFound             = errors.New("wallet not found")
+	ErrWalletAlreadyExists        = errors.New("wallet is already exists")
+	ErrCreateWallet               = errors.New("failed to create wallet")
+	ErrGetWallets                 = errors.New("failed to get wallets")
+	ErrUpdateWalletBalance        = errors.New("failed to update wallet balance")
+	ErrTargetWalletNotFound       = errors.New("target user has no wallet in this currency")
+	ErrInvalidAmount              = errors.New("amount must be greater than zero")
+	ErrRecordEvent                = errors.New("failed to record event")
+	ErrEmailAlreadyExists         = errors.New("email is already exists")
+	ErrEmailNotFound              = errors.New("email not found")
+	ErrEmailNotSet                = errors.New("user has no email")
+	ErrEmailNotVerified           = errors.New("email is not verified")
+	ErrEmailAlreadyVerified       = errors.New("email is already verified")
+	ErrSendEmail                  = errors.New("failed to send email")
+	ErrVerificationLinkInvalid    = errors.New("verification link is invalid")
+	ErrVerificationLinkExpired    = errors.New("verification link is expired")
+	ErrLoginIdentifierRequired    = errors.New("phone number or email is required")
+	ErrInvalidStatementPeriod     = errors.New("statement period is invalid")
+	ErrGetTransactions            = errors.New("failed to get transactions")
+	ErrPinLength                  = errors.New("pin must be exactly 6 digits")
+	ErrPinNotNumeric              = errors.New("pin must only contain digits")
+	ErrPinTooSimple               = errors.New("pin must not be a repeated digit or a sequence")
+	ErrPinReused                  = errors.New("pin must differ from your recent pins")
+	ErrOldPinNotMatch             = errors.New("current pin not match")
+	ErrHashPin                    = errors.New("failed to hash pin")
+	ErrAccountFrozen              = errors.New("account is frozen, please contact support")
 )
 
 type (
@@ -56,58 +97,64 @@ type (
 		FirstName   string `json:"first_name" form:"first_name"`
 		LastName    string `json:"last_name" form:"last_name"`
 		PhoneNumber string `json:"phone_number" form:"phone_number"`
+		Email       string `json:"email,omitempty" form:"email" binding:"omitempty,email"`
 		Address     string `json:"address" form:"address"`
 		Pin         string `json:"pin" form:"pin"`
 	}
 
+	// UserResponse is built by the view package, which decides per audience
+	// which of the optional fields are filled in.
 	UserResponse struct {
-		ID          string `json:"user_id"`
-		FirstName   string `json:"first_name"`
-		LastName    string `json:"last_name"`
-		PhoneNumber string `json:"phone_number"`
-		Address     string `json:"address"`
-		Pin         string `json:"pin"`
+		ID              string           `json:"user_id"`
+		FirstName       string           `json:"first_name"`
+		LastName        string           `json:"last_name"`
+		PhoneNumber     string           `json:"phone_number"`
+		Address         string           `json:"address,omitempty"`
+		Email           *string          `json:"email,omitempty"`
+		Role            string           `json:"role,omitempty"`
+		FrozenAt        *time.Time       `json:"frozen_at,omitempty"`
+		PhoneVerifiedAt *time.Time       `json:"phone_verified_at,omitempty"`
+		EmailVerifiedAt *time.Time       `json:"email_verified_at,omitempty"`
+		Wallets         []WalletResponse `json:"wallets,omitempty"`
+		Verification    *OTPResponse     `json:"verification,omitempty"`
 
-		entity.Timestamp
-	}
-
-	AllUserResponse struct {
-		ID          string `json:"user_id"`
-		FirstName   string `json:"first_name"`
-		LastName    string `json:"last_name"`
-		PhoneNumber string `json:"phone_number"`
-		Address     string `json:"address"`
-		Pin         string `json:"pin"`
-		Balance     int64  `json:"balance"`
-
-		entity.Timestamp
+		*entity.Timestamp
 	}
 
 	UserLoginRequest struct {
-		PhoneNumber string `json:"phone_number" form:"phone_number" binding:"required"`
+		PhoneNumber string `json:"phone_number" form:"phone_number"`
+		Email       string `json:"email" form:"email"`
 		Pin         string `json:"pin" form:"pin" binding:"required"`
+		DeviceInfo
 	}
 
+	// UserLoginResponse carries either the token pair or, for accounts with
+	// two-factor enabled, a challenge token to finish via /login/2fa.
 	UserLoginResponse struct {
-		AccessToken  string `json:"access_token"`
-		RefreshToken string `json:"refresh_token"`
+		AccessToken        string     `json:"access_token,omitempty"`
+		RefreshToken       string     `json:"refresh_token,omitempty"`
+		DeviceID           string     `json:"device_id,omitempty"`
+		TwoFactorRequired  bool       `json:"two_factor_required,omitempty"`
+		ChallengeToken     string     `json:"challenge_token,omitempty"`
+		ChallengeExpiresAt *time.Time `json:"challenge_expires_at,omitempty"`
 	}
 
 	UserPaginationResponse struct {
-		Data []AllUserResponse `json:"data"`
+		Data []UserResponse `json:"data"`
 		PaginationResponse
 	}
 
 	AllTransactionResponse struct {
-		TopUpID       string `json:"top_up_id,omitempty"`
-		PaymentID     string `json:"payment_id,omitempty"`
-		TransferID    string `json:"transfer_id,omitempty"`
-		UserID        string `json:"user_id,omitempty"`
-		TargetUserID  string `json:"target_user_id,omitempty"`
-		Amount        int64  `json:"amount_top_up,omitempty"`
-		Remarks       string `json:"remarks_payment,omitempty"`
-		BalanceBefore *int64 `json:"balance_before_top_up,omitempty"`
-		BalanceAfter  int64  `json:"balance_after_top_up,omitempty"`
+		TopUpID       string        `json:"top_up_id,omitempty"`
+		PaymentID     string        `json:"payment_id,omitempty"`
+		TransferID    string        `json:"transfer_id,omitempty"`
+		UserID        string        `json:"user_id,omitempty"`
+		TargetUserID  string        `json:"target_user_id,omitempty"`
+		Currency      string        `json:"currency,omitempty"`
+		Amount        money.Amount  `json:"amount_top_up"`
+		Remarks       string        `json:"remarks_payment,omitempty"`
+		BalanceBefore *money.Amount `json:"balance_before_top_up,omitempty"`
+		BalanceAfter  money.Amount  `json:"balance_after_top_up"`
 		entity.Timestamp
 	}
 
@@ -128,53 +175,97 @@ type (
 		PaginationResponse
 	}
 
+	WalletResponse struct {
+		ID        string       `json:"wallet_id"`
+		Currency  string       `json:"currency"`
+		MinorUnit int          `json:"minor_unit"`
+		Balance   money.Amount `json:"balance"`
+	}
+
+	OpenWalletRequest struct {
+		Currency string `json:"currency" binding:"required"`
+	}
+
 	TopUpRequest struct {
-		Amount int64 `json:"amount" binding:"required"`
+		Amount money.Amount `json:"amount"`
 	}
 
 	TopUpResponse struct {
-		ID            string `json:"top_up_id"`
-		AmountTopUp   int64  `json:"amount_top_up"`
-		BalanceBefore int64  `json:"balance_before"`
-		BalanceAfter  int64  `json:"balance_after"`
+		ID            string       `json:"top_up_id"`
+		Currency      string       `json:"currency"`
+		AmountTopUp   money.Amount `json:"amount_top_up"`
+		BalanceBefore money.Amount `json:"balance_before"`
+		BalanceAfter  money.Amount `json:"balance_after"`
 		entity.Timestamp
 	}
 
 	PaymentRequest struct {
-		Amount  int64  `json:"amount" binding:"required"`
-		Remarks string `json:"remarks"`
+		Amount  money.Amount `json:"amount"`
+		Remarks string       `json:"remarks"`
 	}
 
 	PaymentResponse struct {
-		ID            string `json:"payment_id"`
-		AmountPayment int64  `json:"amount_payment"`
-		Remarks       string `json:"remarks"`
-		BalanceBefore int64  `json:"balance_before"`
-		BalanceAfter  int64  `json:"balance_after"`
+		ID            string       `json:"payment_id"`
+		Currency      string       `json:"currency"`
+		AmountPayment money.Amount `json:"amount_payment"`
+		Remarks       string       `json:"remarks"`
+		BalanceBefore money.Amount `json:"balance_before"`
+		BalanceAfter  money.Amount `json:"balance_after"`
 		entity.Timestamp
 	}
 
 	TransferRequest struct {
-		TargetUser uuid.UUID `json:"target_user" binding:"required"`
-		Amount     int64     `json:"amount" binding:"required"`
-		Remarks    string    `json:"remarks"`
+		TargetUser uuid.UUID    `json:"target_user" binding:"required"`
+		Amount     money.Amount `json:"amount"`
+		QuoteID    *uuid.UUID   `json:"quote_id"`
+		Remarks    string       `json:"remarks"`
 	}
 
 	TransferResponse struct {
-		ID             string `json:"transfer_id"`
-		TargetUserID   string `json:"target_user_id"`
-		AmountTransfer int64  `json:"amount_transfer"`
-		Remarks        string `json:"remarks"`
-		BalanceBefore  int64  `json:"balance_before"`
-		BalanceAfter   int64  `json:"balance_after"`
+		ID             string       `json:"transfer_id"`
+		TargetUserID   string       `json:"target_user_id"`
+		Currency       string       `json:"currency"`
+		AmountTransfer money.Amount `json:"amount_transfer"`
+		TargetCurrency string       `json:"target_currency"`
+		TargetAmount   money.Amount `json:"target_amount"`
+		QuoteID        string       `json:"quote_id,omitempty"`
+		Remarks        string       `json:"remarks"`
+		BalanceBefore  money.Amount `json:"balance_before"`
+		BalanceAfter   money.Amount `json:"balance_after"`
 		entity.Timestamp
 	}
 
+	VerifyEmailRequest struct {
+		Token string `form:"token" binding:"required"`
+	}
+
+	EmailVerificationResponse struct {
+		Email     string    `json:"email"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+
+	StatementRequest struct {
+		From string `json:"from" form:"from"`
+		To   string `json:"to" form:"to"`
+	}
+
+	StatementResponse struct {
+		Email        string    `json:"email"`
+		From         time.Time `json:"from"`
+		To           time.Time `json:"to"`
+		Transactions int       `json:"transactions"`
+	}
+
 	UpdateProfileRequest struct {
 		FirstName   string `json:"first_name,omitempty"`
 		LastName    string `json:"last_name,omitempty"`
 		PhoneNumber string `json:"phone_number,omitempty"`
+		Email       string `json:"email,omitempty" binding:"omitempty,email"`
 		Address     string `json:"address,omitempty"`
-		Pin         string `json:"pin,omitempty"`
+	}
+
+	ChangePinRequest struct {
+		OldPin string `json:"old_pin" form:"old_pin" binding:"required"`
+		NewPin string `json:"new_pin" form:"new_pin" binding:"required"`
 	}
 )
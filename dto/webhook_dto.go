@@ -0,0 +1,87 @@
+package dto
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Amierza/e-wallet/entity"
+)
+
+const (
+	// Failed
+	MESSAGE_FAILED_CREATE_WEBHOOK_ENDPOINT   = "failed create webhook endpoint"
+	MESSAGE_FAILED_GET_LIST_WEBHOOK_ENDPOINT = "failed get list webhook endpoint"
+	MESSAGE_FAILED_DELETE_WEBHOOK_ENDPOINT   = "failed delete webhook endpoint"
+	MESSAGE_FAILED_GET_LIST_WEBHOOK_DELIVERY = "failed get list webhook delivery"
+	MESSAGE_FAILED_REDELIVER_WEBHOOK         = "failed redeliver webhook"
+
+	// Success
+	MESSAGE_SUCCESS_CREATE_WEBHOOK_ENDPOINT   = "success create webhook endpoint"
+	MESSAGE_SUCCESS_GET_LIST_WEBHOOK_ENDPOINT = "success get list webhook endpoint"
+	MESSAGE_SUCCESS_DELETE_WEBHOOK_ENDPOINT   = "success delete webhook endpoint"
+	MESSAGE_SUCCESS_GET_LIST_WEBHOOK_DELIVERY = "success get list webhook delivery"
+	MESSAGE_SUCCESS_REDELIVER_WEBHOOK         = "success redeliver webhook"
+)
+
+var (
+	ErrInvalidWebhookURL       = errors.New("webhook url must be an absolute http or https url")
+	ErrInsecureWebhookURL      = errors.New("webhook url must use https")
+	ErrForbiddenWebhookURL     = errors.New("webhook url must resolve to a public address")
+	ErrUnknownWebhookEvent     = errors.New("unknown webhook event type")
+	ErrGlobalWebhookNotAllowed = errors.New("only admin can register a global webhook endpoint")
+	ErrCreateWebhookEndpoint   = errors.New("failed to create webhook endpoint")
+	ErrGetWebhookEndpoints     = errors.New("failed to get webhook endpoints")
+	ErrWebhookEndpointNotFound = errors.New("webhook endpoint not found")
+	ErrDeleteWebhookEndpoint   = errors.New("failed to delete webhook endpoint")
+	ErrGetWebhookDeliveries    = errors.New("failed to get webhook deliveries")
+	ErrWebhookDeliveryNotFound = errors.New("webhook delivery not found")
+	ErrRedeliverWebhook        = errors.New("failed to redeliver webhook")
+	ErrPublishWebhook          = errors.New("failed to publish webhook event")
+)
+
+type (
+	WebhookEndpointRequest struct {
+		URL    string   `json:"url" binding:"required"`
+		Events []string `json:"events" binding:"required,min=1"`
+		Global bool     `json:"global"`
+	}
+
+	WebhookEndpointResponse struct {
+		ID        string    `json:"endpoint_id"`
+		URL       string    `json:"url"`
+		Events    []string  `json:"events"`
+		Global    bool      `json:"global"`
+		Active    bool      `json:"active"`
+		Secret    string    `json:"secret,omitempty" redact:"allow"`
+		CreatedAt time.Time `json:"created_at"`
+	}
+
+	WebhookDeliveryPaginationRequest struct {
+		EndpointID string `form:"endpoint_id"`
+		PaginationRequest
+	}
+
+	WebhookDeliveryResponse struct {
+		ID             string     `json:"delivery_id"`
+		EndpointID     string     `json:"endpoint_id"`
+		EventID        string     `json:"event_id"`
+		EventType      string     `json:"event_type"`
+		Status         string     `json:"status"`
+		Attempts       int        `json:"attempts"`
+		NextAttemptAt  time.Time  `json:"next_attempt_at"`
+		LastStatusCode int        `json:"last_status_code"`
+		LastError      string     `json:"last_error,omitempty"`
+		DeliveredAt    *time.Time `json:"delivered_at"`
+		CreatedAt      time.Time  `json:"created_at"`
+	}
+
+	WebhookDeliveryPaginationResponse struct {
+		Data []WebhookDeliveryResponse `json:"data"`
+		PaginationResponse
+	}
+
+	GetAllWebhookDeliveryRepositoryResponse struct {
+		Deliveries []entity.WebhookDelivery
+		PaginationResponse
+	}
+)
@@ -0,0 +1,25 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Device is one signed-in session. Every token pair carries the device ID, so
+// revoking the device signs that session out.
+type Device struct {
+	ID         uuid.UUID  `gorm:"type:uuid;primaryKey" json:"device_id"`
+	UserID     uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	User       User       `gorm:"foreignKey:UserID" json:"-"`
+	Name       string     `json:"name"`
+	Platform   string     `json:"platform"`
+	PushToken  string     `json:"-"`
+	IPAddress  string     `json:"ip_address"`
+	UserAgent  string     `json:"user_agent"`
+	TrustedAt  *time.Time `json:"trusted_at"`
+	LastSeenAt time.Time  `json:"last_seen_at"`
+	RevokedAt  *time.Time `gorm:"index" json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
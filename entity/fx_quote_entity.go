@@ -0,0 +1,33 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/Amierza/e-wallet/money"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type FXQuote struct {
+	ID           uuid.UUID    `gorm:"type:uuid;primaryKey" json:"quote_id"`
+	UserID       uuid.UUID    `gorm:"type:uuid;not null" json:"user_id"`
+	User         User         `gorm:"foreignKey:UserID"`
+	FromCurrency string       `gorm:"type:char(3);not null" json:"from_currency"`
+	ToCurrency   string       `gorm:"type:char(3);not null" json:"to_currency"`
+	MidRate      string       `gorm:"type:numeric(24,12);not null" json:"mid_rate"`
+	Rate         string       `gorm:"type:numeric(24,12);not null" json:"rate"`
+	SpreadBps    int64        `gorm:"not null" json:"spread_bps"`
+	SourceAmount money.Amount `gorm:"not null" json:"source_amount"`
+	TargetAmount money.Amount `gorm:"not null" json:"target_amount"`
+	SpreadAmount money.Amount `gorm:"not null" json:"spread_amount"`
+	ExpiresAt    time.Time    `gorm:"not null" json:"expires_at"`
+	UsedAt       *time.Time   `json:"used_at"`
+	Timestamp
+}
+
+func (q *FXQuote) AfterFind(tx *gorm.DB) error {
+	q.SourceAmount = q.SourceAmount.WithCurrency(q.FromCurrency)
+	q.TargetAmount = q.TargetAmount.WithCurrency(q.ToCurrency)
+	q.SpreadAmount = q.SpreadAmount.WithCurrency(q.ToCurrency)
+	return nil
+}
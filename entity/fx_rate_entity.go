@@ -0,0 +1,13 @@
+package entity
+
+import "github.com/google/uuid"
+
+// FXRate is the mid-market price of one unit of BaseCurrency in QuoteCurrency.
+type FXRate struct {
+	ID            uuid.UUID `gorm:"type:uuid;primaryKey" json:"fx_rate_id"`
+	BaseCurrency  string    `gorm:"type:char(3);not null;uniqueIndex:idx_fx_rate_pair" json:"base_currency"`
+	QuoteCurrency string    `gorm:"type:char(3);not null;uniqueIndex:idx_fx_rate_pair" json:"quote_currency"`
+	Rate          string    `gorm:"type:numeric(24,12);not null" json:"rate"`
+	SpreadBps     int64     `gorm:"not null;default:0" json:"spread_bps"`
+	Timestamp
+}
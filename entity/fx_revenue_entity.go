@@ -0,0 +1,24 @@
+package entity
+
+import (
+	"github.com/Amierza/e-wallet/money"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// FXRevenue records the spread kept by the platform on a converted transfer.
+type FXRevenue struct {
+	ID         uuid.UUID    `gorm:"type:uuid;primaryKey" json:"fx_revenue_id"`
+	QuoteID    uuid.UUID    `gorm:"type:uuid;not null;uniqueIndex" json:"quote_id"`
+	Quote      FXQuote      `gorm:"foreignKey:QuoteID"`
+	TransferID uuid.UUID    `gorm:"type:uuid;not null" json:"transfer_id"`
+	Transfer   Transfer     `gorm:"foreignKey:TransferID"`
+	Currency   string       `gorm:"type:char(3);not null" json:"currency"`
+	Amount     money.Amount `gorm:"not null" json:"amount"`
+	Timestamp
+}
+
+func (r *FXRevenue) AfterFind(tx *gorm.DB) error {
+	r.Amount = r.Amount.WithCurrency(r.Currency)
+	return nil
+}
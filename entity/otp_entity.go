@@ -0,0 +1,20 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type OTP struct {
+	ID          uuid.UUID  `gorm:"type:uuid;primaryKey" json:"otp_id"`
+	Purpose     string     `gorm:"not null;index:idx_otp_purpose_target" json:"purpose"`
+	Channel     string     `gorm:"not null" json:"channel"`
+	Target      string     `gorm:"not null;index:idx_otp_purpose_target" json:"target"`
+	CodeHash    string     `gorm:"not null" json:"-"`
+	Attempts    int        `gorm:"not null;default:0" json:"attempts"`
+	MaxAttempts int        `gorm:"not null" json:"max_attempts"`
+	ExpiresAt   time.Time  `gorm:"not null" json:"expires_at"`
+	ConsumedAt  *time.Time `json:"consumed_at"`
+	Timestamp
+}
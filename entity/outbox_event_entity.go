@@ -0,0 +1,26 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OutboxEvent is written in the same transaction as the change it describes
+// and later published by the relay. Sequence gives a total order, and the
+// relay never publishes an event while an older one for the same aggregate
+// is still pending.
+type OutboxEvent struct {
+	ID             uuid.UUID  `gorm:"type:uuid;primaryKey" json:"event_id"`
+	Sequence       int64      `gorm:"autoIncrement;uniqueIndex;not null" json:"sequence"`
+	AggregateID    uuid.UUID  `gorm:"type:uuid;not null;index:idx_outbox_aggregate_status,priority:1" json:"aggregate_id"`
+	EventType      string     `gorm:"not null" json:"event_type"`
+	Payload        string     `gorm:"type:jsonb;not null" json:"payload"`
+	Status         string     `gorm:"not null;index:idx_outbox_aggregate_status,priority:2" json:"status"`
+	Attempts       int        `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt  time.Time  `gorm:"not null" json:"next_attempt_at"`
+	DeliveredSinks []string   `gorm:"type:text;serializer:json" json:"delivered_sinks"`
+	LastError      string     `gorm:"type:text" json:"last_error"`
+	PublishedAt    *time.Time `json:"published_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
@@ -1,14 +1,26 @@
 package entity
 
-import "github.com/google/uuid"
+import (
+	"github.com/Amierza/e-wallet/money"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
 
 type Payment struct {
-	ID            uuid.UUID `gorm:"type:uuid;primaryKey" json:"payment_id"`
-	UserID        uuid.UUID `gorm:"type:uuid;not null" json:"user_id"`
-	User          User      `gorm:"foreignKey:UserID"`
-	Amount        int64     `json:"amount"`
-	Remarks       string    `gorm:"type:text;null" json:"remarks"`
-	BalanceBefore int64     `json:"balance_before"`
-	BalanceAfter  int64     `json:"balance_after"`
+	ID            uuid.UUID    `gorm:"type:uuid;primaryKey" json:"payment_id"`
+	UserID        uuid.UUID    `gorm:"type:uuid;not null" json:"user_id"`
+	User          User         `gorm:"foreignKey:UserID"`
+	Currency      string       `gorm:"type:char(3);not null;default:IDR" json:"currency"`
+	Amount        money.Amount `json:"amount"`
+	Remarks       string       `gorm:"type:text;null" json:"remarks"`
+	BalanceBefore money.Amount `json:"balance_before"`
+	BalanceAfter  money.Amount `json:"balance_after"`
 	Timestamp
 }
+
+func (p *Payment) AfterFind(tx *gorm.DB) error {
+	p.Amount = p.Amount.WithCurrency(p.Currency)
+	p.BalanceBefore = p.BalanceBefore.WithCurrency(p.Currency)
+	p.BalanceAfter = p.BalanceAfter.WithCurrency(p.Currency)
+	return nil
+}
@@ -0,0 +1,15 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type PinHistory struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey" json:"pin_history_id"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null;index" json:"user_id"`
+	User      User      `gorm:"foreignKey:UserID" json:"-"`
+	PinHash   string    `gorm:"not null" json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -1,13 +1,25 @@
 package entity
 
-import "github.com/google/uuid"
+import (
+	"github.com/Amierza/e-wallet/money"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
 
 type TopUp struct {
-	ID            uuid.UUID `gorm:"type:uuid;primaryKey" json:"top_up_id"`
-	UserID        uuid.UUID `gorm:"type:uuid;not null" json:"user_id"`
-	User          User      `gorm:"foreignKey:UserID"`
-	Amount        int64     `json:"amount"`
-	BalanceBefore int64     `json:"balance_before"`
-	BalanceAfter  int64     `json:"balance_after"`
+	ID            uuid.UUID    `gorm:"type:uuid;primaryKey" json:"top_up_id"`
+	UserID        uuid.UUID    `gorm:"type:uuid;not null" json:"user_id"`
+	User          User         `gorm:"foreignKey:UserID"`
+	Currency      string       `gorm:"type:char(3);not null;default:IDR" json:"currency"`
+	Amount        money.Amount `json:"amount"`
+	BalanceBefore money.Amount `json:"balance_before"`
+	BalanceAfter  money.Amount `json:"balance_after"`
 	Timestamp
 }
+
+func (t *TopUp) AfterFind(tx *gorm.DB) error {
+	t.Amount = t.Amount.WithCurrency(t.Currency)
+	t.BalanceBefore = t.BalanceBefore.WithCurrency(t.Currency)
+	t.BalanceAfter = t.BalanceAfter.WithCurrency(t.Currency)
+	return nil
+}
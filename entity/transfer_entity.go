@@ -1,16 +1,32 @@
 package entity
 
-import "github.com/google/uuid"
+import (
+	"github.com/Amierza/e-wallet/money"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
 
 type Transfer struct {
-	ID            uuid.UUID `gorm:"type:uuid;primaryKey" json:"transfer_id"`
-	UserID        uuid.UUID `gorm:"type:uuid;not null" json:"user_id"`
-	TargetUserID  uuid.UUID `gorm:"type:uuid;not null" json:"target_user_id"`
-	User          User      `gorm:"foreignKey:UserID"`
-	TargetUser    User      `gorm:"foreignKey:TargetUserID;references:ID"`
-	Amount        int64     `json:"amount"`
-	Remarks       string    `gorm:"type:text;null" json:"remarks"`
-	BalanceBefore int64     `json:"balance_before"`
-	BalanceAfter  int64     `json:"balance_after"`
+	ID             uuid.UUID    `gorm:"type:uuid;primaryKey" json:"transfer_id"`
+	UserID         uuid.UUID    `gorm:"type:uuid;not null" json:"user_id"`
+	TargetUserID   uuid.UUID    `gorm:"type:uuid;not null" json:"target_user_id"`
+	User           User         `gorm:"foreignKey:UserID"`
+	TargetUser     User         `gorm:"foreignKey:TargetUserID;references:ID"`
+	Currency       string       `gorm:"type:char(3);not null;default:IDR" json:"currency"`
+	Amount         money.Amount `json:"amount"`
+	TargetCurrency string       `gorm:"type:char(3);not null;default:IDR" json:"target_currency"`
+	TargetAmount   money.Amount `json:"target_amount"`
+	QuoteID        *uuid.UUID   `gorm:"type:uuid" json:"quote_id,omitempty"`
+	Remarks        string       `gorm:"type:text;null" json:"remarks"`
+	BalanceBefore  money.Amount `json:"balance_before"`
+	BalanceAfter   money.Amount `json:"balance_after"`
 	Timestamp
 }
+
+func (t *Transfer) AfterFind(tx *gorm.DB) error {
+	t.Amount = t.Amount.WithCurrency(t.Currency)
+	t.BalanceBefore = t.BalanceBefore.WithCurrency(t.Currency)
+	t.BalanceAfter = t.BalanceAfter.WithCurrency(t.Currency)
+	t.TargetAmount = t.TargetAmount.WithCurrency(t.TargetCurrency)
+	return nil
+}
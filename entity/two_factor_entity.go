@@ -0,0 +1,30 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TwoFactor holds a user's TOTP enrollment. It exists unconfirmed between
+// enrollment and the first valid code.
+type TwoFactor struct {
+	UserID         uuid.UUID  `gorm:"type:uuid;primaryKey" json:"user_id"`
+	User           User       `gorm:"foreignKey:UserID" json:"-"`
+	Secret         string     `gorm:"type:text;not null" json:"-"`
+	ConfirmedAt    *time.Time `json:"confirmed_at"`
+	LastUsedStep   int64      `gorm:"not null;default:0" json:"-"`
+	FailedAttempts int        `gorm:"not null;default:0" json:"-"`
+	LockedUntil    *time.Time `json:"locked_until"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+type RecoveryCode struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primaryKey" json:"recovery_code_id"`
+	UserID    uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	User      User       `gorm:"foreignKey:UserID" json:"-"`
+	CodeHash  string     `gorm:"not null;uniqueIndex" json:"-"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
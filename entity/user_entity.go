@@ -1,22 +1,31 @@
 package entity
 
 import (
-	"github.com/Amierza/e-wallet/helpers"
+	"time"
+
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
 type User struct {
-	ID          uuid.UUID  `gorm:"type:uuid;primaryKey" json:"user_id"`
-	FirstName   string     `json:"first_name"`
-	LastName    string     `json:"last_name"`
-	PhoneNumber string     `json:"phone_number"`
-	Address     string     `json:"address"`
-	Pin         string     `json:"pin"`
-	Balance     int64      `json:"balance"`
-	TopUps      []TopUp    `gorm:"foreignKey:UserID"`
-	Payments    []Payment  `gorm:"foreignKey:UserID"`
-	Transfers   []Transfer `gorm:"foreignKey:UserID"`
+	ID              uuid.UUID  `gorm:"type:uuid;primaryKey" json:"user_id"`
+	FirstName       string     `json:"first_name"`
+	LastName        string     `json:"last_name"`
+	PhoneNumber     string     `json:"phone_number"`
+	Address         string     `json:"address"`
+	Pin             string     `json:"-"`
+	PhoneVerifiedAt *time.Time `json:"phone_verified_at"`
+	Email           *string    `json:"email"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	// SessionsRevokedAt invalidates every token issued before it.
+	SessionsRevokedAt *time.Time `json:"-"`
+	// FrozenAt blocks logins and every session while set.
+	FrozenAt  *time.Time `json:"frozen_at"`
+	Role      string     `gorm:"not null;default:user" json:"role"`
+	Wallets   []Wallet   `gorm:"foreignKey:UserID" json:"wallets,omitempty"`
+	TopUps    []TopUp    `gorm:"foreignKey:UserID"`
+	Payments  []Payment  `gorm:"foreignKey:UserID"`
+	Transfers []Transfer `gorm:"foreignKey:UserID"`
 	Timestamp
 }
 
@@ -27,12 +36,11 @@ func (u *User) BeforeCreate(tx *gorm.DB) error {
 		}
 	}()
 
-	u.ID = uuid.New()
-
-	var err error
-	u.Pin, err = helpers.HashPin(u.Pin)
-	if err != nil {
-		return err
+	// The PIN is hashed by the service before the user reaches the database;
+	// hashing here as well would double-hash it.
+	if u.ID == uuid.Nil {
+		u.ID = uuid.New()
 	}
+
 	return nil
 }
@@ -0,0 +1,28 @@
+package entity
+
+import (
+	"github.com/Amierza/e-wallet/money"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type Wallet struct {
+	ID        uuid.UUID    `gorm:"type:uuid;primaryKey" json:"wallet_id"`
+	UserID    uuid.UUID    `gorm:"type:uuid;not null;uniqueIndex:idx_wallet_user_currency" json:"user_id"`
+	Currency  string       `gorm:"type:char(3);not null;uniqueIndex:idx_wallet_user_currency" json:"currency"`
+	MinorUnit int          `gorm:"not null" json:"minor_unit"`
+	Balance   money.Amount `gorm:"not null;default:0" json:"balance"`
+	Timestamp
+}
+
+func (w *Wallet) BeforeCreate(tx *gorm.DB) error {
+	if w.ID == uuid.Nil {
+		w.ID = uuid.New()
+	}
+	return nil
+}
+
+func (w *Wallet) AfterFind(tx *gorm.DB) error {
+	w.Balance = w.Balance.WithCurrency(w.Currency)
+	return nil
+}
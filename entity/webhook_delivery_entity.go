@@ -0,0 +1,23 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type WebhookDelivery struct {
+	ID             uuid.UUID       `gorm:"type:uuid;primaryKey" json:"delivery_id"`
+	EndpointID     uuid.UUID       `gorm:"type:uuid;not null;index" json:"endpoint_id"`
+	Endpoint       WebhookEndpoint `gorm:"foreignKey:EndpointID"`
+	EventID        uuid.UUID       `gorm:"type:uuid;not null;index" json:"event_id"`
+	EventType      string          `gorm:"not null" json:"event_type"`
+	Payload        string          `gorm:"type:jsonb;not null" json:"payload"`
+	Status         string          `gorm:"not null;index:idx_webhook_delivery_due,priority:1" json:"status"`
+	Attempts       int             `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt  time.Time       `gorm:"not null;index:idx_webhook_delivery_due,priority:2" json:"next_attempt_at"`
+	LastStatusCode int             `json:"last_status_code"`
+	LastError      string          `gorm:"type:text" json:"last_error"`
+	DeliveredAt    *time.Time      `json:"delivered_at"`
+	Timestamp
+}
@@ -0,0 +1,16 @@
+package entity
+
+import "github.com/google/uuid"
+
+// WebhookEndpoint with a nil UserID is an internal endpoint that receives
+// events for every user; only admins can register one.
+type WebhookEndpoint struct {
+	ID     uuid.UUID  `gorm:"type:uuid;primaryKey" json:"endpoint_id"`
+	UserID *uuid.UUID `gorm:"type:uuid;index" json:"user_id"`
+	User   *User      `gorm:"foreignKey:UserID"`
+	URL    string     `gorm:"type:text;not null" json:"url"`
+	Secret string     `gorm:"type:text;not null" json:"-"`
+	Events []string   `gorm:"type:text;serializer:json;not null" json:"events"`
+	Active bool       `gorm:"not null;default:true" json:"active"`
+	Timestamp
+}
@@ -0,0 +1,82 @@
+package event
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// NATSPublisher is the subset of *nats.Conn used by NATSSink.
+type NATSPublisher interface {
+	Publish(subject string, data []byte) error
+}
+
+type KafkaMessage struct {
+	Topic   string
+	Key     []byte
+	Value   []byte
+	Headers map[string]string
+}
+
+// KafkaWriter is implemented by a thin adapter around the Kafka client in use;
+// keying messages by aggregate keeps a user's events on one partition.
+type KafkaWriter interface {
+	WriteMessages(ctx context.Context, msgs ...KafkaMessage) error
+}
+
+type NATSSink struct {
+	conn   NATSPublisher
+	prefix string
+}
+
+// NewNATSSink publishes each event to "<prefix>.<event type>".
+func NewNATSSink(conn NATSPublisher, prefix string) *NATSSink {
+	return &NATSSink{
+		conn:   conn,
+		prefix: prefix,
+	}
+}
+
+func (s *NATSSink) Name() string {
+	return "nats"
+}
+
+func (s *NATSSink) Publish(ctx context.Context, e Event) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	return s.conn.Publish(s.prefix+"."+e.Type, data)
+}
+
+type KafkaSink struct {
+	writer KafkaWriter
+	topic  string
+}
+
+func NewKafkaSink(writer KafkaWriter, topic string) *KafkaSink {
+	return &KafkaSink{
+		writer: writer,
+		topic:  topic,
+	}
+}
+
+func (s *KafkaSink) Name() string {
+	return "kafka"
+}
+
+func (s *KafkaSink) Publish(ctx context.Context, e Event) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	return s.writer.WriteMessages(ctx, KafkaMessage{
+		Topic: s.topic,
+		Key:   []byte(e.AggregateID),
+		Value: data,
+		Headers: map[string]string{
+			"event_id":   e.ID,
+			"event_type": e.Type,
+		},
+	})
+}
@@ -0,0 +1,29 @@
+// Package event defines domain events and the sinks the outbox relay
+// publishes them to.
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// Event is a domain event read back from the outbox. AggregateID is the user
+// the event belongs to; events sharing it are published in Sequence order.
+type Event struct {
+	ID          string          `json:"id"`
+	Sequence    int64           `json:"sequence"`
+	Type        string          `json:"type"`
+	AggregateID string          `json:"aggregate_id"`
+	Payload     json.RawMessage `json:"payload"`
+	OccurredAt  time.Time       `json:"occurred_at"`
+}
+
+// Sink receives events from the relay. Delivery is at-least-once, so a sink
+// may see the same event ID more than once and must be idempotent.
+type Sink interface {
+	Name() string
+	Publish(ctx context.Context, e Event) error
+}
+
+type Handler func(ctx context.Context, e Event) error
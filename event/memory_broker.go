@@ -0,0 +1,46 @@
+package event
+
+import (
+	"context"
+	"sync"
+)
+
+// MemoryBroker is an in-memory fake that satisfies both NATSPublisher and
+// KafkaWriter, recording every message for local runs and tests.
+type MemoryBroker struct {
+	mu       sync.Mutex
+	messages []KafkaMessage
+	err      error
+}
+
+func NewMemoryBroker() *MemoryBroker {
+	return &MemoryBroker{}
+}
+
+// FailWith makes every following publish return err until reset with nil.
+func (b *MemoryBroker) FailWith(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.err = err
+}
+
+func (b *MemoryBroker) Publish(subject string, data []byte) error {
+	return b.WriteMessages(context.Background(), KafkaMessage{Topic: subject, Value: data})
+}
+
+func (b *MemoryBroker) WriteMessages(ctx context.Context, msgs ...KafkaMessage) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.err != nil {
+		return b.err
+	}
+	b.messages = append(b.messages, msgs...)
+	return nil
+}
+
+func (b *MemoryBroker) Messages() []KafkaMessage {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]KafkaMessage(nil), b.messages...)
+}
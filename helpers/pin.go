@@ -1,16 +1,75 @@
 package helpers
 
-import "golang.org/x/crypto/bcrypt"
+import (
+	"sync"
+
+	"github.com/Amierza/e-wallet/config"
+)
+
+const (
+	defaultBcryptCost    = 12
+	defaultArgon2Memory  = 64 * 1024
+	defaultArgon2Time    = 3
+	defaultArgon2Threads = 2
+	defaultArgon2KeyLen  = 32
+	defaultArgon2SaltLen = 16
+)
+
+var (
+	pinHasher     *PinHasher
+	pinHasherOnce sync.Once
+)
+
+// SetPinHasher replaces the hasher used by HashPin and ChcekPin.
+func SetPinHasher(h *PinHasher) {
+	pinHasherOnce.Do(func() {})
+	pinHasher = h
+}
+
+func defaultPinHasher() *PinHasher {
+	pinHasherOnce.Do(func() {
+		pinHasher = NewPinHasherFromConfig(config.PIN{})
+	})
+	return pinHasher
+}
+
+// NewPinHasherFromConfig builds the hasher from the configured algorithm
+// (bcrypt or argon2id), its cost settings and pepper. Unset costs use the
+// defaults. Both algorithms can always verify, so switching algorithm never
+// locks anyone out.
+func NewPinHasherFromConfig(cfg config.PIN) *PinHasher {
+	bcryptHasher := BcryptHasher{Cost: orDefault(cfg.BcryptCost, defaultBcryptCost)}
+	argon2Hasher := Argon2idHasher{
+		Memory:  uint32(orDefault(cfg.Argon2MemoryKB, defaultArgon2Memory)),
+		Time:    uint32(orDefault(cfg.Argon2Time, defaultArgon2Time)),
+		Threads: uint8(orDefault(cfg.Argon2Threads, defaultArgon2Threads)),
+		KeyLen:  defaultArgon2KeyLen,
+		SaltLen: defaultArgon2SaltLen,
+	}
+
+	if cfg.HashAlgorithm == PinHashArgon2id {
+		return NewPinHasher(argon2Hasher, cfg.Pepper, bcryptHasher)
+	}
+	return NewPinHasher(bcryptHasher, cfg.Pepper, argon2Hasher)
+}
+
+func orDefault(value int, fallback int) int {
+	if value <= 0 {
+		return fallback
+	}
+	return value
+}
 
 func HashPin(pin string) (string, error) {
-	bytes, err := bcrypt.GenerateFromPassword([]byte(pin), 4)
-	return string(bytes), err
+	return defaultPinHasher().Hash(pin)
 }
 
 func ChcekPin(hashPin string, plainPin []byte) (bool, error) {
-	hashP := []byte(hashPin)
-	if err := bcrypt.CompareHashAndPassword(hashP, plainPin); err != nil {
-		return false, err
-	}
-	return true, nil
+	return defaultPinHasher().Verify(hashPin, string(plainPin))
+}
+
+// PinNeedsRehash reports whether a verified hash should be upgraded to the
+// current algorithm, parameters and pepper.
+func PinNeedsRehash(hashPin string) bool {
+	return defaultPinHasher().NeedsRehash(hashPin)
 }
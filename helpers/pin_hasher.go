@@ -0,0 +1,207 @@
+package helpers
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	PinHashBcrypt   = "bcrypt"
+	PinHashArgon2id = "argon2id"
+
+	// pepperPrefix marks hashes whose input was peppered, so turning a pepper
+	// on later does not lock out users hashed without one.
+	pepperPrefix = "$pep1"
+)
+
+var (
+	ErrUnknownPinHash = errors.New("unknown pin hash format")
+	ErrPepperMissing  = errors.New("pin hash is peppered but no pepper is configured")
+)
+
+// Hasher is a single algorithm with its parameters encoded in its output.
+type Hasher interface {
+	Hash(pin []byte) (string, error)
+	Verify(hash string, pin []byte) (bool, error)
+	// Owns reports whether the hash was produced by this algorithm.
+	Owns(hash string) bool
+	// Outdated reports whether an owned hash used weaker parameters.
+	Outdated(hash string) bool
+}
+
+type BcryptHasher struct {
+	Cost int
+}
+
+func (h BcryptHasher) Hash(pin []byte) (string, error) {
+	bytes, err := bcrypt.GenerateFromPassword(pin, h.Cost)
+	return string(bytes), err
+}
+
+func (h BcryptHasher) Verify(hash string, pin []byte) (bool, error) {
+	if err := bcrypt.CompareHashAndPassword([]byte(hash), pin); err != nil {
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func (h BcryptHasher) Owns(hash string) bool {
+	return strings.HasPrefix(hash, "$2a$") || strings.HasPrefix(hash, "$2b$") || strings.HasPrefix(hash, "$2y$")
+}
+
+func (h BcryptHasher) Outdated(hash string) bool {
+	cost, err := bcrypt.Cost([]byte(hash))
+	return err != nil || cost < h.Cost
+}
+
+// Argon2idHasher encodes hashes in the PHC string format:
+// $argon2id$v=19$m=<KiB>,t=<passes>,p=<threads>$<salt>$<key>
+type Argon2idHasher struct {
+	Memory  uint32
+	Time    uint32
+	Threads uint8
+	KeyLen  uint32
+	SaltLen uint32
+}
+
+type argon2Params struct {
+	memory  uint32
+	time    uint32
+	threads uint8
+	salt    []byte
+	key     []byte
+}
+
+func (h Argon2idHasher) Hash(pin []byte) (string, error) {
+	salt := make([]byte, h.SaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key := argon2.IDKey(pin, salt, h.Time, h.Memory, h.Threads, h.KeyLen)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version, h.Memory, h.Time, h.Threads,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+func (h Argon2idHasher) Verify(hash string, pin []byte) (bool, error) {
+	p, err := decodeArgon2id(hash)
+	if err != nil {
+		return false, err
+	}
+
+	key := argon2.IDKey(pin, p.salt, p.time, p.memory, p.threads, uint32(len(p.key)))
+	return subtle.ConstantTimeCompare(key, p.key) == 1, nil
+}
+
+func (h Argon2idHasher) Owns(hash string) bool {
+	return strings.HasPrefix(hash, "$argon2id$")
+}
+
+func (h Argon2idHasher) Outdated(hash string) bool {
+	p, err := decodeArgon2id(hash)
+	return err != nil || p.memory < h.Memory || p.time < h.Time || p.threads < h.Threads || uint32(len(p.key)) < h.KeyLen
+}
+
+func decodeArgon2id(hash string) (argon2Params, error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return argon2Params{}, ErrUnknownPinHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return argon2Params{}, ErrUnknownPinHash
+	}
+
+	var p argon2Params
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.memory, &p.time, &p.threads); err != nil {
+		return argon2Params{}, ErrUnknownPinHash
+	}
+
+	var err error
+	if p.salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return argon2Params{}, ErrUnknownPinHash
+	}
+	if p.key, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil {
+		return argon2Params{}, ErrUnknownPinHash
+	}
+
+	return p, nil
+}
+
+// PinHasher hashes with the primary algorithm, verifies hashes from any
+// known algorithm, and optionally mixes a server-side pepper into the input.
+type PinHasher struct {
+	primary Hasher
+	known   []Hasher
+	pepper  []byte
+}
+
+func NewPinHasher(primary Hasher, pepper string, legacy ...Hasher) *PinHasher {
+	return &PinHasher{
+		primary: primary,
+		known:   append([]Hasher{primary}, legacy...),
+		pepper:  []byte(pepper),
+	}
+}
+
+func (h *PinHasher) Hash(pin string) (string, error) {
+	if len(h.pepper) == 0 {
+		return h.primary.Hash([]byte(pin))
+	}
+
+	hash, err := h.primary.Hash(h.peppered(pin))
+	if err != nil {
+		return "", err
+	}
+	return pepperPrefix + hash, nil
+}
+
+func (h *PinHasher) Verify(hash string, pin string) (bool, error) {
+	input := []byte(pin)
+	if inner, ok := strings.CutPrefix(hash, pepperPrefix); ok {
+		if len(h.pepper) == 0 {
+			return false, ErrPepperMissing
+		}
+		hash, input = inner, h.peppered(pin)
+	}
+
+	for _, hasher := range h.known {
+		if hasher.Owns(hash) {
+			return hasher.Verify(hash, input)
+		}
+	}
+	return false, ErrUnknownPinHash
+}
+
+// NeedsRehash reports whether a hash that just verified should be replaced:
+// it uses another algorithm, weaker parameters, or a different pepper setting.
+func (h *PinHasher) NeedsRehash(hash string) bool {
+	inner, peppered := strings.CutPrefix(hash, pepperPrefix)
+	if peppered != (len(h.pepper) > 0) {
+		return true
+	}
+
+	return !h.primary.Owns(inner) || h.primary.Outdated(inner)
+}
+
+// peppered keys the PIN with HMAC-SHA256 so the stored hash is useless
+// without the pepper, and hex-encodes it to stay within bcrypt's 72 bytes.
+func (h *PinHasher) peppered(pin string) []byte {
+	mac := hmac.New(sha256.New, h.pepper)
+	mac.Write([]byte(pin))
+	return []byte(hex.EncodeToString(mac.Sum(nil)))
+}
@@ -0,0 +1,117 @@
+package helpers
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Amierza/e-wallet/config"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Cheap parameters keep the tests fast; the migration logic does not depend
+// on the cost.
+var (
+	testBcrypt = BcryptHasher{Cost: bcrypt.MinCost}
+	testArgon2 = Argon2idHasher{Memory: 64, Time: 1, Threads: 1, KeyLen: 32, SaltLen: 16}
+)
+
+// TestPinHasherMigration follows one stored hash through the configuration
+// changes an operator makes over time: switching to argon2id, adding a pepper
+// and raising the argon2id cost. Each step must still accept the old hash and
+// ask for a rehash, and the rehashed value must be accepted from then on.
+func TestPinHasherMigration(t *testing.T) {
+	const pin = "482913"
+
+	steps := []struct {
+		name   string
+		hasher *PinHasher
+	}{
+		{"bcrypt", NewPinHasher(testBcrypt, "")},
+		{"argon2id", NewPinHasher(testArgon2, "", testBcrypt)},
+		{"argon2id with a pepper", NewPinHasher(testArgon2, "pepper", testBcrypt)},
+		{"stronger argon2id", NewPinHasher(Argon2idHasher{Memory: 128, Time: 1, Threads: 1, KeyLen: 32, SaltLen: 16}, "pepper", testBcrypt)},
+	}
+
+	stored, err := steps[0].hasher.Hash(pin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, step := range steps[1:] {
+		h := step.hasher
+		if ok, err := h.Verify(stored, pin); !ok || err != nil {
+			t.Fatalf("%s: Verify(previous hash) = (%v, %v), want (true, nil)", step.name, ok, err)
+		}
+		if wrong, _ := h.Verify(stored, "000001"); wrong {
+			t.Fatalf("%s: Verify() accepted the wrong PIN", step.name)
+		}
+		if !h.NeedsRehash(stored) {
+			t.Fatalf("%s: NeedsRehash(previous hash) = false", step.name)
+		}
+
+		if stored, err = h.Hash(pin); err != nil {
+			t.Fatal(err)
+		}
+		if ok, err := h.Verify(stored, pin); !ok || err != nil || h.NeedsRehash(stored) {
+			t.Fatalf("%s: rehashed value not accepted as current", step.name)
+		}
+	}
+}
+
+// A hash the current configuration cannot check must fail loudly rather than
+// look like a wrong PIN, or every user would be locked out silently.
+func TestPinHasherRejectsUnverifiableHashes(t *testing.T) {
+	argon2, err := NewPinHasher(testArgon2, "").Hash("482913")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewPinHasher(testBcrypt, "").Verify(argon2, "482913"); !errors.Is(err, ErrUnknownPinHash) {
+		t.Errorf("Verify(argon2id hash) without argon2id = %v, want ErrUnknownPinHash", err)
+	}
+
+	peppered, err := NewPinHasher(testArgon2, "pepper").Hash("482913")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewPinHasher(testArgon2, "", testBcrypt).Verify(peppered, "482913"); !errors.Is(err, ErrPepperMissing) {
+		t.Errorf("Verify(peppered hash) without a pepper = %v, want ErrPepperMissing", err)
+	}
+}
+
+func TestPinHasherPepper(t *testing.T) {
+	hashed, err := NewPinHasher(testBcrypt, "pepper").Hash("482913")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(hashed, pepperPrefix+"$2a$") {
+		t.Fatalf("Hash() = %q, want a peppered bcrypt hash", hashed)
+	}
+
+	if ok, err := NewPinHasher(testBcrypt, "other").Verify(hashed, "482913"); ok || err != nil {
+		t.Errorf("Verify(other pepper) = (%v, %v), want (false, nil)", ok, err)
+	}
+}
+
+func TestNewPinHasherFromConfig(t *testing.T) {
+	tests := []struct {
+		algorithm string
+		want      Hasher
+	}{
+		{"", BcryptHasher{Cost: defaultBcryptCost}},
+		{PinHashBcrypt, BcryptHasher{Cost: defaultBcryptCost}},
+		{PinHashArgon2id, Argon2idHasher{
+			Memory: defaultArgon2Memory, Time: defaultArgon2Time, Threads: defaultArgon2Threads,
+			KeyLen: defaultArgon2KeyLen, SaltLen: defaultArgon2SaltLen,
+		}},
+	}
+
+	for _, tt := range tests {
+		h := NewPinHasherFromConfig(config.PIN{HashAlgorithm: tt.algorithm})
+		if h.primary != tt.want {
+			t.Errorf("NewPinHasherFromConfig(%q) primary = %+v, want %+v", tt.algorithm, h.primary, tt.want)
+		}
+		if len(h.known) != 2 {
+			t.Errorf("NewPinHasherFromConfig(%q) knows %d algorithms, want both", tt.algorithm, len(h.known))
+		}
+	}
+}
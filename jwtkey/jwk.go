@@ -0,0 +1,92 @@
+package jwtkey
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+)
+
+// JWK is the public half of a key as published at /.well-known/jwks.json
+// (RFC 7517, with OKP keys from RFC 8037).
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+func (s *Set) JWKS() JWKS {
+	keys := make([]JWK, 0, len(s.keys))
+	for _, key := range s.keys {
+		jwk := publicJWK(key.Public)
+		jwk.Use = "sig"
+		jwk.Alg = key.Method.Alg()
+		jwk.Kid = key.ID
+		keys = append(keys, jwk)
+	}
+
+	return JWKS{Keys: keys}
+}
+
+func publicJWK(public crypto.PublicKey) JWK {
+	switch public := public.(type) {
+	case *rsa.PublicKey:
+		return JWK{
+			Kty: "RSA",
+			N:   base64.RawURLEncoding.EncodeToString(public.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes()),
+		}
+	case ed25519.PublicKey:
+		return JWK{
+			Kty: "OKP",
+			Crv: "Ed25519",
+			X:   base64.RawURLEncoding.EncodeToString(public),
+		}
+	default:
+		return JWK{}
+	}
+}
+
+// Thumbprint is the RFC 7638 SHA-256 thumbprint of a public key: the hash of
+// its required JWK members in lexicographic order.
+func Thumbprint(public crypto.PublicKey) (string, error) {
+	jwk := publicJWK(public)
+
+	var members any
+	switch jwk.Kty {
+	case "RSA":
+		members = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{jwk.E, jwk.Kty, jwk.N}
+	case "OKP":
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{jwk.Crv, jwk.Kty, jwk.X}
+	default:
+		return "", ErrUnsupportedKey
+	}
+
+	data, err := json.Marshal(members)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+	return base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,112 @@
+// Package jwtkey holds the asymmetric keys tokens are signed and verified
+// with. One key signs; any number of older or external keys may still verify
+// while a rotation is rolled out, and all public halves are published as a
+// JWK set.
+package jwtkey
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	ErrUnsupportedKey = errors.New("unsupported key type, expected RSA or Ed25519")
+	ErrNoSigner       = errors.New("signing key has no private half")
+)
+
+// Key is one RSA or Ed25519 key. Signer is nil for keys that are only used to
+// verify, such as the previous key during a rotation.
+type Key struct {
+	ID     string
+	Method jwt.SigningMethod
+	Signer crypto.Signer
+	Public crypto.PublicKey
+}
+
+// NewKey wraps a private or public RSA or Ed25519 key. An empty id is replaced
+// by the key's RFC 7638 thumbprint, so the same key always gets the same kid.
+func NewKey(id string, key any) (Key, error) {
+	var k Key
+
+	switch key := key.(type) {
+	case *rsa.PrivateKey:
+		k = Key{Method: jwt.SigningMethodRS256, Signer: key, Public: &key.PublicKey}
+	case *rsa.PublicKey:
+		k = Key{Method: jwt.SigningMethodRS256, Public: key}
+	case ed25519.PrivateKey:
+		k = Key{Method: jwt.SigningMethodEdDSA, Signer: key, Public: key.Public()}
+	case ed25519.PublicKey:
+		k = Key{Method: jwt.SigningMethodEdDSA, Public: key}
+	default:
+		return Key{}, ErrUnsupportedKey
+	}
+
+	if id == "" {
+		thumbprint, err := Thumbprint(k.Public)
+		if err != nil {
+			return Key{}, err
+		}
+		id = thumbprint
+	}
+	k.ID = id
+
+	return k, nil
+}
+
+// LoadFile reads a PEM file holding a PKCS#8 or PKCS#1 private key, or a PKIX
+// public key.
+func LoadFile(path string, id string) (Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Key{}, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return Key{}, fmt.Errorf("%s: no PEM block found", path)
+	}
+
+	var key any
+	switch block.Type {
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		key, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		key, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		return Key{}, fmt.Errorf("%s: unsupported PEM block %q", path, block.Type)
+	}
+	if err != nil {
+		return Key{}, fmt.Errorf("%s: %w", path, err)
+	}
+
+	k, err := NewKey(id, key)
+	if err != nil {
+		return Key{}, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return k, nil
+}
+
+// Generate creates a throwaway Ed25519 key. Tokens signed with it stop
+// verifying once the process exits, so it is only fit for local runs.
+func Generate() (Key, error) {
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return Key{}, err
+	}
+
+	return NewKey("", private)
+}
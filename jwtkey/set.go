@@ -0,0 +1,83 @@
+package jwtkey
+
+import (
+	"fmt"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Set is the signing key plus every key tokens may still be verified with.
+type Set struct {
+	signing Key
+	keys    []Key
+	byID    map[string]Key
+}
+
+// NewSet builds a set that signs with signing and also accepts tokens signed
+// by any of verification. Keys are told apart by ID, so rotating in a new
+// signing key while listing the old one under verification keeps existing
+// tokens valid until they expire.
+func NewSet(signing Key, verification ...Key) (*Set, error) {
+	if signing.Signer == nil {
+		return nil, ErrNoSigner
+	}
+
+	s := &Set{
+		signing: signing,
+		byID:    map[string]Key{},
+	}
+	for _, key := range append([]Key{signing}, verification...) {
+		if _, ok := s.byID[key.ID]; ok {
+			continue
+		}
+
+		// Only the public half is ever needed to verify.
+		key.Signer = nil
+		s.byID[key.ID] = key
+		s.keys = append(s.keys, key)
+	}
+
+	return s, nil
+}
+
+func (s *Set) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(s.signing.Method, claims)
+	token.Header["kid"] = s.signing.ID
+
+	return token.SignedString(s.signing.Signer)
+}
+
+// Keyfunc resolves the verification key from the token's kid and refuses
+// tokens whose alg does not match that key, so a public key can never be
+// misused as an HMAC secret.
+func (s *Set) Keyfunc(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := s.byID[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+
+	return key.Public, nil
+}
+
+// Algorithms lists the algorithms of all keys, for jwt.WithValidMethods.
+func (s *Set) Algorithms() []string {
+	seen := map[string]bool{}
+	algs := []string{}
+	for _, key := range s.keys {
+		if alg := key.Method.Alg(); !seen[alg] {
+			seen[alg] = true
+			algs = append(algs, alg)
+		}
+	}
+
+	return algs
+}
+
+func (s *Set) SigningKeyID() string {
+	return s.signing.ID
+}
@@ -0,0 +1,99 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// explainedPlaceholder is how GORM's postgres dialector leaves a $N
+// placeholder it has no value for.
+var explainedPlaceholder = regexp.MustCompile(`\$(\d+)\$`)
+
+// GormLogger sends GORM's logs to slog with the context of the query, so SQL
+// carries the request ID of the request that ran it. Statements are logged
+// with placeholders instead of their values, which keep PIN hashes and phone
+// numbers out of the log. Failed queries are errors, slow ones warnings and
+// the rest debug.
+type GormLogger struct {
+	logger        *slog.Logger
+	slowThreshold time.Duration
+	level         logger.LogLevel
+}
+
+func NewGormLogger(l *slog.Logger, slowThreshold time.Duration) *GormLogger {
+	return &GormLogger{
+		logger:        l,
+		slowThreshold: slowThreshold,
+		level:         logger.Info,
+	}
+}
+
+func (g *GormLogger) LogMode(level logger.LogLevel) logger.Interface {
+	clone := *g
+	clone.level = level
+	return &clone
+}
+
+func (g *GormLogger) Info(ctx context.Context, msg string, args ...any) {
+	if g.level >= logger.Info {
+		g.logger.InfoContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+func (g *GormLogger) Warn(ctx context.Context, msg string, args ...any) {
+	if g.level >= logger.Warn {
+		g.logger.WarnContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+func (g *GormLogger) Error(ctx context.Context, msg string, args ...any) {
+	if g.level >= logger.Error {
+		g.logger.ErrorContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+func (g *GormLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	if g.level <= logger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	level := slog.LevelDebug
+	msg := "query"
+	switch {
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound) && g.level >= logger.Error:
+		level, msg = slog.LevelError, "query failed"
+	case g.slowThreshold > 0 && elapsed > g.slowThreshold && g.level >= logger.Warn:
+		level, msg = slog.LevelWarn, "slow query"
+	case g.level < logger.Info:
+		return
+	}
+	if !g.logger.Enabled(ctx, level) {
+		return
+	}
+
+	sql, rows := fc()
+	sql = explainedPlaceholder.ReplaceAllString(sql, "$$$1")
+	attrs := []slog.Attr{
+		slog.String("sql", sql),
+		slog.Int64("rows", rows),
+		slog.Float64("duration_ms", float64(elapsed.Microseconds())/1000),
+	}
+	if level == slog.LevelError {
+		attrs = append(attrs, slog.Any("error", err))
+	}
+
+	g.logger.LogAttrs(ctx, level, msg, attrs...)
+}
+
+// ParamsFilter makes GORM render statements with placeholders.
+func (g *GormLogger) ParamsFilter(ctx context.Context, sql string, params ...any) (string, []any) {
+	return sql, nil
+}
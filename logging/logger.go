@@ -0,0 +1,101 @@
+// Package logging builds the structured logger of the service. Every record
+// passes through the redaction in this package and carries the request ID and
+// user ID found in its context, so logs of one request can be followed from
+// the access log through the services down to the SQL.
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+
+	"github.com/Amierza/e-wallet/auth"
+	"github.com/Amierza/e-wallet/config"
+	"github.com/Amierza/e-wallet/constants"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// New returns a logger writing to w in the configured format, at the
+// configured level or above.
+func New(cfg config.Log, w io.Writer) *slog.Logger {
+	opts := &slog.HandlerOptions{
+		Level:       ParseLevel(cfg.Level),
+		ReplaceAttr: redactAttr,
+	}
+
+	var handler slog.Handler
+	if cfg.Format == constants.ENUM_LOG_FORMAT_TEXT {
+		handler = slog.NewTextHandler(w, opts)
+	} else {
+		handler = slog.NewJSONHandler(w, opts)
+	}
+
+	return slog.New(contextHandler{handler})
+}
+
+// ParseLevel reads a configured level; anything unknown is info.
+func ParseLevel(level string) slog.Level {
+	switch level {
+	case constants.ENUM_LOG_LEVEL_DEBUG:
+		return slog.LevelDebug
+	case constants.ENUM_LOG_LEVEL_WARN:
+		return slog.LevelWarn
+	case constants.ENUM_LOG_LEVEL_ERROR:
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// requestIDKey is unexported so no other package can read or overwrite the
+// value by accident.
+type requestIDKey struct{}
+
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// contextHandler adds the request ID, the trace and the authenticated user of
+// the record's context, and scrubs the message the way attributes are.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if ctx != nil {
+		if requestID := RequestID(ctx); requestID != "" {
+			r.AddAttrs(slog.String("request_id", requestID))
+		}
+		if span := trace.SpanContextFromContext(ctx); span.IsValid() {
+			r.AddAttrs(slog.String("trace_id", span.TraceID().String()), slog.String("span_id", span.SpanID().String()))
+		}
+		if userID, err := auth.UserID(ctx); err == nil && !hasAttr(r, "user_id") {
+			r.AddAttrs(slog.String("user_id", userID))
+		}
+	}
+	r.Message = Scrub(r.Message)
+
+	return h.Handler.Handle(ctx, r)
+}
+
+func hasAttr(r slog.Record, key string) bool {
+	found := false
+	r.Attrs(func(attr slog.Attr) bool {
+		found = attr.Key == key
+		return !found
+	})
+	return found
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,62 @@
+package logging
+
+import (
+	"errors"
+	"log/slog"
+	"testing"
+)
+
+func TestRedactQuery(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"", ""},
+		{"page=2&per_page=10", "page=2&per_page=10"},
+		{"access_token=eyJabc.def.ghi&page=1", "access_token=[REDACTED]&page=1"},
+		{"token=abc&new_pin=123456", "new_pin=[REDACTED]&token=[REDACTED]"},
+		{"status_code=200", "status_code=200"},
+		{"phone_number=081234567890", "phone_number=********7890"},
+		{"q=call+081234567890", "q=call ********7890"},
+		{"q=eyJhbGciOi.eyJzdWIi.sig", "q=[REDACTED]"},
+		{"a=1&a=2", "a=1&a=2"},
+		{"bad=%zz", redacted},
+	}
+
+	for _, tt := range tests {
+		if got := RedactQuery(tt.query); got != tt.want {
+			t.Errorf("RedactQuery(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestRedactAttr(t *testing.T) {
+	tests := []struct {
+		name   string
+		groups []string
+		attr   slog.Attr
+		want   slog.Value
+	}{
+		{"secret key", nil, slog.String("pin", "123456"), slog.StringValue(redacted)},
+		{"secret word in key", nil, slog.String("refresh-token", "abc"), slog.StringValue(redacted)},
+		{"secret of another kind", nil, slog.Int("otp", 123456), slog.StringValue(redacted)},
+		{"secret in a group", []string{"request"}, slog.String("Authorization", "Bearer x"), slog.StringValue(redacted)},
+		{"phone key", nil, slog.String("phone_number", "081234567890"), slog.StringValue("********7890")},
+		{"phone in text", nil, slog.String("error", "user 6281234567890 not found"), slog.StringValue("user *********7890 not found")},
+		{"phone in error", nil, slog.Any("error", errors.New("no user 081234567890")), slog.StringValue("no user ********7890")},
+		{"jwt in text", nil, slog.String("detail", "got eyJa.eyJb.c"), slog.StringValue("got " + redacted)},
+		{"plain value", nil, slog.String("status_code", "200"), slog.StringValue("200")},
+		{"other kinds untouched", nil, slog.Int("count", 3), slog.IntValue(3)},
+		{"message kept", nil, slog.String(slog.MessageKey, "pin 081234567890"), slog.StringValue("pin 081234567890")},
+		{"message key in a group", []string{"g"}, slog.String(slog.MessageKey, "081234567890"), slog.StringValue("********7890")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := redactAttr(tt.groups, tt.attr)
+			if got.Key != tt.attr.Key || !got.Value.Equal(tt.want) {
+				t.Errorf("redactAttr(%v) = %v, want %s=%v", tt.attr, got, tt.attr.Key, tt.want)
+			}
+		})
+	}
+}
@@ -1,54 +1,375 @@
 package main
 
 import (
-	"log"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
 	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 
 	"github.com/Amierza/e-wallet/cmd"
 	"github.com/Amierza/e-wallet/config"
+	"github.com/Amierza/e-wallet/constants"
 	"github.com/Amierza/e-wallet/controller"
+	"github.com/Amierza/e-wallet/event"
+	"github.com/Amierza/e-wallet/jwtkey"
+	"github.com/Amierza/e-wallet/metrics"
 	"github.com/Amierza/e-wallet/middleware"
+	"github.com/Amierza/e-wallet/migrations"
+	"github.com/Amierza/e-wallet/notify"
+	"github.com/Amierza/e-wallet/ratelimit"
+	"github.com/Amierza/e-wallet/realtime"
 	"github.com/Amierza/e-wallet/repository"
 	"github.com/Amierza/e-wallet/routes"
 	"github.com/Amierza/e-wallet/service"
+	"github.com/Amierza/e-wallet/tracing"
+	"github.com/Amierza/e-wallet/webhook"
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 func main() {
-	db := config.SetUpDatabaseConnection()
-	defer config.CloseDatabaseConnection(db)
+	os.Exit(cmd.Execute(os.Args[1:], serve))
+}
+
+// serve wires the API on top of db and runs it until SIGINT or SIGTERM.
+func serve(cfg config.Config, db *gorm.DB) error {
+	jwtKeys, err := newJWTKeys(cfg)
+	if err != nil {
+		return fmt.Errorf("loading jwt keys: %w", err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return fmt.Errorf("getting database pool: %w", err)
+	}
+	prometheus := metrics.NewPrometheus(sqlDB, cfg.Database.Name)
 
-	if len(os.Args) > 1 {
-		cmd.Command(db)
-		return
+	migrator, err := migrations.NewMigrator(db)
+	if err != nil {
+		return fmt.Errorf("loading migrations: %w", err)
+	}
+
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing, cfg.App.Env)
+	if err != nil {
+		return fmt.Errorf("setting up tracing: %w", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			slog.Warn("flushing spans", slog.Any("error", err))
+		}
+	}()
+	if err := db.Use(tracing.NewGormPlugin()); err != nil {
+		return fmt.Errorf("registering tracing plugin: %w", err)
+	}
+
+	notifier, err := newNotifier(cfg.Notifier)
+	if err != nil {
+		return err
 	}
 
 	var (
-		jwtService     service.JWTService        = service.NewJWTService()
-		userRepository repository.UserRepository = repository.NewUserRepository(db)
-		userService    service.UserService       = service.NewUserService(userRepository, jwtService)
-		userController controller.UserController = controller.NewUserController(userService)
+		userRepository      repository.UserRepository      = repository.NewUserRepository(db)
+		deviceRepository    repository.DeviceRepository    = repository.NewDeviceRepository(db)
+		jwtService          service.JWTService             = service.NewJWTService(userRepository, deviceRepository, jwtKeys, cfg.JWT)
+		fxRepository        repository.FXRepository        = repository.NewFXRepository(db)
+		webhookRepository   repository.WebhookRepository   = repository.NewWebhookRepository(db)
+		outboxRepository    repository.OutboxRepository    = repository.NewOutboxRepository(db)
+		otpRepository       repository.OTPRepository       = repository.NewOTPRepository(db)
+		twoFactorRepository repository.TwoFactorRepository = repository.NewTwoFactorRepository(db)
+		transactor          repository.Transactor          = repository.NewTransactor(db)
+		eventBus            *event.Bus                     = event.NewBus()
+		webhookService      service.WebhookService         = service.NewWebhookService(webhookRepository, webhook.Policy{AllowHTTP: cfg.IsDevelopment()})
+		outboxService       service.OutboxService          = service.NewOutboxService(outboxRepository, eventBus, service.NewWebhookSink(webhookService))
+		otpService          service.OTPService             = service.NewOTPService(otpRepository, notifier, cfg.Secrets.OTP)
+		deviceService       service.DeviceService          = service.NewDeviceService(deviceRepository, outboxRepository, transactor, notifier)
+		twoFactorService    service.TwoFactorService       = service.NewTwoFactorService(twoFactorRepository, userRepository, jwtService, deviceService, cfg.Secrets.TOTPKey, prometheus)
+		userService         service.UserService            = service.NewTracedUserService(service.NewUserService(userRepository, fxRepository, outboxRepository, transactor, otpService, notifier, jwtService, twoFactorService, deviceService, service.NewSignedTokens(cfg.Secrets.Signing, cfg.App.URL), prometheus))
+		realtimeHub         *realtime.Hub                  = realtime.NewHub(newRealtimeBackend(db, cfg.Realtime))
+		realtimeService     service.RealtimeService        = service.NewRealtimeService(realtimeHub)
+		fxService           service.FXService              = service.NewFXService(fxRepository)
+		userController      controller.UserController      = controller.NewUserController(userService)
+		twoFactorController controller.TwoFactorController = controller.NewTwoFactorController(twoFactorService)
+		deviceController    controller.DeviceController    = controller.NewDeviceController(deviceService)
+		fxController        controller.FXController        = controller.NewFXController(fxService)
+		webhookController   controller.WebhookController   = controller.NewWebhookController(webhookService)
+		realtimeController  controller.RealtimeController  = controller.NewRealtimeController(realtimeService)
+		wellKnownController controller.WellKnownController = controller.NewWellKnownController(jwtService)
+		healthService       service.HealthService          = service.NewHealthService(sqlDB, migrator)
+		healthController    controller.HealthController    = controller.NewHealthController(healthService)
 	)
 
-	server := gin.Default()
-	server.Use(middleware.CORSMiddleware())
+	eventBus.Subscribe(event.AllEvents, realtimeService.HandleEvent)
 
-	routes.User(server, userController, jwtService)
+	if created, err := fxService.SeedRatesFromFile(context.Background(), cfg.FX.RatesFile); err != nil {
+		slog.Warn("skip seeding fx rates", slog.String("file", cfg.FX.RatesFile), slog.Any("error", err))
+	} else if created > 0 {
+		slog.Info("seeded missing fx rates", slog.String("file", cfg.FX.RatesFile), slog.Int("created", created))
+	}
 
-	server.Static("/assets", "./assets")
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8888"
+	if cfg.Log.Level != constants.ENUM_LOG_LEVEL_DEBUG {
+		gin.SetMode(gin.ReleaseMode)
+	}
+
+	logger := slog.Default()
+	server := gin.New()
+	if err := server.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		return fmt.Errorf("setting trusted proxies: %w", err)
 	}
+	server.Use(
+		middleware.RequestID(),
+		middleware.Tracing(),
+		middleware.AccessLog(logger),
+		middleware.Metrics(prometheus),
+		middleware.Recovery(logger),
+		middleware.CORSMiddleware(),
+	)
 
+	rateLimits := newRateLimits(db, cfg.RateLimit)
+	routes.User(server, userController, jwtService, deviceService, rateLimits)
+	routes.TwoFactor(server, twoFactorController, jwtService, deviceService, rateLimits)
+	routes.Device(server, deviceController, jwtService)
+	routes.FX(server, fxController, jwtService)
+	routes.Webhook(server, webhookController, jwtService)
+	routes.Realtime(server, realtimeController, jwtService)
+	routes.WellKnown(server, wellKnownController)
+	routes.Metrics(server, prometheus.Handler(), cfg.Metrics.Token)
+	routes.Health(server, healthController)
+
+	server.Static("/assets", "./assets")
 	var serve string
-	if os.Getenv("APP_ENV") == "localhost" {
-		serve = "127.0.0.1:" + port
+	if cfg.IsDevelopment() {
+		serve = fmt.Sprintf("127.0.0.1:%d", cfg.App.Port)
 	} else {
-		serve = ":" + port
+		serve = fmt.Sprintf(":%d", cfg.App.Port)
 	}
 
-	if err := server.Run(serve); err != nil {
-		log.Fatalf("error running server: %v", err)
+	httpServer := &http.Server{
+		Addr:              serve,
+		Handler:           server,
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+		MaxHeaderBytes:    cfg.Server.MaxHeaderBytes,
 	}
+	httpServer.RegisterOnShutdown(realtimeHub.Close)
+
+	err = runServer(httpServer, cfg.Server, healthService.Drain,
+		healthService.Track("realtime_hub", realtimeHub.Run),
+		healthService.Track("outbox_relay", outboxService.StartRelay),
+		healthService.Track("webhook_dispatcher", webhookService.StartDispatcher),
+	)
+	if err != nil {
+		return err
+	}
+
+	slog.Info("server stopped")
+	return nil
+}
+
+// runServer starts the workers and serves until SIGINT or SIGTERM. It then
+// calls drain and keeps serving for cfg.ShutdownDelay, so readiness probes
+// see the server leaving before it stops accepting connections. After that it
+// waits for in-flight requests and finally stops the workers, all within
+// cfg.ShutdownTimeout. Workers keep running while requests drain so events
+// those requests emit are still relayed.
+func runServer(server *http.Server, cfg config.Server, drain func(), workers ...func(context.Context)) error {
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+
+	var running sync.WaitGroup
+	for _, worker := range workers {
+		running.Add(1)
+		go func() {
+			defer running.Done()
+			worker(workerCtx)
+		}()
+	}
+
+	signalCtx, stopSignals := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stopSignals()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		slog.Info("listening", slog.String("addr", server.Addr))
+		serveErr <- server.ListenAndServe()
+	}()
+
+	var err error
+	select {
+	case err = <-serveErr:
+	case <-signalCtx.Done():
+		// A second signal falls back to the default behaviour and kills the
+		// process right away, also while waiting out the delay.
+		stopSignals()
+		drain()
+		slog.Info("shutting down, failing readiness", slog.Duration("delay", cfg.ShutdownDelay))
+		select {
+		case err = <-serveErr:
+		case <-time.After(cfg.ShutdownDelay):
+		}
+		slog.Info("draining in-flight requests")
+	}
+	stopSignals()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+
+	if shutdownErr := server.Shutdown(shutdownCtx); shutdownErr != nil {
+		slog.Error("http server shutdown", slog.Any("error", shutdownErr))
+	}
+
+	stopWorkers()
+	stopped := make(chan struct{})
+	go func() {
+		running.Wait()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-shutdownCtx.Done():
+		slog.Warn("background workers did not stop before the shutdown deadline")
+	}
+
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// newJWTKeys loads the token signing key plus any keys that tokens may still
+// be signed with during a rotation. Outside production a missing signing key
+// is replaced by a throwaway one; config validation already refuses that in
+// production.
+func newJWTKeys(cfg config.Config) (*jwtkey.Set, error) {
+	var signing jwtkey.Key
+
+	switch {
+	case cfg.JWT.SigningKeyFile != "":
+		key, err := jwtkey.LoadFile(cfg.JWT.SigningKeyFile, cfg.JWT.SigningKeyID)
+		if err != nil {
+			return nil, err
+		}
+		signing = key
+	case cfg.IsProduction():
+		return nil, fmt.Errorf("JWT_SIGNING_KEY_FILE is required in production")
+	default:
+		key, err := jwtkey.Generate()
+		if err != nil {
+			return nil, err
+		}
+		slog.Warn("JWT_SIGNING_KEY_FILE is not set, signing tokens with a temporary key", slog.String("kid", key.ID))
+		signing = key
+	}
+
+	var verification []jwtkey.Key
+	for _, path := range cfg.JWT.VerificationKeyFiles {
+		key, err := jwtkey.LoadFile(path, "")
+		if err != nil {
+			return nil, err
+		}
+		verification = append(verification, key)
+	}
+
+	return jwtkey.NewSet(signing, verification...)
+}
+
+// newRateLimits builds the middleware of each rate-limited route group. With
+// rate limiting disabled every one of them lets requests through.
+func newRateLimits(db *gorm.DB, cfg config.RateLimit) routes.RateLimits {
+	if !cfg.Enabled {
+		pass := middleware.RateLimit(nil)
+		return routes.RateLimits{Login: pass, TwoFactor: pass, Register: pass, OTP: pass, Transaction: pass, Email: pass}
+	}
+
+	var store ratelimit.Store = ratelimit.NewMemoryStore()
+	if cfg.Store == constants.ENUM_RATE_LIMIT_STORE_POSTGRES {
+		store = ratelimit.NewPostgresStore(db)
+	}
+
+	// The specs were validated with the rest of the config.
+	policy := func(name string, spec string) ratelimit.Policy {
+		p, _ := ratelimit.ParsePolicy(name, spec)
+		return p
+	}
+
+	return routes.RateLimits{
+		Login: middleware.RateLimit(store,
+			middleware.RateRule{Policy: policy("login_ip", cfg.LoginIP), Key: middleware.ByIP},
+			middleware.RateRule{Policy: policy("login_account", cfg.LoginAccount), Key: middleware.ByLoginIdentifier},
+		),
+		TwoFactor: middleware.RateLimit(store,
+			middleware.RateRule{Policy: policy("login_ip", cfg.LoginIP), Key: middleware.ByIP},
+			middleware.RateRule{Policy: policy("login_challenge", cfg.LoginAccount), Key: middleware.ByChallenge},
+		),
+		Register: middleware.RateLimit(store,
+			middleware.RateRule{Policy: policy("register_ip", cfg.RegisterIP), Key: middleware.ByIP},
+		),
+		OTP: middleware.RateLimit(store,
+			middleware.RateRule{Policy: policy("otp_ip", cfg.OTPIP), Key: middleware.ByIP},
+			middleware.RateRule{Policy: policy("otp_phone", cfg.OTPPhone), Key: middleware.ByPhone},
+		),
+		Transaction: middleware.RateLimit(store,
+			middleware.RateRule{Policy: policy("transaction_user", cfg.TransactionUser), Key: middleware.ByUser},
+		),
+		Email: middleware.RateLimit(store,
+			middleware.RateRule{Policy: policy("email_user", cfg.EmailUser), Key: middleware.ByUser},
+		),
+	}
+}
+
+func newRealtimeBackend(db *gorm.DB, cfg config.Realtime) realtime.PubSub {
+	if cfg.Backend == constants.ENUM_REALTIME_BACKEND_MEMORY {
+		return realtime.NewMemoryPubSub()
+	}
+	return realtime.NewPostgresPubSub(db, constants.ENUM_REALTIME_CHANNEL)
+}
+
+// newNotifier picks the SMS and email transports. Channels left on the log
+// sink write messages, codes included, to stdout or NOTIFIER_LOG_FILE so
+// local runs never need real credentials; config validation refuses that in
+// production. A transport that cannot be set up is an error rather than a
+// silent fallback to the log.
+func newNotifier(cfg config.Notifier) (notify.Notifier, error) {
+	var logSink notify.Notifier = notify.NewLogNotifier(os.Stdout)
+	if path := cfg.LogFile; path != "" {
+		fileSink, err := notify.NewFileNotifier(path)
+		if err != nil {
+			return nil, fmt.Errorf("opening notifier log file: %w", err)
+		}
+		logSink = fileSink
+	}
+
+	router := notify.Router{
+		notify.ChannelSMS:   logSink,
+		notify.ChannelEmail: logSink,
+	}
+
+	if cfg.SMS == constants.ENUM_NOTIFIER_HTTP {
+		router[notify.ChannelSMS] = notify.NewHTTPSMSNotifier(cfg.SMSGateway.URL, cfg.SMSGateway.Token, cfg.SMSGateway.SenderID)
+	}
+
+	if cfg.Email == constants.ENUM_NOTIFIER_SMTP {
+		smtpNotifier, err := notify.NewSMTPNotifier(notify.SMTPConfig{
+			Host:     cfg.SMTP.Host,
+			Port:     cfg.SMTP.Port,
+			Sender:   cfg.SMTP.SenderName,
+			Username: cfg.SMTP.AuthEmail,
+			Password: cfg.SMTP.Password,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("setting up smtp notifier: %w", err)
+		}
+		router[notify.ChannelEmail] = smtpNotifier
+	}
+
+	return router, nil
 }
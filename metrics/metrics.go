@@ -0,0 +1,78 @@
+// Package metrics records what the service does for monitoring. Services
+// depend on the Metrics interface only, so tests and CLI jobs can pass Nop
+// while the server records into Prometheus.
+package metrics
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Amierza/e-wallet/dto"
+	"github.com/Amierza/e-wallet/money"
+)
+
+type Metrics interface {
+	// ObserveRequest records one handled HTTP request. route is the route
+	// pattern, not the path, to keep the number of series bounded.
+	ObserveRequest(method string, route string, status int, elapsed time.Duration)
+	TransactionSucceeded(kind string, amount money.Amount)
+	TransactionFailed(kind string, err error)
+	LoginFailed(err error)
+}
+
+// Nop discards everything.
+type Nop struct{}
+
+func (Nop) ObserveRequest(method string, route string, status int, elapsed time.Duration) {}
+func (Nop) TransactionSucceeded(kind string, amount money.Amount)                         {}
+func (Nop) TransactionFailed(kind string, err error)                                      {}
+func (Nop) LoginFailed(err error)                                                         {}
+
+// ReasonOther labels failures that are not one of the known reasons, so an
+// unexpected error message never becomes a label value.
+const ReasonOther = "other"
+
+var reasons = []struct {
+	err    error
+	reason string
+}{
+	{dto.ErrInvalidAmount, "invalid_amount"},
+	{dto.ErrInsufficientBalance, "insufficient_balance"},
+	{dto.ErrWalletNotFound, "wallet_not_found"},
+	{dto.ErrTargetWalletNotFound, "target_wallet_not_found"},
+	{dto.ErrGetTargetUser, "target_user_not_found"},
+	{dto.ErrCannotTransferToOwnAccount, "own_account"},
+	{dto.ErrFXQuoteNotFound, "fx_quote_not_found"},
+	{dto.ErrFXQuoteExpired, "fx_quote_expired"},
+	{dto.ErrFXQuoteAlreadyUsed, "fx_quote_already_used"},
+	{dto.ErrFXQuoteMismatch, "fx_quote_mismatch"},
+	{dto.ErrGetUserFromToken, "unauthenticated"},
+	{dto.ErrGetUserFromUserID, "user_not_found"},
+	{dto.ErrUpdateWalletBalance, "update_wallet_failed"},
+	{dto.ErrCreateTopUp, "create_top_up_failed"},
+	{dto.ErrCreatePayment, "create_payment_failed"},
+	{dto.ErrCreateTransfer, "create_transfer_failed"},
+	{dto.ErrCreateFXRevenue, "create_fx_revenue_failed"},
+	{dto.ErrRecordEvent, "record_event_failed"},
+	{dto.ErrLoginIdentifierRequired, "identifier_required"},
+	{dto.ErrPhoneNumberNotFound, "phone_number_not_found"},
+	{dto.ErrEmailNotFound, "email_not_found"},
+	{dto.ErrPinNotMatch, "pin_not_match"},
+	{dto.ErrAccountFrozen, "account_frozen"},
+	{dto.ErrEmailNotVerified, "email_not_verified"},
+	{dto.ErrPhoneNotVerified, "phone_not_verified"},
+	{dto.ErrTwoFactorChallenge, "two_factor_challenge"},
+	{dto.ErrTwoFactorCodeRequired, "two_factor_code_required"},
+	{dto.ErrTwoFactorCodeInvalid, "two_factor_code_invalid"},
+	{dto.ErrTwoFactorLocked, "two_factor_locked"},
+}
+
+// Reason names the dto error behind err for a failure label.
+func Reason(err error) string {
+	for _, known := range reasons {
+		if errors.Is(err, known.err) {
+			return known.reason
+		}
+	}
+	return ReasonOther
+}
@@ -0,0 +1,113 @@
+package metrics
+
+import (
+	"database/sql"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Amierza/e-wallet/money"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "ewallet"
+
+// Prometheus records into its own registry rather than the global one, so
+// what /metrics exposes is exactly what is registered here.
+type Prometheus struct {
+	registry *prometheus.Registry
+
+	requests          *prometheus.CounterVec
+	requestDuration   *prometheus.HistogramVec
+	transactions      *prometheus.CounterVec
+	transactionVolume *prometheus.CounterVec
+	failures          *prometheus.CounterVec
+	loginFailures     *prometheus.CounterVec
+}
+
+// NewPrometheus also collects the Go runtime, the process and the connection
+// pool of db, labelled with dbName.
+func NewPrometheus(db *sql.DB, dbName string) *Prometheus {
+	p := &Prometheus{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "HTTP requests handled, by method, route and status.",
+		}, []string{"method", "route", "status"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Time to handle an HTTP request, by method, route and status.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		transactions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "transactions_total",
+			Help:      "Completed top-ups, payments and transfers, by type and currency.",
+		}, []string{"type", "currency"}),
+		transactionVolume: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "transaction_volume_total",
+			Help:      "Amount moved by completed transactions in major units, by type and currency.",
+		}, []string{"type", "currency"}),
+		failures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "transaction_failures_total",
+			Help:      "Rejected or failed transactions, by type and reason.",
+		}, []string{"type", "reason"}),
+		loginFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "login_failures_total",
+			Help:      "Failed logins, including second-factor steps, by reason.",
+		}, []string{"reason"}),
+	}
+
+	p.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		collectors.NewDBStatsCollector(db, dbName),
+		p.requests,
+		p.requestDuration,
+		p.transactions,
+		p.transactionVolume,
+		p.failures,
+		p.loginFailures,
+	)
+
+	return p
+}
+
+// Handler serves the registry in the Prometheus exposition format.
+func (p *Prometheus) Handler() http.Handler {
+	return promhttp.HandlerFor(p.registry, promhttp.HandlerOpts{Registry: p.registry})
+}
+
+func (p *Prometheus) ObserveRequest(method string, route string, status int, elapsed time.Duration) {
+	code := strconv.Itoa(status)
+	p.requests.WithLabelValues(method, route, code).Inc()
+	p.requestDuration.WithLabelValues(method, route, code).Observe(elapsed.Seconds())
+}
+
+func (p *Prometheus) TransactionSucceeded(kind string, amount money.Amount) {
+	p.transactions.WithLabelValues(kind, amount.Currency).Inc()
+	p.transactionVolume.WithLabelValues(kind, amount.Currency).Add(majorUnits(amount))
+}
+
+func (p *Prometheus) TransactionFailed(kind string, err error) {
+	p.failures.WithLabelValues(kind, Reason(err)).Inc()
+}
+
+func (p *Prometheus) LoginFailed(err error) {
+	p.loginFailures.WithLabelValues(Reason(err)).Inc()
+}
+
+// majorUnits turns minor units into a float for the volume counters; the
+// rounding is fine for a dashboard but never for accounting.
+func majorUnits(amount money.Amount) float64 {
+	exponent, _ := money.MinorUnit(amount.Currency)
+	return float64(amount.Minor) / math.Pow10(exponent)
+}
@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/Amierza/e-wallet/auth"
 	"github.com/Amierza/e-wallet/dto"
 	"github.com/Amierza/e-wallet/service"
 	"github.com/Amierza/e-wallet/utils"
@@ -13,40 +14,57 @@ import (
 
 func Authenticate(jwtService service.JWTService) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		authHeader := ctx.GetHeader("Authorization")
-		if authHeader == "" {
-			response := utils.BuildResponseFailed(dto.MESSAGE_FAILED_PROSES_REQUEST, dto.MESSAGE_FAILED_TOKEN_NOT_FOUND, nil)
+		principal, message := authenticate(ctx.Request.Context(), jwtService, ctx.GetHeader("Authorization"))
+		if message != "" {
+			response := utils.BuildResponseFailed(dto.MESSAGE_FAILED_PROSES_REQUEST, message, nil)
 			ctx.AbortWithStatusJSON(http.StatusUnauthorized, response)
 			return
 		}
-		if !strings.Contains(authHeader, "Bearer ") {
-			response := utils.BuildResponseFailed(dto.MESSAGE_FAILED_PROSES_REQUEST, dto.MESSAGE_FAILED_TOKEN_NOT_VALID, nil)
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, response)
-			return
-		}
-		authHeader = strings.Replace(authHeader, "Bearer ", "", -1)
-		token, err := jwtService.ValidateToken(authHeader)
-		if err != nil {
-			response := utils.BuildResponseFailed(dto.MESSAGE_FAILED_PROSES_REQUEST, dto.MESSAGE_FAILED_TOKEN_NOT_VALID, nil)
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, response)
-			return
-		}
-		if !token.Valid {
-			response := utils.BuildResponseFailed(dto.MESSAGE_FAILED_PROSES_REQUEST, dto.MESSAGE_FAILED_TOKEN_DENIED_ACCESS, nil)
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, response)
-			return
-		}
-		userID, err := jwtService.GetUserIDByToken(authHeader)
-		if err != nil {
-			response := utils.BuildResponseFailed(dto.MESSAGE_FAILED_PROSES_REQUEST, err.Error(), nil)
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, response)
-			return
-		}
-		// ctx.Set("Authorization", authHeader)
-		// ctx.Set("user_id", userID)
-		newCtx := context.WithValue(ctx.Request.Context(), "Authorization", authHeader)
-		newCtx = context.WithValue(newCtx, "user_id", userID)
-		ctx.Request = ctx.Request.WithContext(newCtx)
+		ctx.Request = ctx.Request.WithContext(auth.WithPrincipal(ctx.Request.Context(), principal))
 		ctx.Next()
 	}
 }
+
+// authenticate checks the bearer token and its session in a span of its own,
+// and returns why it was refused, if it was.
+func authenticate(ctx context.Context, jwtService service.JWTService, authHeader string) (auth.Principal, string) {
+	ctx, span := tracer.Start(ctx, "middleware.Authenticate")
+	defer span.End()
+
+	if authHeader == "" {
+		return auth.Principal{}, dto.MESSAGE_FAILED_TOKEN_NOT_FOUND
+	}
+	if !strings.Contains(authHeader, "Bearer ") {
+		return auth.Principal{}, dto.MESSAGE_FAILED_TOKEN_NOT_VALID
+	}
+	authHeader = strings.Replace(authHeader, "Bearer ", "", -1)
+	token, err := jwtService.ValidateToken(authHeader)
+	if err != nil {
+		return auth.Principal{}, dto.MESSAGE_FAILED_TOKEN_NOT_VALID
+	}
+	if !token.Valid {
+		return auth.Principal{}, dto.MESSAGE_FAILED_TOKEN_DENIED_ACCESS
+	}
+	principal, err := jwtService.GetPrincipalByToken(authHeader)
+	if err != nil {
+		return auth.Principal{}, err.Error()
+	}
+	if err := jwtService.ValidateSession(ctx, principal); err != nil {
+		return auth.Principal{}, err.Error()
+	}
+	return principal, ""
+}
+
+// AuthenticateStream also accepts the token as ?access_token=, since browsers
+// cannot set headers on WebSocket or EventSource requests.
+func AuthenticateStream(jwtService service.JWTService) gin.HandlerFunc {
+	authenticate := Authenticate(jwtService)
+	return func(ctx *gin.Context) {
+		if ctx.GetHeader("Authorization") == "" {
+			if token := ctx.Query("access_token"); token != "" {
+				ctx.Request.Header.Set("Authorization", "Bearer "+token)
+			}
+		}
+		authenticate(ctx)
+	}
+}
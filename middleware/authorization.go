@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/Amierza/e-wallet/auth"
+	"github.com/Amierza/e-wallet/dto"
+	"github.com/Amierza/e-wallet/service"
+	"github.com/Amierza/e-wallet/utils"
+	"github.com/gin-gonic/gin"
+)
+
+func OnlyAllow(roles ...string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		principal, ok := auth.FromContext(ctx.Request.Context())
+		if !ok || !principal.HasRole(roles...) {
+			response := utils.BuildResponseFailed(dto.MESSAGE_FAILED_PROSES_REQUEST, dto.MESSAGE_FAILED_TOKEN_NOT_ALLOWED, nil)
+			ctx.AbortWithStatusJSON(http.StatusForbidden, response)
+			return
+		}
+		ctx.Next()
+	}
+}
+
+// OnlyEstablishedDevice keeps devices signed in less than a day ago away from
+// sensitive operations, so a stolen PIN alone cannot move money right away.
+func OnlyEstablishedDevice(deviceService service.DeviceService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if err := deviceService.RequireEstablished(ctx.Request.Context()); err != nil {
+			response := utils.BuildResponseFailed(dto.MESSAGE_FAILED_PROSES_REQUEST, err.Error(), nil)
+			ctx.AbortWithStatusJSON(http.StatusForbidden, response)
+			return
+		}
+		ctx.Next()
+	}
+}
@@ -0,0 +1,95 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"github.com/Amierza/e-wallet/constants"
+	"github.com/Amierza/e-wallet/dto"
+	"github.com/Amierza/e-wallet/logging"
+	"github.com/Amierza/e-wallet/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// RequestID keeps the caller's X-Request-ID when it is sane, otherwise
+// generates one. The ID is echoed in the response and carried by the request
+// context, so every log line of the request includes it.
+func RequestID() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		requestID := ctx.GetHeader(constants.ENUM_REQUEST_ID_HEADER)
+		if !validRequestID(requestID) {
+			requestID = uuid.NewString()
+		}
+
+		ctx.Header(constants.ENUM_REQUEST_ID_HEADER, requestID)
+		ctx.Request = ctx.Request.WithContext(logging.WithRequestID(ctx.Request.Context(), requestID))
+		ctx.Next()
+	}
+}
+
+// validRequestID accepts printable ASCII without spaces, so a forged ID
+// cannot break a log line or smuggle a header.
+func validRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > constants.ENUM_REQUEST_ID_MAX_LENGTH {
+		return false
+	}
+	for i := 0; i < len(requestID); i++ {
+		if requestID[i] <= ' ' || requestID[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// AccessLog writes one line per request once it is handled. The user ID is
+// added by the logger from the context Authenticate left on the request.
+// Server errors are logged as errors and client errors as warnings.
+func AccessLog(logger *slog.Logger) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		start := time.Now()
+		ctx.Next()
+
+		status := ctx.Writer.Status()
+		level := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+
+		attrs := []slog.Attr{
+			slog.String("method", ctx.Request.Method),
+			slog.String("route", ctx.FullPath()),
+			slog.String("path", ctx.Request.URL.Path),
+			slog.String("query", logging.RedactQuery(ctx.Request.URL.RawQuery)),
+			slog.Int("status", status),
+			slog.Int("bytes", ctx.Writer.Size()),
+			slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.String("client_ip", ctx.ClientIP()),
+			slog.String("user_agent", ctx.Request.UserAgent()),
+		}
+		if errs := ctx.Errors.ByType(gin.ErrorTypeAny); len(errs) > 0 {
+			attrs = append(attrs, slog.String("error", errs.String()))
+		}
+
+		logger.LogAttrs(ctx.Request.Context(), level, "request", attrs...)
+	}
+}
+
+// Recovery turns a panic into a 500 in the usual response envelope and logs
+// it with its stack.
+func Recovery(logger *slog.Logger) gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(nil, func(ctx *gin.Context, err any) {
+		logger.ErrorContext(ctx.Request.Context(), "panic recovered",
+			slog.Any("panic", err),
+			slog.String("stack", string(debug.Stack())),
+		)
+
+		response := utils.BuildResponseFailed(dto.MESSAGE_FAILED_PROSES_REQUEST, http.StatusText(http.StatusInternalServerError), nil)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, response)
+	})
+}
@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"time"
+
+	"github.com/Amierza/e-wallet/dto"
+	"github.com/Amierza/e-wallet/metrics"
+	"github.com/Amierza/e-wallet/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// unmatchedRoute labels requests no route matched, so scanning random paths
+// cannot create a series per path.
+const unmatchedRoute = "unmatched"
+
+// Metrics counts and times every request by its route pattern and status.
+func Metrics(m metrics.Metrics) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		start := time.Now()
+		ctx.Next()
+
+		route := ctx.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		m.ObserveRequest(ctx.Request.Method, route, ctx.Writer.Status(), time.Since(start))
+	}
+}
+
+// ScrapeToken lets a request through only with the bearer token the metrics
+// scraper is configured with. An empty token leaves the endpoint open.
+func ScrapeToken(token string) gin.HandlerFunc {
+	expected := []byte("Bearer " + token)
+	return func(ctx *gin.Context) {
+		if token == "" {
+			ctx.Next()
+			return
+		}
+		if subtle.ConstantTimeCompare([]byte(ctx.GetHeader("Authorization")), expected) != 1 {
+			response := utils.BuildResponseFailed(dto.MESSAGE_FAILED_PROSES_REQUEST, dto.MESSAGE_FAILED_TOKEN_NOT_VALID, nil)
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, response)
+			return
+		}
+		ctx.Next()
+	}
+}
@@ -0,0 +1,188 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Amierza/e-wallet/auth"
+	"github.com/Amierza/e-wallet/dto"
+	"github.com/Amierza/e-wallet/ratelimit"
+	"github.com/Amierza/e-wallet/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// maxPeekBytes bounds how much of a JSON body is read to find the fields a
+// rule keys on; login and OTP bodies are far smaller.
+const maxPeekBytes = 64 << 10
+
+// RateKey names the bucket of a request, or returns "" when the request has
+// nothing to key on, which skips the rule.
+type RateKey func(ctx *gin.Context) string
+
+type RateRule struct {
+	Policy ratelimit.Policy
+	Key    RateKey
+}
+
+// RateLimit takes a token per rule and refuses the request with 429 once any
+// bucket is empty. The X-RateLimit-* headers describe the bucket closest to
+// empty. A failing store lets requests through rather than locking everyone
+// out.
+func RateLimit(store ratelimit.Store, rules ...RateRule) gin.HandlerFunc {
+	var active []RateRule
+	for _, rule := range rules {
+		if rule.Policy.Enabled() {
+			active = append(active, rule)
+		}
+	}
+	if len(active) == 0 {
+		return func(ctx *gin.Context) {
+			ctx.Next()
+		}
+	}
+
+	return func(ctx *gin.Context) {
+		now := time.Now()
+
+		var tightest *ratelimit.Result
+		for _, rule := range active {
+			key := rule.Key(ctx)
+			if key == "" {
+				continue
+			}
+
+			res, err := store.Take(ctx.Request.Context(), rule.Policy.Name+":"+key, rule.Policy, now)
+			if err != nil {
+				slog.WarnContext(ctx.Request.Context(), "rate limit store failed, allowing request",
+					slog.String("policy", rule.Policy.Name),
+					slog.Any("error", err),
+				)
+				continue
+			}
+
+			if !res.Allowed {
+				setRateLimitHeaders(ctx, res)
+				ctx.Header("Retry-After", strconv.Itoa(max(1, ceilSeconds(res.RetryAfter))))
+				response := utils.BuildResponseFailed(dto.MESSAGE_FAILED_TOO_MANY_REQUESTS, dto.ErrTooManyRequests.Error(), nil)
+				ctx.AbortWithStatusJSON(http.StatusTooManyRequests, response)
+				return
+			}
+			if tightest == nil || res.Remaining < tightest.Remaining {
+				tightest = &res
+			}
+		}
+
+		if tightest != nil {
+			setRateLimitHeaders(ctx, *tightest)
+		}
+		ctx.Next()
+	}
+}
+
+func setRateLimitHeaders(ctx *gin.Context, res ratelimit.Result) {
+	ctx.Header("X-RateLimit-Limit", strconv.Itoa(res.Limit))
+	ctx.Header("X-RateLimit-Remaining", strconv.Itoa(res.Remaining))
+	ctx.Header("X-RateLimit-Reset", strconv.Itoa(ceilSeconds(res.ResetAfter)))
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+// ByIP keys on the client IP, as resolved through the trusted proxies.
+func ByIP(ctx *gin.Context) string {
+	return "ip:" + ctx.ClientIP()
+}
+
+// ByUser keys on the authenticated user, so it must run after Authenticate.
+func ByUser(ctx *gin.Context) string {
+	userID, err := auth.UserID(ctx.Request.Context())
+	if err != nil {
+		return ""
+	}
+	return "user:" + userID
+}
+
+// ByPhone keys on the phone_number of a JSON or form body. The body is left
+// for the controller to bind as usual.
+func ByPhone(ctx *gin.Context) string {
+	return hashedKey("phone", bodyFields(ctx, "phone_number")["phone_number"])
+}
+
+// ByLoginIdentifier keys on the account a login names, by email or else by
+// phone number as the login itself does, so guessing a PIN through either
+// field draws from the same bucket.
+func ByLoginIdentifier(ctx *gin.Context) string {
+	fields := bodyFields(ctx, "email", "phone_number")
+	if email := strings.ToLower(strings.TrimSpace(fields["email"])); email != "" {
+		return hashedKey("login", "email:"+email)
+	}
+	if phone := strings.TrimSpace(fields["phone_number"]); phone != "" {
+		return hashedKey("login", "phone:"+phone)
+	}
+	return ""
+}
+
+// ByChallenge keys on the challenge_token that finishes a two-factor login,
+// limiting the codes tried against one challenge.
+func ByChallenge(ctx *gin.Context) string {
+	return hashedKey("challenge", bodyFields(ctx, "challenge_token")["challenge_token"])
+}
+
+// hashedKey hashes value so the store never holds phone numbers, emails or
+// tokens. An empty value has nothing to key on.
+func hashedKey(prefix string, value string) string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return ""
+	}
+
+	sum := sha256.Sum256([]byte(value))
+	return prefix + ":" + hex.EncodeToString(sum[:16])
+}
+
+func bodyFields(ctx *gin.Context, names ...string) map[string]string {
+	values := make(map[string]string, len(names))
+	if ctx.ContentType() != binding.MIMEJSON {
+		// Gin caches the parsed form, so binding it again later still works.
+		for _, name := range names {
+			values[name] = ctx.PostForm(name)
+		}
+		return values
+	}
+
+	peeked, err := io.ReadAll(io.LimitReader(ctx.Request.Body, maxPeekBytes))
+	ctx.Request.Body = readCloser{io.MultiReader(bytes.NewReader(peeked), ctx.Request.Body), ctx.Request.Body}
+	if err != nil {
+		return values
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(peeked, &fields); err != nil {
+		return values
+	}
+	for _, name := range names {
+		var value string
+		if err := json.Unmarshal(fields[name], &value); err == nil {
+			values[name] = value
+		}
+	}
+	return values
+}
+
+// readCloser puts the peeked bytes back in front of the body while still
+// closing the original.
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
@@ -1,19 +1,18 @@
 package migrations
 
 import (
-	"github.com/Amierza/e-wallet/entity"
+	"context"
+
 	"gorm.io/gorm"
 )
 
+// Migrate applies every pending migration embedded in the binary.
 func Migrate(db *gorm.DB) error {
-	if err := db.AutoMigrate(
-		&entity.User{},
-		&entity.TopUp{},
-		&entity.Payment{},
-		&entity.Transfer{},
-	); err != nil {
+	migrator, err := NewMigrator(db)
+	if err != nil {
 		return err
 	}
 
-	return nil
+	_, err = migrator.Up(context.Background(), 0)
+	return err
 }
@@ -0,0 +1,373 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SourceDir is where `migrate create` writes new files, relative to the
+// repository root. The binary only runs the files embedded at build time.
+const SourceDir = "migrations/sql"
+
+// schemaLockKey identifies the advisory lock held while migrating ("ewallet"
+// in ASCII), so that replicas starting together apply each migration once.
+const schemaLockKey int64 = 0x6577616c6c6574
+
+//go:embed sql/*.sql
+var embedded embed.FS
+
+var (
+	fileNamePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+	nameSeparator   = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+var (
+	ErrNoDownMigration = errors.New("migration has no down file")
+	ErrInvalidName     = errors.New("migration name must contain letters or digits")
+)
+
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+type MigrationStatus struct {
+	Version   int64
+	Name      string
+	AppliedAt *time.Time
+	// Missing marks a version recorded in schema_migrations whose files are
+	// not part of this build.
+	Missing bool
+}
+
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+}
+
+func NewMigrator(db *gorm.DB) (*Migrator, error) {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	migrations, err := load(embedded, "sql")
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{db: sqlDB, migrations: migrations}, nil
+}
+
+// Up applies pending migrations in version order, at most n of them when n is
+// positive, and returns the ones it applied.
+func (m *Migrator) Up(ctx context.Context, n int) ([]Migration, error) {
+	var applied []Migration
+
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		done, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range m.migrations {
+			if n > 0 && len(applied) == n {
+				break
+			}
+			if _, ok := done[migration.Version]; ok {
+				continue
+			}
+
+			if err := run(ctx, conn, migration.Up, "INSERT INTO schema_migrations (version, name, applied_at) VALUES ($1, $2, $3)",
+				migration.Version, migration.Name, time.Now()); err != nil {
+				return fmt.Errorf("migration %04d_%s: %w", migration.Version, migration.Name, err)
+			}
+			applied = append(applied, migration)
+		}
+
+		return nil
+	})
+
+	return applied, err
+}
+
+// Down rolls back the n most recently applied migrations, newest first.
+func (m *Migrator) Down(ctx context.Context, n int) ([]Migration, error) {
+	var reverted []Migration
+
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		done, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0 && len(reverted) < n; i-- {
+			migration := m.migrations[i]
+			if _, ok := done[migration.Version]; !ok {
+				continue
+			}
+			if migration.Down == "" {
+				return fmt.Errorf("migration %04d_%s: %w", migration.Version, migration.Name, ErrNoDownMigration)
+			}
+
+			if err := run(ctx, conn, migration.Down, "DELETE FROM schema_migrations WHERE version = $1",
+				migration.Version); err != nil {
+				return fmt.Errorf("migration %04d_%s: %w", migration.Version, migration.Name, err)
+			}
+			reverted = append(reverted, migration)
+		}
+
+		return nil
+	})
+
+	return reverted, err
+}
+
+// Status lists every known migration alongside when it was applied, followed
+// by applied versions this build does not know about. It only reads, so it
+// takes no lock and answers at once while a migration is running, showing
+// that one as not applied yet.
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	done, err := appliedVersions(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	var statuses []MigrationStatus
+	for _, migration := range m.migrations {
+		status := MigrationStatus{Version: migration.Version, Name: migration.Name}
+		if record, ok := done[migration.Version]; ok {
+			status.AppliedAt = &record.appliedAt
+			delete(done, migration.Version)
+		}
+		statuses = append(statuses, status)
+	}
+
+	for version, record := range done {
+		statuses = append(statuses, MigrationStatus{Version: version, Name: record.name, AppliedAt: &record.appliedAt, Missing: true})
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+
+	return statuses, nil
+}
+
+// Pending lists the migrations of this build that are not applied yet. It
+// takes no lock, so a readiness probe never waits behind a running migration.
+func (m *Migrator) Pending(ctx context.Context) ([]Migration, error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	done, err := appliedVersions(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []Migration
+	for _, migration := range m.migrations {
+		if _, ok := done[migration.Version]; !ok {
+			pending = append(pending, migration)
+		}
+	}
+
+	return pending, nil
+}
+
+// Create writes an empty up/down pair under dir numbered after the highest
+// version already there, and returns the paths it created.
+func Create(dir, name string) ([]string, error) {
+	name = strings.Trim(nameSeparator.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if name == "" {
+		return nil, ErrInvalidName
+	}
+
+	existing, err := load(os.DirFS(dir), ".")
+	if err != nil {
+		return nil, err
+	}
+
+	var version int64 = 1
+	if len(existing) > 0 {
+		version = existing[len(existing)-1].Version + 1
+	}
+
+	var paths []string
+	for _, direction := range []string{"up", "down"} {
+		path := filepath.Join(dir, fmt.Sprintf("%04d_%s.%s.sql", version, name, direction))
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if err != nil {
+			return paths, err
+		}
+		_, err = fmt.Fprintf(file, "-- %04d_%s (%s)\n", version, name, direction)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return paths, err
+		}
+		paths = append(paths, path)
+	}
+
+	return paths, nil
+}
+
+// withLock runs fn on a single connection holding the schema advisory lock;
+// session-level advisory locks belong to the connection that took them.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", schemaLockKey); err != nil {
+		return err
+	}
+	defer conn.ExecContext(context.WithoutCancel(ctx), "SELECT pg_advisory_unlock($1)", schemaLockKey)
+
+	if _, err := conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version bigint PRIMARY KEY,
+		name text NOT NULL,
+		applied_at timestamptz NOT NULL
+	)`); err != nil {
+		return err
+	}
+
+	return fn(conn)
+}
+
+// run executes a migration script and its bookkeeping statement in one
+// transaction, so a failing script leaves neither schema nor record behind.
+func run(ctx context.Context, conn *sql.Conn, script, record string, args ...any) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if strings.TrimSpace(script) != "" {
+		if _, err := tx.ExecContext(ctx, script); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.ExecContext(ctx, record, args...); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+type appliedRecord struct {
+	name      string
+	appliedAt time.Time
+}
+
+// appliedVersions reads the bookkeeping table. A database that was never
+// migrated has no such table yet, which reads as nothing applied.
+func appliedVersions(ctx context.Context, conn *sql.Conn) (map[int64]appliedRecord, error) {
+	var table sql.NullString
+	if err := conn.QueryRowContext(ctx, "SELECT to_regclass('schema_migrations')::text").Scan(&table); err != nil {
+		return nil, err
+	}
+	if !table.Valid {
+		return map[int64]appliedRecord{}, nil
+	}
+
+	rows, err := conn.QueryContext(ctx, "SELECT version, name, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	done := make(map[int64]appliedRecord)
+	for rows.Next() {
+		var version int64
+		var record appliedRecord
+		if err := rows.Scan(&version, &record.name, &record.appliedAt); err != nil {
+			return nil, err
+		}
+		done[version] = record
+	}
+
+	return done, rows.Err()
+}
+
+// load reads NNNN_name.up.sql / NNNN_name.down.sql pairs from dir, sorted by
+// version. Every version needs an up file; the down file is optional.
+func load(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]*Migration)
+	hasUp := make(map[int64]bool)
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".sql") {
+			continue
+		}
+
+		match := fileNamePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("migration %s: file name must look like 0001_name.up.sql", entry.Name())
+		}
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("migration %s: %w", entry.Name(), err)
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		}
+		if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration %04d: conflicting names %q and %q", version, migration.Name, match[2])
+		}
+
+		content, err := fs.ReadFile(fsys, filepath.ToSlash(filepath.Join(dir, entry.Name())))
+		if err != nil {
+			return nil, err
+		}
+		if match[3] == "up" {
+			migration.Up = string(content)
+			hasUp[version] = true
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if !hasUp[migration.Version] {
+			return nil, fmt.Errorf("migration %04d_%s: missing up file", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	return migrations, nil
+}
@@ -0,0 +1,74 @@
+package migrations
+
+import (
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoadEmbedded(t *testing.T) {
+	migrations, err := load(embedded, "sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migrations) == 0 {
+		t.Fatal("no migrations embedded")
+	}
+
+	for i, migration := range migrations {
+		if i > 0 && migration.Version <= migrations[i-1].Version {
+			t.Errorf("migration %04d_%s is out of order after %04d", migration.Version, migration.Name, migrations[i-1].Version)
+		}
+		if strings.TrimSpace(migration.Up) == "" || strings.TrimSpace(migration.Down) == "" {
+			t.Errorf("migration %04d_%s needs both an up and a down file", migration.Version, migration.Name)
+		}
+	}
+}
+
+func file(content string) *fstest.MapFile {
+	return &fstest.MapFile{Data: []byte(content)}
+}
+
+func TestLoadOrdersByVersion(t *testing.T) {
+	files := fstest.MapFS{
+		"sql/0010_later.up.sql":    file("up 10"),
+		"sql/0002_second.up.sql":   file("up 2"),
+		"sql/0002_second.down.sql": file("down 2"),
+		"sql/0001_first.up.sql":    file("up 1"),
+		"sql/README.md":            file("ignored"),
+		"sql/0003_nested/x.up.sql": file("ignored"),
+	}
+
+	got, err := load(files, "sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Migration{
+		{Version: 1, Name: "first", Up: "up 1"},
+		{Version: 2, Name: "second", Up: "up 2", Down: "down 2"},
+		{Version: 10, Name: "later", Up: "up 10"},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("load() = %+v\nwant %+v", got, want)
+	}
+}
+
+func TestLoadRejectsMalformedSets(t *testing.T) {
+	sets := map[string]fstest.MapFS{
+		"file name must look like": {"sql/1-first.up.sql": file("")},
+		"conflicting names": {
+			"sql/0001_first.up.sql":   file(""),
+			"sql/0001_other.down.sql": file(""),
+		},
+		"missing up file": {"sql/0001_first.down.sql": file("")},
+	}
+
+	for wantErr, files := range sets {
+		if _, err := load(files, "sql"); err == nil || !strings.Contains(err.Error(), wantErr) {
+			t.Errorf("load(%v) err = %v, want %q", slices.Sorted(maps.Keys(files)), err, wantErr)
+		}
+	}
+}
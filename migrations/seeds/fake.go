@@ -0,0 +1,337 @@
+package seeds
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/Amierza/e-wallet/constants"
+	"github.com/Amierza/e-wallet/entity"
+	"github.com/Amierza/e-wallet/money"
+	"github.com/Amierza/e-wallet/repository"
+	"github.com/Amierza/e-wallet/service"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// fakePhonePrefix marks generated users, so that their numbers never clash
+// with the fixtures and a later run can avoid the numbers already taken.
+const (
+	fakePhonePrefix = "0899"
+	fakeBatchSize   = 500
+)
+
+var (
+	fakeFirstNames = []string{"Adi", "Budi", "Citra", "Dewi", "Eka", "Fajar", "Gita", "Hadi", "Indah", "Joko", "Kartika", "Lestari", "Made", "Nur", "Putri", "Rizky", "Sari", "Teguh", "Wulan", "Yusuf"}
+	fakeLastNames  = []string{"Pratama", "Saputra", "Wijaya", "Lestari", "Kusuma", "Hidayat", "Nugroho", "Santoso", "Permana", "Siregar", "Harahap", "Wibowo", "Setiawan", "Rahmawati", "Utami"}
+	fakeStreets    = []string{"Jl. Sudirman", "Jl. Thamrin", "Jl. Gatot Subroto", "Jl. Diponegoro", "Jl. Ahmad Yani", "Jl. Merdeka", "Jl. Pemuda", "Jl. Malioboro", "Jl. Asia Afrika", "Jl. Pahlawan"}
+	fakeCities     = []string{"Jakarta", "Bandung", "Surabaya", "Yogyakarta", "Semarang", "Medan", "Denpasar", "Makassar"}
+	fakeMerchants  = []string{"Indomaret", "Alfamart", "PLN Token", "Pulsa Telkomsel", "GoFood", "Tokopedia", "Shopee", "BPJS Kesehatan", "Grab", "Kopi Kenangan", "Netflix", "PDAM"}
+	fakeTransfers  = []string{"Patungan makan siang", "Bayar utang", "Arisan bulanan", "Kado ulang tahun", "Uang kos", "Ongkos bensin", "Titip belanja"}
+)
+
+// fakeTopUps are the top-up amounts people pick, in major units.
+var fakeTopUps = map[string][]int64{
+	"IDR": {50000, 100000, 200000, 250000, 500000, 1000000},
+	"USD": {10, 25, 50, 100, 200},
+}
+
+// fakeSteps rounds payments and transfers to amounts people actually pay, in
+// major units.
+var fakeSteps = map[string]int64{
+	"IDR": 500,
+	"USD": 1,
+}
+
+type FakeOptions struct {
+	Users        int
+	Transactions int
+	// Days is how far back the generated history starts.
+	Days int
+	// Pin is the PIN of every generated user. It is hashed once and shared,
+	// since hashing thousands of PINs would take minutes.
+	Pin string
+	// Seed makes a run reproducible; runs with the same seed generate the
+	// same people and history.
+	Seed int64
+}
+
+type FakeResult struct {
+	Users     int       `json:"users"`
+	Wallets   int       `json:"wallets"`
+	TopUps    int       `json:"top_ups"`
+	Payments  int       `json:"payments"`
+	Transfers int       `json:"transfers"`
+	From      time.Time `json:"from"`
+	To        time.Time `json:"to"`
+	Seed      int64     `json:"seed"`
+}
+
+type fakeUser struct {
+	user    entity.User
+	wallets []*entity.Wallet
+}
+
+type fakeData struct {
+	users     []*fakeUser
+	topUps    []entity.TopUp
+	payments  []entity.Payment
+	transfers []entity.Transfer
+}
+
+// Fake generates users and a history of top-ups, payments and transfers
+// between them. The history is played in order in memory and every wallet is
+// stored with the balance it ends on, so the ledger of the new users is
+// consistent. Everything is written in one transaction.
+func Fake(ctx context.Context, db *gorm.DB, opts FakeOptions) (FakeResult, error) {
+	rng := rand.New(rand.NewSource(opts.Seed))
+	to := time.Now()
+	from := to.AddDate(0, 0, -opts.Days)
+
+	// A new user has no PIN history, so this only applies the PIN policy
+	// and hashes the PIN the way registration does.
+	var pinHolder entity.User
+	if err := service.SetPin(ctx, repository.NewUserRepository(db), &pinHolder, opts.Pin); err != nil {
+		return FakeResult{}, err
+	}
+	pinHash := pinHolder.Pin
+
+	var taken []string
+	if err := db.WithContext(ctx).Unscoped().Model(&entity.User{}).Where("phone_number LIKE ?", fakePhonePrefix+"%").Pluck("phone_number", &taken).Error; err != nil {
+		return FakeResult{}, err
+	}
+
+	data := fakeData{}
+	phones := map[string]bool{}
+	for _, phone := range taken {
+		phones[phone] = true
+	}
+	for i := 0; i < opts.Users; i++ {
+		phone := ""
+		for phone == "" || phones[phone] {
+			phone = fmt.Sprintf("%s%08d", fakePhonePrefix, rng.Intn(100000000))
+		}
+		phones[phone] = true
+		data.users = append(data.users, newFakeUser(rng, phone, pinHash, from))
+	}
+
+	data.play(rng, opts.Transactions, from, to)
+
+	var wallets []*entity.Wallet
+	users := make([]entity.User, 0, len(data.users))
+	pins := make([]entity.PinHistory, 0, len(data.users))
+	for _, u := range data.users {
+		users = append(users, u.user)
+		wallets = append(wallets, u.wallets...)
+		pins = append(pins, entity.PinHistory{ID: uuid.New(), UserID: u.user.ID, PinHash: pinHash})
+	}
+
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		tx = tx.Omit(clause.Associations)
+		if err := createInBatches(tx, users); err != nil {
+			return err
+		}
+		if err := createInBatches(tx, pins); err != nil {
+			return err
+		}
+		if err := createInBatches(tx, wallets); err != nil {
+			return err
+		}
+		if err := createInBatches(tx, data.topUps); err != nil {
+			return err
+		}
+		if err := createInBatches(tx, data.payments); err != nil {
+			return err
+		}
+		return createInBatches(tx, data.transfers)
+	})
+	if err != nil {
+		return FakeResult{}, err
+	}
+
+	return FakeResult{
+		Users:     len(users),
+		Wallets:   len(wallets),
+		TopUps:    len(data.topUps),
+		Payments:  len(data.payments),
+		Transfers: len(data.transfers),
+		From:      from,
+		To:        to,
+		Seed:      opts.Seed,
+	}, nil
+}
+
+// newFakeUser joins in the month before the history starts. Everyone has a
+// wallet in the default currency and one in four a USD wallet as well.
+func newFakeUser(rng *rand.Rand, phone string, pinHash string, from time.Time) *fakeUser {
+	joined := from.Add(-time.Duration(rng.Int63n(int64(30 * 24 * time.Hour))))
+	timestamp := entity.Timestamp{CreatedAt: joined, UpdatedAt: joined}
+
+	u := &fakeUser{user: entity.User{
+		ID:              uuid.New(),
+		FirstName:       pick(rng, fakeFirstNames),
+		LastName:        pick(rng, fakeLastNames),
+		PhoneNumber:     phone,
+		Address:         fmt.Sprintf("%s No. %d, %s", pick(rng, fakeStreets), rng.Intn(200)+1, pick(rng, fakeCities)),
+		Pin:             pinHash,
+		PhoneVerifiedAt: &joined,
+		Role:            constants.ENUM_ROLE_USER,
+		Timestamp:       timestamp,
+	}}
+
+	currencies := []string{constants.ENUM_CURRENCY_DEFAULT}
+	if rng.Intn(4) == 0 {
+		currencies = append(currencies, "USD")
+	}
+	for _, currency := range currencies {
+		minorUnit, _ := money.MinorUnit(currency)
+		u.wallets = append(u.wallets, &entity.Wallet{
+			ID:        uuid.New(),
+			UserID:    u.user.ID,
+			Currency:  currency,
+			MinorUnit: minorUnit,
+			Balance:   money.Zero(currency),
+			Timestamp: timestamp,
+		})
+	}
+
+	return u
+}
+
+// play generates n transactions at random times between from and to, in
+// time order. A wallet too empty to pay is topped up instead, so no balance
+// ever goes negative.
+func (d *fakeData) play(rng *rand.Rand, n int, from time.Time, to time.Time) {
+	if len(d.users) == 0 {
+		return
+	}
+
+	times := make([]time.Time, n)
+	for i := range times {
+		times[i] = from.Add(time.Duration(rng.Int63n(int64(to.Sub(from)) + 1)))
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+
+	for _, at := range times {
+		u := d.users[rng.Intn(len(d.users))]
+		wallet := u.wallets[rng.Intn(len(u.wallets))]
+		timestamp := entity.Timestamp{CreatedAt: at, UpdatedAt: at}
+
+		amount, canSpend := spend(rng, wallet.Balance)
+		roll := rng.Intn(100)
+		var target *fakeUser
+		if canSpend && roll >= 75 {
+			target = d.transferTarget(rng, u, wallet.Currency)
+		}
+
+		switch {
+		case !canSpend || roll < 35:
+			top := fakeTopUps[wallet.Currency]
+			amount = major(wallet.Currency, top[rng.Intn(len(top))])
+			before := wallet.Balance
+			wallet.Balance.Minor += amount.Minor
+			d.topUps = append(d.topUps, entity.TopUp{
+				ID:            uuid.New(),
+				UserID:        u.user.ID,
+				Currency:      wallet.Currency,
+				Amount:        amount,
+				BalanceBefore: before,
+				BalanceAfter:  wallet.Balance,
+				Timestamp:     timestamp,
+			})
+		case target == nil:
+			before := wallet.Balance
+			wallet.Balance.Minor -= amount.Minor
+			d.payments = append(d.payments, entity.Payment{
+				ID:            uuid.New(),
+				UserID:        u.user.ID,
+				Currency:      wallet.Currency,
+				Amount:        amount,
+				Remarks:       pick(rng, fakeMerchants),
+				BalanceBefore: before,
+				BalanceAfter:  wallet.Balance,
+				Timestamp:     timestamp,
+			})
+		default:
+			before := wallet.Balance
+			wallet.Balance.Minor -= amount.Minor
+			target.wallet(wallet.Currency).Balance.Minor += amount.Minor
+			d.transfers = append(d.transfers, entity.Transfer{
+				ID:             uuid.New(),
+				UserID:         u.user.ID,
+				TargetUserID:   target.user.ID,
+				Currency:       wallet.Currency,
+				Amount:         amount,
+				TargetCurrency: wallet.Currency,
+				TargetAmount:   amount,
+				Remarks:        pick(rng, fakeTransfers),
+				BalanceBefore:  before,
+				BalanceAfter:   wallet.Balance,
+				Timestamp:      timestamp,
+			})
+		}
+		wallet.UpdatedAt = at
+	}
+}
+
+// transferTarget picks another user holding a wallet in currency, or nil
+// after a few misses.
+func (d *fakeData) transferTarget(rng *rand.Rand, from *fakeUser, currency string) *fakeUser {
+	for i := 0; i < 10; i++ {
+		candidate := d.users[rng.Intn(len(d.users))]
+		if candidate != from && candidate.wallet(currency) != nil {
+			return candidate
+		}
+	}
+	return nil
+}
+
+func (u *fakeUser) wallet(currency string) *entity.Wallet {
+	for _, wallet := range u.wallets {
+		if wallet.Currency == currency {
+			return wallet
+		}
+	}
+	return nil
+}
+
+// spend picks an amount between 1% and 40% of balance, rounded down to the
+// currency's step. It reports false when the balance cannot cover one step.
+func spend(rng *rand.Rand, balance money.Amount) (money.Amount, bool) {
+	step := major(balance.Currency, fakeSteps[balance.Currency]).Minor
+	if step <= 0 || balance.Minor < step {
+		return money.Amount{}, false
+	}
+
+	share := balance.Minor * int64(rng.Intn(40)+1) / 100
+	minor := share / step * step
+	if minor < step {
+		minor = step
+	}
+
+	return money.Amount{Currency: balance.Currency, Minor: minor}, true
+}
+
+// major converts whole units of currency to an Amount.
+func major(currency string, units int64) money.Amount {
+	exp, _ := money.MinorUnit(currency)
+	for ; exp > 0; exp-- {
+		units *= 10
+	}
+	return money.Amount{Currency: currency, Minor: units}
+}
+
+// createInBatches skips empty slices, which gorm refuses to insert.
+func createInBatches[T any](tx *gorm.DB, rows []T) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	return tx.CreateInBatches(rows, fakeBatchSize).Error
+}
+
+func pick(rng *rand.Rand, values []string) string {
+	return values[rng.Intn(len(values))]
+}
@@ -0,0 +1,28 @@
+package seeds
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Amierza/e-wallet/dto"
+	"github.com/Amierza/e-wallet/repository"
+	"github.com/Amierza/e-wallet/service"
+	"gorm.io/gorm"
+)
+
+// seedFXRates upserts the rates, so a changed fixture updates the stored
+// rate instead of being skipped. Every rate in the file counts as created.
+func seedFXRates(ctx context.Context, db *gorm.DB, set FixtureSet, file string) (Result, error) {
+	var req dto.FXRateUpsertRequest
+	path, err := set.load(file, &req.Rates)
+	if err != nil || path == "" {
+		return Result{File: path}, err
+	}
+
+	fxService := service.NewFXService(repository.NewFXRepository(db))
+	if _, err := fxService.UpsertRates(ctx, req); err != nil {
+		return Result{File: path}, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return Result{File: path, Created: len(req.Rates)}, nil
+}
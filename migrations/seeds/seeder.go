@@ -0,0 +1,149 @@
+// Package seeds loads fixture data. Seeders run in registry order, so users
+// exist before their transactions, and each one is idempotent: fixtures that
+// are already in the database are skipped.
+package seeds
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// BaseDir holds the fixtures shared by every environment, relative to the
+// repository root. Each environment's own fixtures live in BaseDir/<env>.
+const BaseDir = "migrations/json"
+
+var (
+	ErrUnknownSeeder   = errors.New("unknown seeder")
+	ErrFixtureRef      = errors.New("fixture has no ref")
+	ErrFixtureUser     = errors.New("fixture refers to an unknown phone number")
+	ErrFixtureWallet   = errors.New("fixture refers to a wallet the user does not have")
+	ErrFixtureAmount   = errors.New("fixture amount must be a positive amount of its currency")
+	ErrFixtureOverdraw = errors.New("fixture would overdraw the wallet")
+)
+
+// fixtureNamespace derives the IDs of transaction fixtures from their ref, so
+// a fixture keeps its ID across runs and databases.
+var fixtureNamespace = uuid.NewSHA1(uuid.NameSpaceURL, []byte("https://github.com/Amierza/e-wallet/seeds"))
+
+// FixtureSet is the fixtures of one environment. A file in Dir/Env replaces
+// the file of the same name in Dir, so shared data such as the FX rates is
+// kept once while demo accounts only exist where they are wanted.
+type FixtureSet struct {
+	Dir string
+	Env string
+}
+
+// Seeder loads one kind of record from File.
+type Seeder struct {
+	Name string
+	File string
+	Run  func(ctx context.Context, db *gorm.DB, set FixtureSet, file string) (Result, error)
+}
+
+type Result struct {
+	Name string `json:"name"`
+	// File is the fixture file that was loaded, empty when the set has none.
+	File    string `json:"file"`
+	Created int    `json:"created"`
+	Skipped int    `json:"skipped"`
+}
+
+var registry = []Seeder{
+	{Name: "fx_rates", File: "fx_rates.json", Run: seedFXRates},
+	{Name: "users", File: "users.json", Run: seedUsers},
+	{Name: "top_ups", File: "top_ups.json", Run: seedTopUps},
+	{Name: "payments", File: "payments.json", Run: seedPayments},
+	{Name: "transfers", File: "transfers.json", Run: seedTransfers},
+}
+
+// Names lists the seeders in the order they run.
+func Names() []string {
+	var names []string
+	for _, seeder := range registry {
+		names = append(names, seeder.Name)
+	}
+	return names
+}
+
+// Run loads the fixture set with every seeder, or only the named ones. The
+// registry order is kept whatever the order of only.
+func Run(ctx context.Context, db *gorm.DB, set FixtureSet, only ...string) ([]Result, error) {
+	selected := map[string]bool{}
+	for _, name := range only {
+		found := false
+		for _, seeder := range registry {
+			found = found || seeder.Name == name
+		}
+		if !found {
+			return nil, fmt.Errorf("%w %q", ErrUnknownSeeder, name)
+		}
+		selected[name] = true
+	}
+
+	results := []Result{}
+	for _, seeder := range registry {
+		if len(selected) > 0 && !selected[seeder.Name] {
+			continue
+		}
+
+		result, err := seeder.Run(ctx, db, set, seeder.File)
+		result.Name = seeder.Name
+		results = append(results, result)
+		if err != nil {
+			return results, fmt.Errorf("seed %s: %w", seeder.Name, err)
+		}
+	}
+
+	return results, nil
+}
+
+// path returns the file to load for name, or "" when the set has none.
+func (s FixtureSet) path(name string) (string, error) {
+	candidates := []string{filepath.Join(s.Dir, name)}
+	if s.Env != "" {
+		candidates = append([]string{filepath.Join(s.Dir, s.Env, name)}, candidates...)
+	}
+
+	for _, candidate := range candidates {
+		_, err := os.Stat(candidate)
+		if err == nil {
+			return candidate, nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return "", err
+		}
+	}
+
+	return "", nil
+}
+
+// load decodes the named fixture file into v and returns its path, which is
+// empty when the set has no such file.
+func (s FixtureSet) load(name string, v any) (string, error) {
+	path, err := s.path(name)
+	if err != nil || path == "" {
+		return "", err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return path, err
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return path, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return path, nil
+}
+
+func fixtureID(kind string, ref string) uuid.UUID {
+	return uuid.NewSHA1(fixtureNamespace, []byte(kind+"/"+ref))
+}
@@ -0,0 +1,210 @@
+package seeds
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Amierza/e-wallet/constants"
+	"github.com/Amierza/e-wallet/dto"
+	"github.com/Amierza/e-wallet/entity"
+	"github.com/Amierza/e-wallet/money"
+	"github.com/Amierza/e-wallet/repository"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// transactionFixture is one top-up, payment or transfer. Amounts are in major
+// units. Ref names the fixture and fixes its ID, which is how a second run
+// knows it was already applied.
+type transactionFixture struct {
+	Ref               string `json:"ref"`
+	PhoneNumber       string `json:"phone_number"`
+	TargetPhoneNumber string `json:"target_phone_number"`
+	Currency          string `json:"currency"`
+	Amount            string `json:"amount"`
+	Remarks           string `json:"remarks"`
+}
+
+// applyFunc books one fixture inside tx and moves the wallet balances with
+// it. Seeding writes no outbox events, so loading fixtures never notifies
+// anyone.
+type applyFunc func(ctx context.Context, tx *gorm.DB, userRepo repository.UserRepository, id uuid.UUID, user entity.User, fixture transactionFixture, amount money.Amount) error
+
+func seedTopUps(ctx context.Context, db *gorm.DB, set FixtureSet, file string) (Result, error) {
+	return seedTransactions(ctx, db, set, file, constants.ENUM_TRANSACTION_TOPUP, &entity.TopUp{}, applyTopUp)
+}
+
+func seedPayments(ctx context.Context, db *gorm.DB, set FixtureSet, file string) (Result, error) {
+	return seedTransactions(ctx, db, set, file, constants.ENUM_TRANSACTION_PAYMENT, &entity.Payment{}, applyPayment)
+}
+
+func seedTransfers(ctx context.Context, db *gorm.DB, set FixtureSet, file string) (Result, error) {
+	return seedTransactions(ctx, db, set, file, constants.ENUM_TRANSACTION_TRANSFER, &entity.Transfer{}, applyTransfer)
+}
+
+// seedTransactions applies the fixtures in file order, each in its own
+// transaction, skipping those whose ID already exists.
+func seedTransactions(ctx context.Context, db *gorm.DB, set FixtureSet, file string, kind string, model any, apply applyFunc) (Result, error) {
+	var fixtures []transactionFixture
+	path, err := set.load(file, &fixtures)
+	if err != nil || path == "" {
+		return Result{File: path}, err
+	}
+
+	result := Result{File: path}
+	userRepo := repository.NewUserRepository(db)
+	transactor := repository.NewTransactor(db)
+
+	for _, fixture := range fixtures {
+		if fixture.Ref == "" {
+			return result, fmt.Errorf("%s: %w", path, ErrFixtureRef)
+		}
+
+		id := fixtureID(kind, fixture.Ref)
+
+		var count int64
+		if err := db.WithContext(ctx).Unscoped().Model(model).Where("id = ?", id).Count(&count).Error; err != nil {
+			return result, err
+		}
+		if count > 0 {
+			result.Skipped++
+			continue
+		}
+
+		currency := fixture.Currency
+		if currency == "" {
+			currency = constants.ENUM_CURRENCY_DEFAULT
+		}
+		amount, err := money.Parse(fixture.Amount, currency)
+		if err != nil || !amount.IsPositive() {
+			return result, fmt.Errorf("%s: %s: %w", path, fixture.Ref, ErrFixtureAmount)
+		}
+
+		user, err := fixtureUser(ctx, userRepo, fixture.PhoneNumber)
+		if err != nil {
+			return result, fmt.Errorf("%s: %s: %w", path, fixture.Ref, err)
+		}
+
+		err = transactor.WithTransaction(ctx, func(tx *gorm.DB) error {
+			return apply(ctx, tx, userRepo, id, user, fixture, amount)
+		})
+		if err != nil {
+			return result, fmt.Errorf("%s: %s: %w", path, fixture.Ref, err)
+		}
+
+		result.Created++
+	}
+
+	return result, nil
+}
+
+func applyTopUp(ctx context.Context, tx *gorm.DB, userRepo repository.UserRepository, id uuid.UUID, user entity.User, fixture transactionFixture, amount money.Amount) error {
+	before, after, err := moveBalance(ctx, tx, userRepo, user, amount, false)
+	if err != nil {
+		return err
+	}
+
+	return userRepo.CreateTopUp(ctx, tx, entity.TopUp{
+		ID:            id,
+		UserID:        user.ID,
+		Currency:      amount.Currency,
+		Amount:        amount,
+		BalanceBefore: before,
+		BalanceAfter:  after,
+	})
+}
+
+func applyPayment(ctx context.Context, tx *gorm.DB, userRepo repository.UserRepository, id uuid.UUID, user entity.User, fixture transactionFixture, amount money.Amount) error {
+	before, after, err := moveBalance(ctx, tx, userRepo, user, amount, true)
+	if err != nil {
+		return err
+	}
+
+	return userRepo.CreatePayment(ctx, tx, entity.Payment{
+		ID:            id,
+		UserID:        user.ID,
+		Currency:      amount.Currency,
+		Amount:        amount,
+		Remarks:       fixture.Remarks,
+		BalanceBefore: before,
+		BalanceAfter:  after,
+	})
+}
+
+// applyTransfer only moves money between wallets of the same currency;
+// converted transfers need a quote and are left to the API.
+func applyTransfer(ctx context.Context, tx *gorm.DB, userRepo repository.UserRepository, id uuid.UUID, user entity.User, fixture transactionFixture, amount money.Amount) error {
+	target, err := fixtureUser(ctx, userRepo, fixture.TargetPhoneNumber)
+	if err != nil {
+		return err
+	}
+	if target.ID == user.ID {
+		return dto.ErrCannotTransferToOwnAccount
+	}
+
+	before, after, err := moveBalance(ctx, tx, userRepo, user, amount, true)
+	if err != nil {
+		return err
+	}
+
+	if _, _, err := moveBalance(ctx, tx, userRepo, target, amount, false); err != nil {
+		return err
+	}
+
+	return userRepo.CreateTransfer(ctx, tx, entity.Transfer{
+		ID:             id,
+		UserID:         user.ID,
+		TargetUserID:   target.ID,
+		Currency:       amount.Currency,
+		Amount:         amount,
+		TargetCurrency: amount.Currency,
+		TargetAmount:   amount,
+		Remarks:        fixture.Remarks,
+		BalanceBefore:  before,
+		BalanceAfter:   after,
+	})
+}
+
+// moveBalance credits amount to the user's wallet, or debits it when debit
+// is set, and returns the balance before and after.
+func moveBalance(ctx context.Context, tx *gorm.DB, userRepo repository.UserRepository, user entity.User, amount money.Amount, debit bool) (money.Amount, money.Amount, error) {
+	wallet, found, err := userRepo.FindWallet(ctx, tx, user.ID.String(), amount.Currency)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return money.Amount{}, money.Amount{}, err
+	}
+	if !found {
+		return money.Amount{}, money.Amount{}, fmt.Errorf("%w: %s %s", ErrFixtureWallet, user.PhoneNumber, amount.Currency)
+	}
+
+	before := wallet.Balance
+	if debit {
+		wallet.Balance, err = wallet.Balance.Sub(amount)
+		if err == nil && wallet.Balance.IsNegative() {
+			err = ErrFixtureOverdraw
+		}
+	} else {
+		wallet.Balance, err = wallet.Balance.Add(amount)
+	}
+	if err != nil {
+		return money.Amount{}, money.Amount{}, err
+	}
+
+	if err := userRepo.UpdateWallet(ctx, tx, wallet); err != nil {
+		return money.Amount{}, money.Amount{}, err
+	}
+
+	return before, wallet.Balance, nil
+}
+
+func fixtureUser(ctx context.Context, userRepo repository.UserRepository, phoneNumber string) (entity.User, error) {
+	user, found, err := userRepo.CheckPhoneNumber(ctx, nil, phoneNumber)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return entity.User{}, err
+	}
+	if !found {
+		return entity.User{}, fmt.Errorf("%w: %q", ErrFixtureUser, phoneNumber)
+	}
+
+	return user, nil
+}
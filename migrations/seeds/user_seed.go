@@ -1,54 +1,112 @@
 package seeds
 
 import (
-	"encoding/json"
+	"context"
 	"errors"
-	"io"
-	"os"
+	"fmt"
+	"time"
 
+	"github.com/Amierza/e-wallet/constants"
 	"github.com/Amierza/e-wallet/entity"
+	"github.com/Amierza/e-wallet/money"
+	"github.com/Amierza/e-wallet/repository"
+	"github.com/Amierza/e-wallet/service"
 	"gorm.io/gorm"
 )
 
-func ListUserSeeder(db *gorm.DB) error {
-	// membuka file json beranam users.json
-	jsonFile, err := os.Open("./migrations/json/users.json")
-	if err != nil {
-		return err
-	}
-
-	// membaca file json sebagai byte
-	jsonData, _ := io.ReadAll(jsonFile)
+// userFixture reads the plain PIN that entity.User no longer accepts from JSON.
+type userFixture struct {
+	entity.User
+	Pin string `json:"pin"`
+}
 
-	// mengubah file json menjadi slice dari entity user menggunakan json.unmarshal
-	var listUser []entity.User
-	if err := json.Unmarshal(jsonData, &listUser); err != nil {
-		return nil
+// seedUsers creates the users that are missing by phone number. Wallets
+// listed for an existing user are still added when it lacks them; every user
+// gets at least a wallet in the default currency.
+func seedUsers(ctx context.Context, db *gorm.DB, set FixtureSet, file string) (Result, error) {
+	var fixtures []userFixture
+	path, err := set.load(file, &fixtures)
+	if err != nil || path == "" {
+		return Result{File: path}, err
 	}
 
-	// memastikan tabel user berada di database dan jika tidak ditemukan maka akan membuat tabel baru sesuai dengan entity user
-	hashTable := db.Migrator().HasTable(&entity.User{})
-	if !hashTable {
-		if err := db.Migrator().CreateTable(&entity.User{}); err != nil {
-			return err
+	result := Result{File: path}
+	userRepo := repository.NewUserRepository(db)
+	transactor := repository.NewTransactor(db)
+
+	for _, fixture := range fixtures {
+		data := fixture.User
+		if len(data.Wallets) == 0 {
+			data.Wallets = []entity.Wallet{{Currency: constants.ENUM_CURRENCY_DEFAULT}}
+		}
+		for i := range data.Wallets {
+			currency := money.NormalizeCurrency(data.Wallets[i].Currency)
+			minorUnit, ok := money.MinorUnit(currency)
+			if !ok {
+				return result, fmt.Errorf("%s: %s: %w", path, data.PhoneNumber, money.ErrUnsupportedCurrency)
+			}
+			data.Wallets[i] = entity.Wallet{Currency: currency, MinorUnit: minorUnit}
 		}
-	}
 
-	// memasukkan data user ke tabel user dan melakukan pengecekan terhadap phone number yang tidak boleh sama
-	for _, data := range listUser {
-		var user entity.User
-		err := db.Where(&entity.User{PhoneNumber: data.PhoneNumber}).First(&user).Error
+		user, found, err := userRepo.CheckPhoneNumber(ctx, nil, data.PhoneNumber)
 		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
-			return err
+			return result, err
 		}
 
-		isData := db.Find(&user, "phone_number = ?", data.PhoneNumber).RowsAffected
-		if isData == 0 {
-			if err := db.Create(&data).Error; err != nil {
+		if found {
+			err = transactor.WithTransaction(ctx, func(tx *gorm.DB) error {
+				for _, wallet := range data.Wallets {
+					_, exists, err := userRepo.FindWallet(ctx, tx, user.ID.String(), wallet.Currency)
+					if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+						return err
+					}
+					if exists {
+						continue
+					}
+
+					wallet.UserID = user.ID
+					if _, err := userRepo.CreateWallet(ctx, tx, wallet); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+			if err != nil {
+				return result, fmt.Errorf("%s: %s: %w", path, data.PhoneNumber, err)
+			}
+
+			result.Skipped++
+			continue
+		}
+
+		// Fixtures hold plain PINs; they go through the PIN policy and into
+		// the PIN history the way registration does.
+		if err := service.SetPin(ctx, userRepo, &data, fixture.Pin); err != nil {
+			return result, fmt.Errorf("%s: %s: %w", path, data.PhoneNumber, err)
+		}
+
+		if data.Role == "" {
+			data.Role = constants.ENUM_ROLE_USER
+		}
+
+		if data.PhoneVerifiedAt == nil {
+			now := time.Now()
+			data.PhoneVerifiedAt = &now
+		}
+
+		err = transactor.WithTransaction(ctx, func(tx *gorm.DB) error {
+			created, err := userRepo.RegisterUser(ctx, tx, data)
+			if err != nil {
 				return err
 			}
+			return service.RecordPin(ctx, userRepo, tx, created)
+		})
+		if err != nil {
+			return result, fmt.Errorf("%s: %s: %w", path, data.PhoneNumber, err)
 		}
+
+		result.Created++
 	}
 
-	return nil
+	return result, nil
 }
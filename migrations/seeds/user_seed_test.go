@@ -0,0 +1,56 @@
+package seeds
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Amierza/e-wallet/entity"
+	"github.com/Amierza/e-wallet/helpers"
+	"github.com/Amierza/e-wallet/repository"
+	"github.com/Amierza/e-wallet/service"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// newUserRepo stands in for a database in which no user has a PIN history
+// yet.
+type newUserRepo struct {
+	repository.UserRepository
+}
+
+func (newUserRepo) GetRecentPinHistory(ctx context.Context, tx *gorm.DB, userID string, limit int) ([]entity.PinHistory, error) {
+	return nil, nil
+}
+
+// Fixture PINs go through the same policy as a registration, so a PIN the
+// app would refuse makes the seeder fail rather than create a user who can
+// never change it.
+func TestFixturePinsPassThePinPolicy(t *testing.T) {
+	helpers.SetPinHasher(helpers.NewPinHasher(helpers.BcryptHasher{Cost: bcrypt.MinCost}, ""))
+
+	files, err := filepath.Glob(filepath.Join("..", "json", "*", "users.json"))
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no user fixtures found: %v", err)
+	}
+
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var fixtures []userFixture
+		if err := json.Unmarshal(data, &fixtures); err != nil {
+			t.Fatalf("%s: %v", file, err)
+		}
+
+		for _, fixture := range fixtures {
+			user := fixture.User
+			if err := service.SetPin(context.Background(), newUserRepo{}, &user, fixture.Pin); err != nil {
+				t.Errorf("%s: %s: PIN %q: %v", file, fixture.PhoneNumber, fixture.Pin, err)
+			}
+		}
+	}
+}
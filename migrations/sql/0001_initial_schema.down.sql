@@ -0,0 +1,16 @@
+DROP TABLE IF EXISTS devices;
+DROP TABLE IF EXISTS recovery_codes;
+DROP TABLE IF EXISTS two_factors;
+DROP TABLE IF EXISTS pin_histories;
+DROP TABLE IF EXISTS otps;
+DROP TABLE IF EXISTS outbox_events;
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhook_endpoints;
+DROP TABLE IF EXISTS fx_revenues;
+DROP TABLE IF EXISTS fx_quotes;
+DROP TABLE IF EXISTS fx_rates;
+DROP TABLE IF EXISTS transfers;
+DROP TABLE IF EXISTS payments;
+DROP TABLE IF EXISTS top_ups;
+DROP TABLE IF EXISTS wallets;
+DROP TABLE IF EXISTS users;
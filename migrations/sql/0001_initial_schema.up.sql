@@ -0,0 +1,347 @@
+-- Reproduces the schema previously built by GORM AutoMigrate. Every statement
+-- is idempotent so databases created by AutoMigrate upgrade in place.
+
+CREATE TABLE IF NOT EXISTS users (
+	id uuid NOT NULL,
+	first_name text,
+	last_name text,
+	phone_number text,
+	address text,
+	pin text,
+	phone_verified_at timestamptz,
+	email text,
+	email_verified_at timestamptz,
+	sessions_revoked_at timestamptz,
+	role text NOT NULL DEFAULT 'user',
+	created_at timestamptz,
+	updated_at timestamptz,
+	deleted_at timestamptz,
+	PRIMARY KEY (id)
+);
+
+-- Accounts created before phone verification existed must keep working.
+DO $$
+BEGIN
+	IF NOT EXISTS (
+		SELECT 1 FROM information_schema.columns
+		WHERE table_schema = current_schema() AND table_name = 'users' AND column_name = 'phone_verified_at'
+	) THEN
+		ALTER TABLE users ADD COLUMN phone_verified_at timestamptz;
+		UPDATE users SET phone_verified_at = created_at;
+	END IF;
+END $$;
+
+ALTER TABLE users
+	ADD COLUMN IF NOT EXISTS email text,
+	ADD COLUMN IF NOT EXISTS email_verified_at timestamptz,
+	ADD COLUMN IF NOT EXISTS sessions_revoked_at timestamptz,
+	ADD COLUMN IF NOT EXISTS role text NOT NULL DEFAULT 'user';
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email ON users (email);
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users (deleted_at);
+
+-- Registration only checks live accounts, so a soft-deleted user must not
+-- block the number from being registered again. Fails on existing duplicates.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_phone_number ON users (phone_number) WHERE deleted_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS wallets (
+	id uuid NOT NULL,
+	user_id uuid NOT NULL,
+	currency char(3) NOT NULL,
+	minor_unit bigint NOT NULL,
+	balance bigint NOT NULL DEFAULT 0,
+	created_at timestamptz,
+	updated_at timestamptz,
+	deleted_at timestamptz,
+	PRIMARY KEY (id),
+	CONSTRAINT fk_users_wallets FOREIGN KEY (user_id) REFERENCES users (id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_wallet_user_currency ON wallets (user_id, currency);
+CREATE INDEX IF NOT EXISTS idx_wallets_deleted_at ON wallets (deleted_at);
+
+CREATE TABLE IF NOT EXISTS top_ups (
+	id uuid NOT NULL,
+	user_id uuid NOT NULL,
+	currency char(3) NOT NULL DEFAULT 'IDR',
+	amount bigint,
+	balance_before bigint,
+	balance_after bigint,
+	created_at timestamptz,
+	updated_at timestamptz,
+	deleted_at timestamptz,
+	PRIMARY KEY (id),
+	CONSTRAINT fk_users_top_ups FOREIGN KEY (user_id) REFERENCES users (id)
+);
+
+ALTER TABLE top_ups ADD COLUMN IF NOT EXISTS currency char(3) NOT NULL DEFAULT 'IDR';
+
+CREATE INDEX IF NOT EXISTS idx_top_ups_deleted_at ON top_ups (deleted_at);
+
+CREATE TABLE IF NOT EXISTS payments (
+	id uuid NOT NULL,
+	user_id uuid NOT NULL,
+	currency char(3) NOT NULL DEFAULT 'IDR',
+	amount bigint,
+	remarks text,
+	balance_before bigint,
+	balance_after bigint,
+	created_at timestamptz,
+	updated_at timestamptz,
+	deleted_at timestamptz,
+	PRIMARY KEY (id),
+	CONSTRAINT fk_users_payments FOREIGN KEY (user_id) REFERENCES users (id)
+);
+
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS currency char(3) NOT NULL DEFAULT 'IDR';
+
+CREATE INDEX IF NOT EXISTS idx_payments_deleted_at ON payments (deleted_at);
+
+CREATE TABLE IF NOT EXISTS transfers (
+	id uuid NOT NULL,
+	user_id uuid NOT NULL,
+	target_user_id uuid NOT NULL,
+	currency char(3) NOT NULL DEFAULT 'IDR',
+	amount bigint,
+	target_currency char(3) NOT NULL DEFAULT 'IDR',
+	target_amount bigint,
+	quote_id uuid,
+	remarks text,
+	balance_before bigint,
+	balance_after bigint,
+	created_at timestamptz,
+	updated_at timestamptz,
+	deleted_at timestamptz,
+	PRIMARY KEY (id),
+	CONSTRAINT fk_users_transfers FOREIGN KEY (user_id) REFERENCES users (id),
+	CONSTRAINT fk_transfers_target_user FOREIGN KEY (target_user_id) REFERENCES users (id)
+);
+
+ALTER TABLE transfers
+	ADD COLUMN IF NOT EXISTS currency char(3) NOT NULL DEFAULT 'IDR',
+	ADD COLUMN IF NOT EXISTS target_currency char(3) NOT NULL DEFAULT 'IDR',
+	ADD COLUMN IF NOT EXISTS target_amount bigint,
+	ADD COLUMN IF NOT EXISTS quote_id uuid;
+
+CREATE INDEX IF NOT EXISTS idx_transfers_deleted_at ON transfers (deleted_at);
+
+-- The old users.balance column held whole units of the default currency. Move
+-- it into a default-currency wallet and rescale the history to minor units.
+DO $$
+DECLARE
+	scale bigint := 100;
+BEGIN
+	IF EXISTS (
+		SELECT 1 FROM information_schema.columns
+		WHERE table_schema = current_schema() AND table_name = 'users' AND column_name = 'balance'
+	) THEN
+		INSERT INTO wallets (id, user_id, currency, minor_unit, balance, created_at, updated_at)
+			SELECT gen_random_uuid(), u.id, 'IDR', 2, u.balance * scale, NOW(), NOW() FROM users u
+			WHERE NOT EXISTS (SELECT 1 FROM wallets w WHERE w.user_id = u.id AND w.currency = 'IDR');
+
+		UPDATE top_ups SET amount = amount * scale, balance_before = balance_before * scale, balance_after = balance_after * scale;
+		UPDATE payments SET amount = amount * scale, balance_before = balance_before * scale, balance_after = balance_after * scale;
+		UPDATE transfers SET amount = amount * scale, target_amount = amount * scale, balance_before = balance_before * scale, balance_after = balance_after * scale;
+
+		ALTER TABLE users DROP COLUMN balance;
+	END IF;
+END $$;
+
+ALTER TABLE wallets DROP CONSTRAINT IF EXISTS chk_wallets_balance;
+ALTER TABLE wallets ADD CONSTRAINT chk_wallets_balance CHECK (balance >= 0);
+ALTER TABLE top_ups DROP CONSTRAINT IF EXISTS chk_top_ups_balance;
+ALTER TABLE top_ups ADD CONSTRAINT chk_top_ups_balance CHECK (balance_before >= 0 AND balance_after >= 0);
+ALTER TABLE payments DROP CONSTRAINT IF EXISTS chk_payments_balance;
+ALTER TABLE payments ADD CONSTRAINT chk_payments_balance CHECK (balance_before >= 0 AND balance_after >= 0);
+ALTER TABLE transfers DROP CONSTRAINT IF EXISTS chk_transfers_balance;
+ALTER TABLE transfers ADD CONSTRAINT chk_transfers_balance CHECK (balance_before >= 0 AND balance_after >= 0);
+
+CREATE TABLE IF NOT EXISTS fx_rates (
+	id uuid NOT NULL,
+	base_currency char(3) NOT NULL,
+	quote_currency char(3) NOT NULL,
+	rate numeric(24,12) NOT NULL,
+	spread_bps bigint NOT NULL DEFAULT 0,
+	created_at timestamptz,
+	updated_at timestamptz,
+	deleted_at timestamptz,
+	PRIMARY KEY (id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_fx_rate_pair ON fx_rates (base_currency, quote_currency);
+CREATE INDEX IF NOT EXISTS idx_fx_rates_deleted_at ON fx_rates (deleted_at);
+
+CREATE TABLE IF NOT EXISTS fx_quotes (
+	id uuid NOT NULL,
+	user_id uuid NOT NULL,
+	from_currency char(3) NOT NULL,
+	to_currency char(3) NOT NULL,
+	mid_rate numeric(24,12) NOT NULL,
+	rate numeric(24,12) NOT NULL,
+	spread_bps bigint NOT NULL,
+	source_amount bigint NOT NULL,
+	target_amount bigint NOT NULL,
+	spread_amount bigint NOT NULL,
+	expires_at timestamptz NOT NULL,
+	used_at timestamptz,
+	created_at timestamptz,
+	updated_at timestamptz,
+	deleted_at timestamptz,
+	PRIMARY KEY (id),
+	CONSTRAINT fk_fx_quotes_user FOREIGN KEY (user_id) REFERENCES users (id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_fx_quotes_deleted_at ON fx_quotes (deleted_at);
+
+CREATE TABLE IF NOT EXISTS fx_revenues (
+	id uuid NOT NULL,
+	quote_id uuid NOT NULL,
+	transfer_id uuid NOT NULL,
+	currency char(3) NOT NULL,
+	amount bigint NOT NULL,
+	created_at timestamptz,
+	updated_at timestamptz,
+	deleted_at timestamptz,
+	PRIMARY KEY (id),
+	CONSTRAINT fk_fx_revenues_quote FOREIGN KEY (quote_id) REFERENCES fx_quotes (id),
+	CONSTRAINT fk_fx_revenues_transfer FOREIGN KEY (transfer_id) REFERENCES transfers (id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_fx_revenues_quote_id ON fx_revenues (quote_id);
+CREATE INDEX IF NOT EXISTS idx_fx_revenues_deleted_at ON fx_revenues (deleted_at);
+
+CREATE TABLE IF NOT EXISTS webhook_endpoints (
+	id uuid NOT NULL,
+	user_id uuid,
+	url text NOT NULL,
+	secret text NOT NULL,
+	events text NOT NULL,
+	active boolean NOT NULL DEFAULT true,
+	created_at timestamptz,
+	updated_at timestamptz,
+	deleted_at timestamptz,
+	PRIMARY KEY (id),
+	CONSTRAINT fk_webhook_endpoints_user FOREIGN KEY (user_id) REFERENCES users (id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_endpoints_user_id ON webhook_endpoints (user_id);
+CREATE INDEX IF NOT EXISTS idx_webhook_endpoints_deleted_at ON webhook_endpoints (deleted_at);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+	id uuid NOT NULL,
+	endpoint_id uuid NOT NULL,
+	event_id uuid NOT NULL,
+	event_type text NOT NULL,
+	payload jsonb NOT NULL,
+	status text NOT NULL,
+	attempts bigint NOT NULL DEFAULT 0,
+	next_attempt_at timestamptz NOT NULL,
+	last_status_code bigint,
+	last_error text,
+	delivered_at timestamptz,
+	created_at timestamptz,
+	updated_at timestamptz,
+	deleted_at timestamptz,
+	PRIMARY KEY (id),
+	CONSTRAINT fk_webhook_deliveries_endpoint FOREIGN KEY (endpoint_id) REFERENCES webhook_endpoints (id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_endpoint_id ON webhook_deliveries (endpoint_id);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_event_id ON webhook_deliveries (event_id);
+CREATE INDEX IF NOT EXISTS idx_webhook_delivery_due ON webhook_deliveries (status, next_attempt_at);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_deleted_at ON webhook_deliveries (deleted_at);
+
+CREATE TABLE IF NOT EXISTS outbox_events (
+	id uuid NOT NULL,
+	sequence bigserial NOT NULL,
+	aggregate_id uuid NOT NULL,
+	event_type text NOT NULL,
+	payload jsonb NOT NULL,
+	status text NOT NULL,
+	attempts bigint NOT NULL DEFAULT 0,
+	next_attempt_at timestamptz NOT NULL,
+	delivered_sinks text,
+	last_error text,
+	published_at timestamptz,
+	created_at timestamptz,
+	PRIMARY KEY (id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_outbox_events_sequence ON outbox_events (sequence);
+CREATE INDEX IF NOT EXISTS idx_outbox_aggregate_status ON outbox_events (aggregate_id, status);
+
+CREATE TABLE IF NOT EXISTS otps (
+	id uuid NOT NULL,
+	purpose text NOT NULL,
+	channel text NOT NULL,
+	target text NOT NULL,
+	code_hash text NOT NULL,
+	attempts bigint NOT NULL DEFAULT 0,
+	max_attempts bigint NOT NULL,
+	expires_at timestamptz NOT NULL,
+	consumed_at timestamptz,
+	created_at timestamptz,
+	updated_at timestamptz,
+	deleted_at timestamptz,
+	PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_otp_purpose_target ON otps (purpose, target);
+CREATE INDEX IF NOT EXISTS idx_otps_deleted_at ON otps (deleted_at);
+
+CREATE TABLE IF NOT EXISTS pin_histories (
+	id uuid NOT NULL,
+	user_id uuid NOT NULL,
+	pin_hash text NOT NULL,
+	created_at timestamptz,
+	PRIMARY KEY (id),
+	CONSTRAINT fk_pin_histories_user FOREIGN KEY (user_id) REFERENCES users (id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_pin_histories_user_id ON pin_histories (user_id);
+
+CREATE TABLE IF NOT EXISTS two_factors (
+	user_id uuid NOT NULL,
+	secret text NOT NULL,
+	confirmed_at timestamptz,
+	last_used_step bigint NOT NULL DEFAULT 0,
+	failed_attempts bigint NOT NULL DEFAULT 0,
+	locked_until timestamptz,
+	created_at timestamptz,
+	updated_at timestamptz,
+	PRIMARY KEY (user_id),
+	CONSTRAINT fk_two_factors_user FOREIGN KEY (user_id) REFERENCES users (id)
+);
+
+CREATE TABLE IF NOT EXISTS recovery_codes (
+	id uuid NOT NULL,
+	user_id uuid NOT NULL,
+	code_hash text NOT NULL,
+	used_at timestamptz,
+	created_at timestamptz,
+	PRIMARY KEY (id),
+	CONSTRAINT fk_recovery_codes_user FOREIGN KEY (user_id) REFERENCES users (id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_recovery_codes_user_id ON recovery_codes (user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_recovery_codes_code_hash ON recovery_codes (code_hash);
+
+CREATE TABLE IF NOT EXISTS devices (
+	id uuid NOT NULL,
+	user_id uuid NOT NULL,
+	name text,
+	platform text,
+	push_token text,
+	ip_address text,
+	user_agent text,
+	trusted_at timestamptz,
+	last_seen_at timestamptz,
+	revoked_at timestamptz,
+	created_at timestamptz,
+	updated_at timestamptz,
+	PRIMARY KEY (id),
+	CONSTRAINT fk_devices_user FOREIGN KEY (user_id) REFERENCES users (id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_devices_user_id ON devices (user_id);
+CREATE INDEX IF NOT EXISTS idx_devices_revoked_at ON devices (revoked_at);
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS rate_limit_buckets (
+	key text PRIMARY KEY,
+	tokens double precision NOT NULL,
+	updated_at timestamptz NOT NULL,
+	full_at timestamptz NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_rate_limit_buckets_full_at ON rate_limit_buckets (full_at);
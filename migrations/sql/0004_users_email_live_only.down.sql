@@ -0,0 +1,3 @@
+-- Fails if a live and a soft-deleted account share an email.
+DROP INDEX IF EXISTS idx_users_email;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email ON users (email);
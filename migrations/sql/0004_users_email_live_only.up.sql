@@ -0,0 +1,5 @@
+-- Like the phone number, an email only has to be unique among live accounts,
+-- so a soft-deleted user no longer blocks it. 0001 created the index over
+-- every row.
+DROP INDEX IF EXISTS idx_users_email;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email ON users (email) WHERE deleted_at IS NULL;
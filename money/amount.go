@@ -0,0 +1,207 @@
+package money
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrUnsupportedCurrency = errors.New("currency is not supported")
+	ErrCurrencyMismatch    = errors.New("currency mismatch")
+	ErrOverflow            = errors.New("amount overflow")
+	ErrInvalidAmount       = errors.New("invalid amount")
+	ErrTooPrecise          = errors.New("amount has more decimals than the currency allows")
+)
+
+// Amount is a monetary value held as an integer count of the currency's
+// minor unit, e.g. 150.25 USD is Amount{Currency: "USD", Minor: 15025}.
+type Amount struct {
+	Currency string
+	Minor    int64
+}
+
+func New(minor int64, currency string) (Amount, error) {
+	currency = NormalizeCurrency(currency)
+	if !IsSupported(currency) {
+		return Amount{}, ErrUnsupportedCurrency
+	}
+
+	return Amount{Currency: currency, Minor: minor}, nil
+}
+
+func Zero(currency string) Amount {
+	return Amount{Currency: NormalizeCurrency(currency)}
+}
+
+// Parse reads a decimal string in major units such as "1500", "-3.5" or
+// "0.07". More fractional digits than the currency allows is an error rather
+// than a silent rounding.
+func Parse(value string, currency string) (Amount, error) {
+	currency = NormalizeCurrency(currency)
+	exp, ok := MinorUnit(currency)
+	if !ok {
+		return Amount{}, ErrUnsupportedCurrency
+	}
+
+	value = strings.TrimSpace(value)
+	negative := false
+	if strings.HasPrefix(value, "-") || strings.HasPrefix(value, "+") {
+		negative = value[0] == '-'
+		value = value[1:]
+	}
+
+	whole, frac, hasDot := strings.Cut(value, ".")
+	if whole == "" && frac == "" || hasDot && frac == "" || !isDigits(whole) || !isDigits(frac) {
+		return Amount{}, ErrInvalidAmount
+	}
+
+	if len(frac) > exp {
+		return Amount{}, ErrTooPrecise
+	}
+
+	digits := strings.TrimLeft(whole+frac+strings.Repeat("0", exp-len(frac)), "0")
+	if digits == "" {
+		return Amount{Currency: currency}, nil
+	}
+
+	minor, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil {
+		return Amount{}, ErrOverflow
+	}
+
+	if negative {
+		minor = -minor
+	}
+
+	return Amount{Currency: currency, Minor: minor}, nil
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func (a Amount) IsZero() bool {
+	return a.Minor == 0
+}
+
+func (a Amount) IsPositive() bool {
+	return a.Minor > 0
+}
+
+func (a Amount) IsNegative() bool {
+	return a.Minor < 0
+}
+
+func (a Amount) SameCurrency(b Amount) bool {
+	return a.Currency == b.Currency
+}
+
+func (a Amount) WithCurrency(currency string) Amount {
+	return Amount{Currency: currency, Minor: a.Minor}
+}
+
+func (a Amount) Add(b Amount) (Amount, error) {
+	if !a.SameCurrency(b) {
+		return Amount{}, ErrCurrencyMismatch
+	}
+
+	if (b.Minor > 0 && a.Minor > math.MaxInt64-b.Minor) || (b.Minor < 0 && a.Minor < math.MinInt64-b.Minor) {
+		return Amount{}, ErrOverflow
+	}
+
+	return Amount{Currency: a.Currency, Minor: a.Minor + b.Minor}, nil
+}
+
+func (a Amount) Sub(b Amount) (Amount, error) {
+	if !a.SameCurrency(b) {
+		return Amount{}, ErrCurrencyMismatch
+	}
+
+	if (b.Minor < 0 && a.Minor > math.MaxInt64+b.Minor) || (b.Minor > 0 && a.Minor < math.MinInt64+b.Minor) {
+		return Amount{}, ErrOverflow
+	}
+
+	return Amount{Currency: a.Currency, Minor: a.Minor - b.Minor}, nil
+}
+
+// Cmp returns -1, 0 or 1 when a is less than, equal to or greater than b.
+func (a Amount) Cmp(b Amount) (int, error) {
+	if !a.SameCurrency(b) {
+		return 0, ErrCurrencyMismatch
+	}
+
+	switch {
+	case a.Minor < b.Minor:
+		return -1, nil
+	case a.Minor > b.Minor:
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// Convert prices a in another currency at rate (units of to per unit of
+// a.Currency, in major units), rounding down so the platform never pays out
+// more than it quoted.
+func (a Amount) Convert(to string, rate *big.Rat) (Amount, error) {
+	fromExp, okFrom := MinorUnit(a.Currency)
+	toExp, okTo := MinorUnit(to)
+	if !okFrom || !okTo {
+		return Amount{}, ErrUnsupportedCurrency
+	}
+
+	value := new(big.Rat).Mul(new(big.Rat).SetInt64(a.Minor), rate)
+
+	exp := toExp - fromExp
+	if exp < 0 {
+		exp = -exp
+	}
+	scale := new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exp)), nil))
+	if toExp >= fromExp {
+		value.Mul(value, scale)
+	} else {
+		value.Quo(value, scale)
+	}
+
+	minor := new(big.Int).Quo(value.Num(), value.Denom())
+	if !minor.IsInt64() {
+		return Amount{}, ErrOverflow
+	}
+
+	return Amount{Currency: to, Minor: minor.Int64()}, nil
+}
+
+// String formats the amount in major units without the currency code.
+func (a Amount) String() string {
+	exp, _ := MinorUnit(a.Currency)
+
+	sign := ""
+	digits := strconv.FormatUint(absUint(a.Minor), 10)
+	if a.Minor < 0 {
+		sign = "-"
+	}
+
+	if exp == 0 {
+		return sign + digits
+	}
+
+	if len(digits) <= exp {
+		digits = strings.Repeat("0", exp-len(digits)+1) + digits
+	}
+
+	return sign + digits[:len(digits)-exp] + "." + digits[len(digits)-exp:]
+}
+
+func absUint(v int64) uint64 {
+	if v < 0 {
+		return uint64(-(v + 1)) + 1
+	}
+	return uint64(v)
+}
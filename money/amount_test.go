@@ -0,0 +1,140 @@
+package money
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"testing"
+)
+
+func TestAddSub(t *testing.T) {
+	usd := func(minor int64) Amount { return Amount{Currency: "USD", Minor: minor} }
+
+	tests := []struct {
+		name    string
+		op      func(Amount, Amount) (Amount, error)
+		a, b    Amount
+		want    Amount
+		wantErr error
+	}{
+		{"add", Amount.Add, usd(150), usd(25), usd(175), nil},
+		{"add negative", Amount.Add, usd(150), usd(-200), usd(-50), nil},
+		{"add up to max", Amount.Add, usd(math.MaxInt64 - 1), usd(1), usd(math.MaxInt64), nil},
+		{"add overflow", Amount.Add, usd(math.MaxInt64), usd(1), Amount{}, ErrOverflow},
+		{"add underflow", Amount.Add, usd(math.MinInt64), usd(-1), Amount{}, ErrOverflow},
+		{"add currency mismatch", Amount.Add, usd(1), Amount{Currency: "IDR", Minor: 1}, Amount{}, ErrCurrencyMismatch},
+		{"sub", Amount.Sub, usd(150), usd(25), usd(125), nil},
+		{"sub below zero", Amount.Sub, usd(25), usd(150), usd(-125), nil},
+		{"sub down to min", Amount.Sub, usd(math.MinInt64 + 1), usd(1), usd(math.MinInt64), nil},
+		{"sub overflow", Amount.Sub, usd(math.MaxInt64), usd(-1), Amount{}, ErrOverflow},
+		{"sub underflow", Amount.Sub, usd(math.MinInt64), usd(1), Amount{}, ErrOverflow},
+		{"sub currency mismatch", Amount.Sub, usd(1), Amount{Currency: "IDR", Minor: 1}, Amount{}, ErrCurrencyMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.op(tt.a, tt.b)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		value    string
+		currency string
+		want     Amount
+		wantErr  error
+	}{
+		{"1500", "IDR", Amount{Currency: "IDR", Minor: 150000}, nil},
+		{"12.5", "usd", Amount{Currency: "USD", Minor: 1250}, nil},
+		{"0.07", "USD", Amount{Currency: "USD", Minor: 7}, nil},
+		{"-3.50", "USD", Amount{Currency: "USD", Minor: -350}, nil},
+		{"+3", "USD", Amount{Currency: "USD", Minor: 300}, nil},
+		{" 42 ", "JPY", Amount{Currency: "JPY", Minor: 42}, nil},
+		{"0", "USD", Amount{Currency: "USD"}, nil},
+		{"92233720368547758.07", "USD", Amount{Currency: "USD", Minor: math.MaxInt64}, nil},
+		{"1.005", "USD", Amount{}, ErrTooPrecise},
+		{"1.5", "JPY", Amount{}, ErrTooPrecise},
+		{"92233720368547758.08", "USD", Amount{}, ErrOverflow},
+		{"", "USD", Amount{}, ErrInvalidAmount},
+		{"1.", "USD", Amount{}, ErrInvalidAmount},
+		{"1e3", "USD", Amount{}, ErrInvalidAmount},
+		{"1,5", "USD", Amount{}, ErrInvalidAmount},
+		{"1", "XYZ", Amount{}, ErrUnsupportedCurrency},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value+" "+tt.currency, func(t *testing.T) {
+			got, err := Parse(tt.value, tt.currency)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Parse(%q, %q) err = %v, want %v", tt.value, tt.currency, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Parse(%q, %q) = %+v, want %+v", tt.value, tt.currency, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		name    string
+		from    Amount
+		to      string
+		rate    string
+		want    int64
+		wantErr error
+	}{
+		{"same exponent", Amount{Currency: "USD", Minor: 1000}, "IDR", "15500.25", 15500250, nil},
+		{"rounds down", Amount{Currency: "USD", Minor: 1}, "EUR", "0.929", 0, nil},
+		{"rounds down not to nearest", Amount{Currency: "USD", Minor: 199}, "EUR", "0.5", 99, nil},
+		{"negative rounds toward zero", Amount{Currency: "USD", Minor: -199}, "EUR", "0.5", -99, nil},
+		{"to fewer decimals", Amount{Currency: "USD", Minor: 1999}, "JPY", "150.5", 3008, nil},
+		{"to more decimals", Amount{Currency: "JPY", Minor: 1000}, "USD", "0.0066", 660, nil},
+		{"overflow", Amount{Currency: "USD", Minor: math.MaxInt64}, "IDR", "2", 0, ErrOverflow},
+		{"unsupported", Amount{Currency: "USD", Minor: 1}, "XYZ", "1", 0, ErrUnsupportedCurrency},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rate, ok := new(big.Rat).SetString(tt.rate)
+			if !ok {
+				t.Fatalf("bad rate %q", tt.rate)
+			}
+
+			got, err := tt.from.Convert(tt.to, rate)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && (got.Currency != tt.to || got.Minor != tt.want) {
+				t.Errorf("got %+v, want %d %s", got, tt.want, tt.to)
+			}
+		})
+	}
+}
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		amount Amount
+		want   string
+	}{
+		{Amount{Currency: "USD", Minor: 15025}, "150.25"},
+		{Amount{Currency: "USD", Minor: 7}, "0.07"},
+		{Amount{Currency: "USD", Minor: -350}, "-3.50"},
+		{Amount{Currency: "USD"}, "0.00"},
+		{Amount{Currency: "JPY", Minor: 42}, "42"},
+		{Amount{Currency: "USD", Minor: math.MinInt64}, "-92233720368547758.08"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.amount.String(); got != tt.want {
+			t.Errorf("%+v.String() = %q, want %q", tt.amount, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package money
+
+import (
+	"strings"
+
+	"github.com/Amierza/e-wallet/constants"
+)
+
+const DefaultCurrency = constants.ENUM_CURRENCY_DEFAULT
+
+// ISO 4217 codes supported by the wallet and their minor-unit exponent.
+var currencies = map[string]int{
+	"IDR": 2,
+	"USD": 2,
+	"EUR": 2,
+	"SGD": 2,
+	"MYR": 2,
+	"JPY": 0,
+}
+
+func NormalizeCurrency(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+func IsSupported(code string) bool {
+	_, ok := currencies[code]
+	return ok
+}
+
+func MinorUnit(code string) (int, bool) {
+	minor, ok := currencies[code]
+	return minor, ok
+}
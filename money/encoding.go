@@ -0,0 +1,104 @@
+package money
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+type amountJSON struct {
+	Value    json.Number `json:"value"`
+	Currency string      `json:"currency"`
+}
+
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Value    string `json:"value"`
+		Currency string `json:"currency"`
+	}{
+		Value:    a.String(),
+		Currency: a.Currency,
+	})
+}
+
+// UnmarshalJSON accepts {"value": "12.50", "currency": "USD"} as well as a
+// bare number or decimal string, which is read in the default currency.
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	var raw amountJSON
+	if len(data) > 0 && data[0] == '{' {
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		if err := decoder.Decode(&raw); err != nil {
+			return err
+		}
+	} else {
+		var value any
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		if err := decoder.Decode(&value); err != nil {
+			return err
+		}
+		switch v := value.(type) {
+		case json.Number:
+			raw.Value = v
+		case string:
+			raw.Value = json.Number(v)
+		default:
+			return ErrInvalidAmount
+		}
+	}
+
+	if raw.Currency == "" {
+		raw.Currency = DefaultCurrency
+	}
+
+	parsed, err := Parse(raw.Value.String(), raw.Currency)
+	if err != nil {
+		return err
+	}
+
+	*a = parsed
+	return nil
+}
+
+// Value stores only the minor units; the currency lives in its own column
+// and is attached back by the owning entity after a find.
+func (a Amount) Value() (driver.Value, error) {
+	return a.Minor, nil
+}
+
+func (a *Amount) Scan(value any) error {
+	switch v := value.(type) {
+	case nil:
+		a.Minor = 0
+	case int64:
+		a.Minor = v
+	case []byte:
+		return a.scanString(string(v))
+	case string:
+		return a.scanString(v)
+	default:
+		return fmt.Errorf("money: cannot scan %T into Amount", value)
+	}
+	return nil
+}
+
+func (a *Amount) scanString(v string) error {
+	minor, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return fmt.Errorf("money: cannot scan %q into Amount: %w", v, err)
+	}
+	a.Minor = minor
+	return nil
+}
+
+func (Amount) GormDataType() string {
+	return "bigint"
+}
@@ -0,0 +1,69 @@
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestAmountJSONRoundTrip(t *testing.T) {
+	type payload struct {
+		Balance Amount `json:"balance"`
+	}
+
+	for _, a := range []Amount{
+		{Currency: "USD", Minor: 1250},
+		{Currency: "JPY", Minor: -3},
+		{Currency: "IDR"},
+	} {
+		data, err := json.Marshal(payload{Balance: a})
+		if err != nil {
+			t.Fatalf("Marshal(%+v): %v", a, err)
+		}
+
+		var got payload
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Fatalf("Unmarshal(%s): %v", data, err)
+		}
+		if got.Balance != a {
+			t.Errorf("%+v came back as %+v through %s", a, got.Balance, data)
+		}
+	}
+
+	data, _ := json.Marshal(Amount{Currency: "USD", Minor: 1250})
+	if want := `{"value":"12.50","currency":"USD"}`; string(data) != want {
+		t.Errorf("Marshal() = %s, want %s", data, want)
+	}
+}
+
+// Requests may send an amount as an object, a bare number or a string; the
+// bare forms are read in the default currency.
+func TestUnmarshalJSONForms(t *testing.T) {
+	accepted := map[string]Amount{
+		`{"value": "12.50", "currency": "usd"}`: {Currency: "USD", Minor: 1250},
+		`{"value": 12.5, "currency": "USD"}`:    {Currency: "USD", Minor: 1250},
+		`{"value": "3"}`:                        {Currency: DefaultCurrency, Minor: 300},
+		`1500`:                                  {Currency: DefaultCurrency, Minor: 150000},
+		`"0.07"`:                                {Currency: DefaultCurrency, Minor: 7},
+		`null`:                                  {},
+	}
+	for data, want := range accepted {
+		var got Amount
+		if err := json.Unmarshal([]byte(data), &got); err != nil || got != want {
+			t.Errorf("Unmarshal(%s) = (%+v, %v), want %+v", data, got, err, want)
+		}
+	}
+
+	rejected := map[string]error{
+		`"1.005"`:                           ErrTooPrecise,
+		`1e3`:                               ErrInvalidAmount,
+		`true`:                              ErrInvalidAmount,
+		`{"value": "1", "currency": "XYZ"}`: ErrUnsupportedCurrency,
+	}
+	for data, want := range rejected {
+		var got Amount
+		if err := json.Unmarshal([]byte(data), &got); !errors.Is(err, want) {
+			t.Errorf("Unmarshal(%s) err = %v, want %v", data, err, want)
+		}
+	}
+}
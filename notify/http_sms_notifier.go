@@ -0,0 +1,62 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// HTTPSMSNotifier posts {"to": ..., "message": ...} to a generic SMS gateway.
+// Most providers either accept this shape directly or sit behind a small
+// adapter that does.
+type HTTPSMSNotifier struct {
+	url    string
+	token  string
+	sender string
+	client *http.Client
+}
+
+func NewHTTPSMSNotifier(url, token, sender string) *HTTPSMSNotifier {
+	return &HTTPSMSNotifier{
+		url:    url,
+		token:  token,
+		sender: sender,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (n *HTTPSMSNotifier) Send(ctx context.Context, msg Message) error {
+	body, err := json.Marshal(map[string]string{
+		"from":    n.sender,
+		"to":      msg.To,
+		"message": msg.Body,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if n.token != "" {
+		req.Header.Set("Authorization", "Bearer "+n.token)
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("sms gateway returned status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// LogNotifier writes messages to a file or stdout instead of delivering them,
+// which is what local development and CI want. Bodies are written as they
+// are, one-time codes and links included, so it must never run in
+// production.
+type LogNotifier struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func NewLogNotifier(w io.Writer) *LogNotifier {
+	if w == nil {
+		w = os.Stdout
+	}
+	return &LogNotifier{w: w}
+}
+
+// NewFileNotifier appends to path, creating it when needed.
+func NewFileNotifier(path string) (*LogNotifier, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	return NewLogNotifier(f), nil
+}
+
+func (n *LogNotifier) Send(_ context.Context, msg Message) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	_, err := fmt.Fprintf(n.w, "%s [%s] to=%s subject=%q body=%q\n",
+		time.Now().Format(time.RFC3339), msg.Channel, msg.To, msg.Subject, msg.Body)
+	return err
+}
@@ -0,0 +1,38 @@
+// Package notify delivers short out-of-band messages such as one-time
+// passwords. Each transport implements Notifier so services never depend on a
+// particular SMS gateway or mail server.
+package notify
+
+import (
+	"context"
+	"errors"
+)
+
+const (
+	ChannelSMS   = "sms"
+	ChannelEmail = "email"
+)
+
+var ErrNoNotifier = errors.New("no notifier configured for channel")
+
+type Message struct {
+	Channel string
+	To      string
+	Subject string
+	Body    string
+}
+
+type Notifier interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// Router sends each message through the notifier registered for its channel.
+type Router map[string]Notifier
+
+func (r Router) Send(ctx context.Context, msg Message) error {
+	n, ok := r[msg.Channel]
+	if !ok {
+		return ErrNoNotifier
+	}
+	return n.Send(ctx, msg)
+}
@@ -0,0 +1,53 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strings"
+)
+
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	Sender   string
+	Username string
+	Password string
+}
+
+type SMTPNotifier struct {
+	cfg  SMTPConfig
+	from string
+}
+
+func NewSMTPNotifier(cfg SMTPConfig) (*SMTPNotifier, error) {
+	sender, err := mail.ParseAddress(cfg.Sender)
+	if err != nil {
+		return nil, fmt.Errorf("invalid smtp sender: %w", err)
+	}
+	return &SMTPNotifier{cfg: cfg, from: sender.Address}, nil
+}
+
+func (n *SMTPNotifier) Send(ctx context.Context, msg Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", n.cfg.Sender)
+	fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", msg.Subject)
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=\"utf-8\"\r\n\r\n")
+	b.WriteString(msg.Body)
+
+	var auth smtp.Auth
+	if n.cfg.Username != "" {
+		auth = smtp.PlainAuth("", n.cfg.Username, n.cfg.Password, n.cfg.Host)
+	}
+
+	addr := net.JoinHostPort(n.cfg.Host, n.cfg.Port)
+	return smtp.SendMail(addr, auth, n.from, []string{msg.To}, []byte(b.String()))
+}
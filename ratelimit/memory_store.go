@@ -0,0 +1,52 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often buckets that have refilled are dropped; a full
+// bucket is the same as no bucket.
+const sweepInterval = time.Minute
+
+// MemoryStore keeps buckets in this process only, so with several instances
+// each one allows the full limit.
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]memoryBucket
+	lastSweep time.Time
+}
+
+type memoryBucket struct {
+	Bucket
+	fullAt time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: make(map[string]memoryBucket)}
+}
+
+func (s *MemoryStore) Take(ctx context.Context, key string, policy Policy, now time.Time) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now.Sub(s.lastSweep) >= sweepInterval {
+		for k, b := range s.buckets {
+			if !now.Before(b.fullAt) {
+				delete(s.buckets, k)
+			}
+		}
+		s.lastSweep = now
+	}
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b.Bucket = policy.NewBucket(now)
+	}
+
+	bucket, res := policy.Take(b.Bucket, now)
+	s.buckets[key] = memoryBucket{Bucket: bucket, fullAt: now.Add(res.ResetAfter)}
+
+	return res, nil
+}
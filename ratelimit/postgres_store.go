@@ -0,0 +1,62 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PostgresStore keeps buckets in the application database, so every instance
+// sharing it enforces one limit together. Each take locks the key's row for
+// the length of a short transaction.
+type PostgresStore struct {
+	db *gorm.DB
+
+	mu        sync.Mutex
+	lastSweep time.Time
+}
+
+func NewPostgresStore(db *gorm.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+func (s *PostgresStore) Take(ctx context.Context, key string, policy Policy, now time.Time) (Result, error) {
+	s.sweep(ctx, now)
+
+	var res Result
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// The no-op update on conflict locks an existing row and returns it,
+		// so a new and a known key take the same path.
+		fresh := policy.NewBucket(now)
+		var bucket Bucket
+		err := tx.Raw(`INSERT INTO rate_limit_buckets (key, tokens, updated_at, full_at) VALUES (?, ?, ?, ?)
+			ON CONFLICT (key) DO UPDATE SET key = EXCLUDED.key
+			RETURNING tokens, updated_at`, key, fresh.Tokens, fresh.UpdatedAt, now).Scan(&bucket).Error
+		if err != nil {
+			return err
+		}
+
+		bucket, res = policy.Take(bucket, now)
+		return tx.Exec("UPDATE rate_limit_buckets SET tokens = ?, updated_at = ?, full_at = ? WHERE key = ?",
+			bucket.Tokens, bucket.UpdatedAt, now.Add(res.ResetAfter), key).Error
+	})
+
+	return res, err
+}
+
+// sweep deletes buckets that have refilled, at most once per sweepInterval
+// per instance. A failed sweep is retried on the next interval.
+func (s *PostgresStore) sweep(ctx context.Context, now time.Time) {
+	s.mu.Lock()
+	due := now.Sub(s.lastSweep) >= sweepInterval
+	if due {
+		s.lastSweep = now
+	}
+	s.mu.Unlock()
+
+	if due {
+		s.db.WithContext(ctx).Exec("DELETE FROM rate_limit_buckets WHERE full_at <= ?", now)
+	}
+}
@@ -0,0 +1,104 @@
+// Package ratelimit implements token-bucket rate limiting. A Policy says how
+// many requests a key may make per period; a Store keeps the buckets, in
+// memory for a single instance or in a shared store when several instances
+// must agree.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Policy lets a key make Limit requests in a burst, refilled evenly over
+// Period. The zero Policy is disabled.
+type Policy struct {
+	Name   string
+	Limit  int
+	Period time.Duration
+}
+
+// ParsePolicy reads a spec such as 5/1m. An empty spec or "off" disables the
+// policy.
+func ParsePolicy(name string, spec string) (Policy, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" || spec == "off" {
+		return Policy{Name: name}, nil
+	}
+
+	limit, period, ok := strings.Cut(spec, "/")
+	if !ok {
+		return Policy{}, fmt.Errorf("%q is not a limit/period such as 5/1m", spec)
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(limit))
+	if err != nil || n < 1 {
+		return Policy{}, fmt.Errorf("%q: limit must be a positive integer", spec)
+	}
+	d, err := time.ParseDuration(strings.TrimSpace(period))
+	if err != nil || d <= 0 {
+		return Policy{}, fmt.Errorf("%q: period must be a positive duration", spec)
+	}
+
+	return Policy{Name: name, Limit: n, Period: d}, nil
+}
+
+func (p Policy) Enabled() bool {
+	return p.Limit > 0
+}
+
+// Bucket is the state a Store keeps per key.
+type Bucket struct {
+	Tokens    float64
+	UpdatedAt time.Time
+}
+
+// Result is the outcome of taking a token. RetryAfter is set when the request
+// is refused; ResetAfter is how long until the bucket is full again.
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	RetryAfter time.Duration
+	ResetAfter time.Duration
+}
+
+// NewBucket is the bucket of a key seen for the first time: full.
+func (p Policy) NewBucket(now time.Time) Bucket {
+	return Bucket{Tokens: float64(p.Limit), UpdatedAt: now}
+}
+
+// Take refills b for the time since it was last used and takes one token if
+// there is one. Stores call it under their own lock or transaction, so every
+// store counts the same way.
+func (p Policy) Take(b Bucket, now time.Time) (Bucket, Result) {
+	perSecond := float64(p.Limit) / p.Period.Seconds()
+	if elapsed := now.Sub(b.UpdatedAt).Seconds(); elapsed > 0 {
+		b.Tokens = math.Min(float64(p.Limit), b.Tokens+elapsed*perSecond)
+	}
+	b.UpdatedAt = now
+
+	res := Result{Limit: p.Limit}
+	if b.Tokens >= 1 {
+		b.Tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = seconds((1 - b.Tokens) / perSecond)
+	}
+	res.Remaining = int(b.Tokens)
+	res.ResetAfter = seconds((float64(p.Limit) - b.Tokens) / perSecond)
+
+	return b, res
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
+
+// Store takes a token from the bucket of key under policy. Keys are already
+// prefixed with the policy name.
+type Store interface {
+	Take(ctx context.Context, key string, policy Policy, now time.Time) (Result, error)
+}
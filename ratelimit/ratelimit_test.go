@@ -0,0 +1,115 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestParsePolicy(t *testing.T) {
+	valid := map[string]Policy{
+		"5/1m":       {Name: "login", Limit: 5, Period: time.Minute},
+		" 30 / 15m ": {Name: "login", Limit: 30, Period: 15 * time.Minute},
+		// Both turn the policy off.
+		"":    {Name: "login"},
+		"off": {Name: "login"},
+	}
+	for spec, want := range valid {
+		got, err := ParsePolicy("login", spec)
+		if err != nil || got != want {
+			t.Errorf("ParsePolicy(%q) = (%+v, %v), want %+v", spec, got, err, want)
+		}
+	}
+
+	for _, spec := range []string{"5", "0/1m", "x/1m", "5/0s", "5/soon"} {
+		if _, err := ParsePolicy("login", spec); err == nil {
+			t.Errorf("ParsePolicy(%q) accepted an invalid spec", spec)
+		}
+	}
+}
+
+func TestTake(t *testing.T) {
+	// 4 requests per minute: one token every 15s.
+	policy := Policy{Name: "test", Limit: 4, Period: time.Minute}
+	start := time.Unix(1_700_000_000, 0)
+
+	tests := []struct {
+		name    string
+		bucket  Bucket
+		at      time.Duration
+		want    Result
+		wantLen float64
+	}{
+		{"full bucket", policy.NewBucket(start), 0,
+			Result{Allowed: true, Limit: 4, Remaining: 3, ResetAfter: 15 * time.Second}, 3},
+		{"last token", Bucket{Tokens: 1, UpdatedAt: start}, 0,
+			Result{Allowed: true, Limit: 4, Remaining: 0, ResetAfter: time.Minute}, 0},
+		{"empty", Bucket{Tokens: 0, UpdatedAt: start}, 0,
+			Result{Limit: 4, RetryAfter: 15 * time.Second, ResetAfter: time.Minute}, 0},
+		{"partly refilled", Bucket{Tokens: 0, UpdatedAt: start}, 5 * time.Second,
+			Result{Limit: 4, RetryAfter: 10 * time.Second, ResetAfter: 55 * time.Second}, 1.0 / 3},
+		{"refilled one token", Bucket{Tokens: 0, UpdatedAt: start}, 15 * time.Second,
+			Result{Allowed: true, Limit: 4, Remaining: 0, ResetAfter: time.Minute}, 0},
+		{"refill caps at limit", Bucket{Tokens: 0, UpdatedAt: start}, time.Hour,
+			Result{Allowed: true, Limit: 4, Remaining: 3, ResetAfter: 15 * time.Second}, 3},
+		{"clock going back does not drain", Bucket{Tokens: 2, UpdatedAt: start}, -time.Minute,
+			Result{Allowed: true, Limit: 4, Remaining: 1, ResetAfter: 45 * time.Second}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := start.Add(tt.at)
+			bucket, got := policy.Take(tt.bucket, now)
+			if !approx(got.RetryAfter, tt.want.RetryAfter) || !approx(got.ResetAfter, tt.want.ResetAfter) {
+				t.Errorf("Take() = %+v, want %+v", got, tt.want)
+			}
+			got.RetryAfter, got.ResetAfter = tt.want.RetryAfter, tt.want.ResetAfter
+			if got != tt.want {
+				t.Errorf("Take() = %+v, want %+v", got, tt.want)
+			}
+			if diff := bucket.Tokens - tt.wantLen; diff > 1e-9 || diff < -1e-9 || !bucket.UpdatedAt.Equal(now) {
+				t.Errorf("bucket = %+v, want %v tokens at %s", bucket, tt.wantLen, now)
+			}
+		})
+	}
+}
+
+func approx(got time.Duration, want time.Duration) bool {
+	diff := got - want
+	return diff < time.Millisecond && diff > -time.Millisecond
+}
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	policy := Policy{Name: "test", Limit: 2, Period: 10 * time.Second}
+	now := time.Unix(1_700_000_000, 0)
+
+	steps := []struct {
+		key   string
+		at    time.Duration
+		allow bool
+	}{
+		{"a", 0, true},
+		{"a", 0, true},
+		{"a", 0, false},
+		{"b", 0, true},
+		{"a", 4 * time.Second, false},
+		{"a", 5 * time.Second, true},
+		{"a", 5 * time.Second, false},
+		// Past the sweep interval the bucket is dropped and starts full.
+		{"a", 2 * time.Minute, true},
+		{"a", 2 * time.Minute, true},
+		{"a", 2 * time.Minute, false},
+	}
+
+	for i, step := range steps {
+		res, err := store.Take(ctx, step.key, policy, now.Add(step.at))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Allowed != step.allow {
+			t.Errorf("step %d: Take(%s at %s) allowed = %v, want %v", i, step.key, step.at, res.Allowed, step.allow)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+// Package realtime fans notifications out to every connected device of a
+// user, across server instances, through a pluggable PubSub backend.
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+const (
+	MessageTypeBalance     = "balance.updated"
+	MessageTypeTransaction = "transaction.created"
+
+	// The last message of a stream whose token expired or was revoked; the
+	// subscription is closed right after it.
+	MessageTypeSessionExpired = "session.expired"
+	MessageTypeSessionRevoked = "session.revoked"
+
+	clientBuffer = 32
+)
+
+// Message goes to every stream of UserID, or only to those of DeviceID when
+// it is set.
+type Message struct {
+	Type     string          `json:"type"`
+	UserID   string          `json:"user_id"`
+	DeviceID string          `json:"device_id,omitempty"`
+	Data     json.RawMessage `json:"data,omitempty"`
+	SentAt   time.Time       `json:"sent_at"`
+}
+
+// Final reports whether m ends the streams it is delivered to.
+func (m Message) Final() bool {
+	return m.Type == MessageTypeSessionExpired || m.Type == MessageTypeSessionRevoked
+}
+
+// PubSub carries messages between instances. Subscribe blocks until ctx is
+// done or the backend fails, calling handler for every published message.
+type PubSub interface {
+	Publish(ctx context.Context, msg Message) error
+	Subscribe(ctx context.Context, handler func(Message)) error
+}
+
+type Hub struct {
+	backend PubSub
+	mu      sync.RWMutex
+	// clients maps a user to the channel of each subscriber and the device
+	// it belongs to.
+	clients map[string]map[chan Message]string
+	closed  bool
+}
+
+func NewHub(backend PubSub) *Hub {
+	return &Hub{
+		backend: backend,
+		clients: make(map[string]map[chan Message]string),
+	}
+}
+
+// Run consumes the backend until ctx is done, resubscribing after failures.
+func (h *Hub) Run(ctx context.Context) {
+	for {
+		err := h.backend.Subscribe(ctx, h.deliver)
+		if ctx.Err() != nil {
+			return
+		}
+
+		slog.WarnContext(ctx, "realtime hub: subscription ended", slog.Any("error", err))
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+func (h *Hub) Publish(ctx context.Context, msg Message) error {
+	if msg.SentAt.IsZero() {
+		msg.SentAt = time.Now()
+	}
+	return h.backend.Publish(ctx, msg)
+}
+
+// Subscribe registers a device of userID. The channel is closed when the
+// returned cancel func is called or when the device falls too far behind, in
+// which case the client is expected to reconnect and refetch. At expiresAt,
+// the expiry of the token the stream was opened with, it gets a final
+// session.expired message; the zero time never expires.
+func (h *Hub) Subscribe(userID string, deviceID string, expiresAt time.Time) (<-chan Message, func()) {
+	ch := make(chan Message, clientBuffer)
+
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		close(ch)
+		return ch, func() {}
+	}
+	if h.clients[userID] == nil {
+		h.clients[userID] = make(map[chan Message]string)
+	}
+	h.clients[userID][ch] = deviceID
+	h.mu.Unlock()
+
+	var expiry *time.Timer
+	if !expiresAt.IsZero() {
+		expiry = time.AfterFunc(time.Until(expiresAt), func() {
+			h.remove(userID, ch, &Message{
+				Type:     MessageTypeSessionExpired,
+				UserID:   userID,
+				DeviceID: deviceID,
+				SentAt:   time.Now(),
+			})
+		})
+	}
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			if expiry != nil {
+				expiry.Stop()
+			}
+			h.remove(userID, ch, nil)
+		})
+	}
+}
+
+func (h *Hub) deliver(msg Message) {
+	h.mu.RLock()
+	var slow, ended []chan Message
+	for ch, deviceID := range h.clients[msg.UserID] {
+		if msg.DeviceID != "" && msg.DeviceID != deviceID {
+			continue
+		}
+		if msg.Final() {
+			ended = append(ended, ch)
+			continue
+		}
+		select {
+		case ch <- msg:
+		default:
+			slow = append(slow, ch)
+		}
+	}
+	h.mu.RUnlock()
+
+	for _, ch := range slow {
+		h.remove(msg.UserID, ch, nil)
+	}
+	for _, ch := range ended {
+		h.remove(msg.UserID, ch, &msg)
+	}
+}
+
+// remove unsubscribes ch and closes it, first handing it last when there is
+// one and the subscriber has room for it.
+func (h *Hub) remove(userID string, ch chan Message, last *Message) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.clients[userID][ch]; !ok {
+		return
+	}
+
+	delete(h.clients[userID], ch)
+	if len(h.clients[userID]) == 0 {
+		delete(h.clients, userID)
+	}
+	if last != nil {
+		select {
+		case ch <- *last:
+		default:
+		}
+	}
+	close(ch)
+}
+
+// Close disconnects every subscriber and refuses new ones. Streams never end
+// on their own, so without this they would hold a shutting down server open
+// until its deadline.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.closed = true
+	for userID, clients := range h.clients {
+		for ch := range clients {
+			close(ch)
+		}
+		delete(h.clients, userID)
+	}
+}
@@ -0,0 +1,46 @@
+package realtime
+
+import (
+	"context"
+	"sync"
+)
+
+// MemoryPubSub only reaches clients connected to this process; use it for a
+// single instance or in tests.
+type MemoryPubSub struct {
+	mu       sync.RWMutex
+	handlers map[int]func(Message)
+	nextID   int
+}
+
+func NewMemoryPubSub() *MemoryPubSub {
+	return &MemoryPubSub{
+		handlers: make(map[int]func(Message)),
+	}
+}
+
+func (p *MemoryPubSub) Publish(ctx context.Context, msg Message) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	for _, handler := range p.handlers {
+		handler(msg)
+	}
+	return nil
+}
+
+func (p *MemoryPubSub) Subscribe(ctx context.Context, handler func(Message)) error {
+	p.mu.Lock()
+	id := p.nextID
+	p.nextID++
+	p.handlers[id] = handler
+	p.mu.Unlock()
+
+	<-ctx.Done()
+
+	p.mu.Lock()
+	delete(p.handlers, id)
+	p.mu.Unlock()
+
+	return ctx.Err()
+}
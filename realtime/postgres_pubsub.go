@@ -0,0 +1,75 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+	"gorm.io/gorm"
+)
+
+// PostgresPubSub uses LISTEN/NOTIFY on the application database, so every
+// instance sharing the database sees every message without extra
+// infrastructure. NOTIFY payloads are limited to 8000 bytes.
+type PostgresPubSub struct {
+	db      *gorm.DB
+	channel string
+}
+
+func NewPostgresPubSub(db *gorm.DB, channel string) *PostgresPubSub {
+	return &PostgresPubSub{
+		db:      db,
+		channel: channel,
+	}
+}
+
+func (p *PostgresPubSub) Publish(ctx context.Context, msg Message) error {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	return p.db.WithContext(ctx).Exec("SELECT pg_notify(?, ?)", p.channel, string(payload)).Error
+}
+
+// Subscribe holds one pooled connection for as long as it listens.
+func (p *PostgresPubSub) Subscribe(ctx context.Context, handler func(Message)) error {
+	sqlDB, err := p.db.DB()
+	if err != nil {
+		return err
+	}
+
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return conn.Raw(func(driverConn any) error {
+		stdlibConn, ok := driverConn.(*stdlib.Conn)
+		if !ok {
+			return fmt.Errorf("realtime: unsupported driver connection %T", driverConn)
+		}
+		pgxConn := stdlibConn.Conn()
+
+		if _, err := pgxConn.Exec(ctx, "LISTEN "+pgx.Identifier{p.channel}.Sanitize()); err != nil {
+			return err
+		}
+		defer pgxConn.Exec(context.Background(), "UNLISTEN *")
+
+		for {
+			notification, err := pgxConn.WaitForNotification(ctx)
+			if err != nil {
+				return err
+			}
+
+			var msg Message
+			if err := json.Unmarshal([]byte(notification.Payload), &msg); err != nil {
+				continue
+			}
+			handler(msg)
+		}
+	})
+}
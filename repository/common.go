@@ -1,6 +1,10 @@
 package repository
 
-import "gorm.io/gorm"
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
 
 func Paginate(page, perPage int) func(db *gorm.DB) *gorm.DB {
 	return func(db *gorm.DB) *gorm.DB {
@@ -8,3 +12,23 @@ func Paginate(page, perPage int) func(db *gorm.DB) *gorm.DB {
 		return db.Offset(offset).Limit(perPage)
 	}
 }
+
+type (
+	Transactor interface {
+		WithTransaction(ctx context.Context, fn func(tx *gorm.DB) error) error
+	}
+
+	transactor struct {
+		db *gorm.DB
+	}
+)
+
+func NewTransactor(db *gorm.DB) Transactor {
+	return &transactor{
+		db: db,
+	}
+}
+
+func (t *transactor) WithTransaction(ctx context.Context, fn func(tx *gorm.DB) error) error {
+	return t.db.WithContext(ctx).Transaction(fn)
+}
@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Amierza/e-wallet/entity"
+	"gorm.io/gorm"
+)
+
+type (
+	DeviceRepository interface {
+		CreateDevice(ctx context.Context, tx *gorm.DB, device entity.Device) error
+		UpdateDevice(ctx context.Context, tx *gorm.DB, device entity.Device) error
+		FindDevice(ctx context.Context, tx *gorm.DB, userID string, deviceID string) (entity.Device, bool, error)
+		CountDevices(ctx context.Context, tx *gorm.DB, userID string) (int64, error)
+		GetActiveDevices(ctx context.Context, tx *gorm.DB, userID string) ([]entity.Device, error)
+		TouchDevice(ctx context.Context, tx *gorm.DB, deviceID string, now time.Time) error
+		RevokeDevice(ctx context.Context, tx *gorm.DB, userID string, deviceID string, now time.Time) (bool, error)
+		RevokeOtherDevices(ctx context.Context, tx *gorm.DB, userID string, keepDeviceID string, now time.Time) error
+	}
+
+	deviceRepository struct {
+		db *gorm.DB
+	}
+)
+
+func NewDeviceRepository(db *gorm.DB) DeviceRepository {
+	return &deviceRepository{
+		db: db,
+	}
+}
+
+func (r *deviceRepository) CreateDevice(ctx context.Context, tx *gorm.DB, device entity.Device) error {
+	if tx == nil {
+		tx = r.db
+	}
+
+	return tx.WithContext(ctx).Omit("User").Create(&device).Error
+}
+
+func (r *deviceRepository) UpdateDevice(ctx context.Context, tx *gorm.DB, device entity.Device) error {
+	if tx == nil {
+		tx = r.db
+	}
+
+	return tx.WithContext(ctx).Omit("User").Save(&device).Error
+}
+
+// FindDevice only finds devices of the given user, so a device ID taken from a
+// request can never reach another account's session.
+func (r *deviceRepository) FindDevice(ctx context.Context, tx *gorm.DB, userID string, deviceID string) (entity.Device, bool, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	var device entity.Device
+	err := tx.WithContext(ctx).Where("id = ? AND user_id = ?", deviceID, userID).Take(&device).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return entity.Device{}, false, nil
+	}
+	if err != nil {
+		return entity.Device{}, false, err
+	}
+
+	return device, true, nil
+}
+
+func (r *deviceRepository) CountDevices(ctx context.Context, tx *gorm.DB, userID string) (int64, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	var count int64
+	err := tx.WithContext(ctx).Model(&entity.Device{}).Where("user_id = ?", userID).Count(&count).Error
+	return count, err
+}
+
+func (r *deviceRepository) GetActiveDevices(ctx context.Context, tx *gorm.DB, userID string) ([]entity.Device, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	var devices []entity.Device
+	err := tx.WithContext(ctx).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Order("last_seen_at DESC").
+		Find(&devices).Error
+	return devices, err
+}
+
+func (r *deviceRepository) TouchDevice(ctx context.Context, tx *gorm.DB, deviceID string, now time.Time) error {
+	if tx == nil {
+		tx = r.db
+	}
+
+	return tx.WithContext(ctx).Model(&entity.Device{}).
+		Where("id = ?", deviceID).
+		UpdateColumn("last_seen_at", now).Error
+}
+
+func (r *deviceRepository) RevokeDevice(ctx context.Context, tx *gorm.DB, userID string, deviceID string, now time.Time) (bool, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	res := tx.WithContext(ctx).Model(&entity.Device{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", deviceID, userID).
+		Update("revoked_at", now)
+	if res.Error != nil {
+		return false, res.Error
+	}
+
+	return res.RowsAffected == 1, nil
+}
+
+func (r *deviceRepository) RevokeOtherDevices(ctx context.Context, tx *gorm.DB, userID string, keepDeviceID string, now time.Time) error {
+	if tx == nil {
+		tx = r.db
+	}
+
+	query := tx.WithContext(ctx).Model(&entity.Device{}).Where("user_id = ? AND revoked_at IS NULL", userID)
+	if keepDeviceID != "" {
+		query = query.Where("id <> ?", keepDeviceID)
+	}
+
+	return query.Update("revoked_at", now).Error
+}
@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Amierza/e-wallet/dto"
+	"github.com/Amierza/e-wallet/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type (
+	FXRepository interface {
+		UpsertRate(ctx context.Context, tx *gorm.DB, rate entity.FXRate) error
+		CreateRateIfMissing(ctx context.Context, tx *gorm.DB, rate entity.FXRate) (bool, error)
+		GetAllRates(ctx context.Context, tx *gorm.DB) ([]entity.FXRate, error)
+		FindRate(ctx context.Context, tx *gorm.DB, baseCurrency, quoteCurrency string) (entity.FXRate, bool, error)
+		CreateQuote(ctx context.Context, tx *gorm.DB, quote entity.FXQuote) error
+		FindQuoteByID(ctx context.Context, tx *gorm.DB, quoteID string) (entity.FXQuote, error)
+		MarkQuoteUsed(ctx context.Context, tx *gorm.DB, quoteID string, usedAt time.Time) (bool, error)
+		CreateRevenue(ctx context.Context, tx *gorm.DB, revenue entity.FXRevenue) error
+		GetRevenueSummary(ctx context.Context, tx *gorm.DB) ([]dto.FXRevenueResponse, error)
+	}
+
+	fxRepository struct {
+		db *gorm.DB
+	}
+)
+
+func NewFXRepository(db *gorm.DB) FXRepository {
+	return &fxRepository{
+		db: db,
+	}
+}
+
+func (r *fxRepository) UpsertRate(ctx context.Context, tx *gorm.DB, rate entity.FXRate) error {
+	if tx == nil {
+		tx = r.db
+	}
+
+	return tx.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "base_currency"}, {Name: "quote_currency"}},
+		DoUpdates: clause.AssignmentColumns([]string{"rate", "spread_bps", "updated_at"}),
+	}).Create(&rate).Error
+}
+
+// CreateRateIfMissing inserts the rate unless the pair already has one, and
+// reports whether it did.
+func (r *fxRepository) CreateRateIfMissing(ctx context.Context, tx *gorm.DB, rate entity.FXRate) (bool, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	result := tx.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "base_currency"}, {Name: "quote_currency"}},
+		DoNothing: true,
+	}).Create(&rate)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+func (r *fxRepository) GetAllRates(ctx context.Context, tx *gorm.DB) ([]entity.FXRate, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	var rates []entity.FXRate
+	if err := tx.WithContext(ctx).Order("base_currency, quote_currency").Find(&rates).Error; err != nil {
+		return nil, err
+	}
+
+	return rates, nil
+}
+
+func (r *fxRepository) FindRate(ctx context.Context, tx *gorm.DB, baseCurrency, quoteCurrency string) (entity.FXRate, bool, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	var rate entity.FXRate
+	if err := tx.WithContext(ctx).Where("base_currency = ? AND quote_currency = ?", baseCurrency, quoteCurrency).Take(&rate).Error; err != nil {
+		return entity.FXRate{}, false, err
+	}
+
+	return rate, true, nil
+}
+
+func (r *fxRepository) CreateQuote(ctx context.Context, tx *gorm.DB, quote entity.FXQuote) error {
+	if tx == nil {
+		tx = r.db
+	}
+
+	return tx.WithContext(ctx).Create(&quote).Error
+}
+
+func (r *fxRepository) FindQuoteByID(ctx context.Context, tx *gorm.DB, quoteID string) (entity.FXQuote, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	var quote entity.FXQuote
+	if err := tx.WithContext(ctx).Where("id = ?", quoteID).Take(&quote).Error; err != nil {
+		return entity.FXQuote{}, err
+	}
+
+	return quote, nil
+}
+
+func (r *fxRepository) MarkQuoteUsed(ctx context.Context, tx *gorm.DB, quoteID string, usedAt time.Time) (bool, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	result := tx.WithContext(ctx).Model(&entity.FXQuote{}).
+		Where("id = ? AND used_at IS NULL AND expires_at > ?", quoteID, usedAt).
+		Update("used_at", usedAt)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+func (r *fxRepository) CreateRevenue(ctx context.Context, tx *gorm.DB, revenue entity.FXRevenue) error {
+	if tx == nil {
+		tx = r.db
+	}
+
+	return tx.WithContext(ctx).Create(&revenue).Error
+}
+
+func (r *fxRepository) GetRevenueSummary(ctx context.Context, tx *gorm.DB) ([]dto.FXRevenueResponse, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	var summary []dto.FXRevenueResponse
+	if err := tx.WithContext(ctx).Model(&entity.FXRevenue{}).
+		Select("currency, SUM(amount) AS amount, COUNT(*) AS count").
+		Group("currency").
+		Order("currency").
+		Scan(&summary).Error; err != nil {
+		return nil, err
+	}
+
+	for i := range summary {
+		summary[i].Amount = summary[i].Amount.WithCurrency(summary[i].Currency)
+	}
+
+	return summary, nil
+}
@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Amierza/e-wallet/dto"
+	"gorm.io/gorm"
+)
+
+// expectedBalanceSQL rebuilds every wallet balance from the history: top-ups
+// and received transfers add, payments and sent transfers subtract.
+const expectedBalanceSQL = `SELECT w.id AS wallet_id, w.user_id, w.currency, w.balance, (
+	COALESCE((SELECT SUM(t.amount) FROM top_ups t WHERE t.user_id = w.user_id AND t.currency = w.currency AND t.deleted_at IS NULL), 0)
+	- COALESCE((SELECT SUM(p.amount) FROM payments p WHERE p.user_id = w.user_id AND p.currency = w.currency AND p.deleted_at IS NULL), 0)
+	- COALESCE((SELECT SUM(o.amount) FROM transfers o WHERE o.user_id = w.user_id AND o.currency = w.currency AND o.deleted_at IS NULL), 0)
+	+ COALESCE((SELECT SUM(i.target_amount) FROM transfers i WHERE i.target_user_id = w.user_id AND i.target_currency = w.currency AND i.deleted_at IS NULL), 0)
+	)::bigint AS expected
+	FROM wallets w WHERE w.deleted_at IS NULL`
+
+// brokenEntrySQL finds rows whose balance_after does not follow from their
+// own balance_before and amount.
+const brokenEntrySQL = `SELECT * FROM (
+	SELECT 'top_up' AS type, id, user_id, currency, amount, balance_before, balance_after, created_at FROM top_ups
+	WHERE deleted_at IS NULL AND balance_after IS DISTINCT FROM balance_before + amount
+	UNION ALL
+	SELECT 'payment', id, user_id, currency, amount, balance_before, balance_after, created_at FROM payments
+	WHERE deleted_at IS NULL AND balance_after IS DISTINCT FROM balance_before - amount
+	UNION ALL
+	SELECT 'transfer', id, user_id, currency, amount, balance_before, balance_after, created_at FROM transfers
+	WHERE deleted_at IS NULL AND balance_after IS DISTINCT FROM balance_before - amount
+) e`
+
+type (
+	LedgerRepository interface {
+		GetWalletBalances(ctx context.Context, tx *gorm.DB, userID string) ([]dto.LedgerWalletResponse, error)
+		GetBrokenEntries(ctx context.Context, tx *gorm.DB, userID string) ([]dto.LedgerEntryResponse, error)
+		CountEntries(ctx context.Context, tx *gorm.DB, userID string) (int64, error)
+	}
+
+	ledgerRepository struct {
+		db *gorm.DB
+	}
+)
+
+func NewLedgerRepository(db *gorm.DB) LedgerRepository {
+	return &ledgerRepository{
+		db: db,
+	}
+}
+
+// GetWalletBalances covers every wallet, or only the user's when userID is
+// set.
+func (r *ledgerRepository) GetWalletBalances(ctx context.Context, tx *gorm.DB, userID string) ([]dto.LedgerWalletResponse, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	query, args := expectedBalanceSQL, []any{}
+	if userID != "" {
+		query, args = query+" AND w.user_id = ?", append(args, userID)
+	}
+
+	var wallets []dto.LedgerWalletResponse
+	if err := tx.WithContext(ctx).Raw(query+" ORDER BY w.user_id, w.currency", args...).Scan(&wallets).Error; err != nil {
+		return nil, err
+	}
+
+	for i := range wallets {
+		wallets[i].Balance = wallets[i].Balance.WithCurrency(wallets[i].Currency)
+		wallets[i].Expected = wallets[i].Expected.WithCurrency(wallets[i].Currency)
+	}
+
+	return wallets, nil
+}
+
+func (r *ledgerRepository) GetBrokenEntries(ctx context.Context, tx *gorm.DB, userID string) ([]dto.LedgerEntryResponse, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	query, args := brokenEntrySQL, []any{}
+	if userID != "" {
+		query, args = query+" WHERE e.user_id = ?", append(args, userID)
+	}
+
+	var entries []dto.LedgerEntryResponse
+	if err := tx.WithContext(ctx).Raw(query+" ORDER BY e.created_at", args...).Scan(&entries).Error; err != nil {
+		return nil, err
+	}
+
+	for i := range entries {
+		entries[i].Amount = entries[i].Amount.WithCurrency(entries[i].Currency)
+		entries[i].BalanceBefore = entries[i].BalanceBefore.WithCurrency(entries[i].Currency)
+		entries[i].BalanceAfter = entries[i].BalanceAfter.WithCurrency(entries[i].Currency)
+	}
+
+	return entries, nil
+}
+
+func (r *ledgerRepository) CountEntries(ctx context.Context, tx *gorm.DB, userID string) (int64, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	var total int64
+	for _, table := range []string{"top_ups", "payments", "transfers"} {
+		query := tx.WithContext(ctx).Table(table).Where("deleted_at IS NULL")
+		if userID != "" {
+			query = query.Where("user_id = ?", userID)
+		}
+
+		var count int64
+		if err := query.Count(&count).Error; err != nil {
+			return 0, err
+		}
+		total += count
+	}
+
+	return total, nil
+}
@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Amierza/e-wallet/entity"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type (
+	OTPRepository interface {
+		CreateOTP(ctx context.Context, tx *gorm.DB, otp entity.OTP) (entity.OTP, error)
+		FindLatestOTP(ctx context.Context, tx *gorm.DB, purpose string, target string) (entity.OTP, bool, error)
+		IncrementOTPAttempts(ctx context.Context, tx *gorm.DB, otpID uuid.UUID) (bool, error)
+		ConsumeOTP(ctx context.Context, tx *gorm.DB, otpID uuid.UUID, now time.Time) (bool, error)
+	}
+
+	otpRepository struct {
+		db *gorm.DB
+	}
+)
+
+func NewOTPRepository(db *gorm.DB) OTPRepository {
+	return &otpRepository{
+		db: db,
+	}
+}
+
+func (r *otpRepository) CreateOTP(ctx context.Context, tx *gorm.DB, otp entity.OTP) (entity.OTP, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	if err := tx.WithContext(ctx).Create(&otp).Error; err != nil {
+		return entity.OTP{}, err
+	}
+
+	return otp, nil
+}
+
+// FindLatestOTP returns the most recently issued code; issuing a new code
+// implicitly retires every older one for the same purpose and target.
+func (r *otpRepository) FindLatestOTP(ctx context.Context, tx *gorm.DB, purpose string, target string) (entity.OTP, bool, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	var otp entity.OTP
+	err := tx.WithContext(ctx).
+		Where("purpose = ? AND target = ?", purpose, target).
+		Order("created_at DESC").
+		Take(&otp).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return entity.OTP{}, false, nil
+	}
+	if err != nil {
+		return entity.OTP{}, false, err
+	}
+
+	return otp, true, nil
+}
+
+// IncrementOTPAttempts counts a guess before it is checked. It reports false
+// once the code has used up its attempts or was consumed; the condition is
+// part of the update, so concurrent guesses cannot exceed the limit.
+func (r *otpRepository) IncrementOTPAttempts(ctx context.Context, tx *gorm.DB, otpID uuid.UUID) (bool, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	res := tx.WithContext(ctx).Model(&entity.OTP{}).
+		Where("id = ? AND attempts < max_attempts AND consumed_at IS NULL", otpID).
+		Update("attempts", gorm.Expr("attempts + 1"))
+	if res.Error != nil {
+		return false, res.Error
+	}
+
+	return res.RowsAffected == 1, nil
+}
+
+// ConsumeOTP marks the code used. It reports false if it was already used.
+func (r *otpRepository) ConsumeOTP(ctx context.Context, tx *gorm.DB, otpID uuid.UUID, now time.Time) (bool, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	res := tx.WithContext(ctx).Model(&entity.OTP{}).
+		Where("id = ? AND consumed_at IS NULL", otpID).
+		Update("consumed_at", now)
+	if res.Error != nil {
+		return false, res.Error
+	}
+
+	return res.RowsAffected == 1, nil
+}
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Amierza/e-wallet/constants"
+	"github.com/Amierza/e-wallet/entity"
+	"gorm.io/gorm"
+)
+
+type (
+	OutboxRepository interface {
+		CreateEvent(ctx context.Context, tx *gorm.DB, event entity.OutboxEvent) error
+		ClaimPendingEvents(ctx context.Context, tx *gorm.DB, now time.Time, lease time.Duration, limit int) ([]entity.OutboxEvent, error)
+		UpdateEvent(ctx context.Context, tx *gorm.DB, event entity.OutboxEvent) error
+	}
+
+	outboxRepository struct {
+		db *gorm.DB
+	}
+)
+
+func NewOutboxRepository(db *gorm.DB) OutboxRepository {
+	return &outboxRepository{
+		db: db,
+	}
+}
+
+func (r *outboxRepository) CreateEvent(ctx context.Context, tx *gorm.DB, event entity.OutboxEvent) error {
+	if tx == nil {
+		tx = r.db
+	}
+
+	return tx.WithContext(ctx).Create(&event).Error
+}
+
+// ClaimPendingEvents only claims the oldest pending event of each aggregate,
+// so a user's events are published strictly one after another even when
+// several relays run at once. Claimed rows are leased by moving their next
+// attempt into the future.
+func (r *outboxRepository) ClaimPendingEvents(ctx context.Context, tx *gorm.DB, now time.Time, lease time.Duration, limit int) ([]entity.OutboxEvent, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	var events []entity.OutboxEvent
+	err := tx.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Raw(`SELECT * FROM outbox_events o
+			WHERE o.status = ? AND o.next_attempt_at <= ?
+			AND NOT EXISTS (
+				SELECT 1 FROM outbox_events p
+				WHERE p.aggregate_id = o.aggregate_id AND p.status = ? AND p.sequence < o.sequence
+			)
+			ORDER BY o.sequence ASC
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED`,
+			constants.ENUM_OUTBOX_STATUS_PENDING, now, constants.ENUM_OUTBOX_STATUS_PENDING, limit).
+			Scan(&events).Error; err != nil {
+			return err
+		}
+
+		if len(events) == 0 {
+			return nil
+		}
+
+		ids := make([]string, 0, len(events))
+		for _, event := range events {
+			ids = append(ids, event.ID.String())
+		}
+
+		return tx.Model(&entity.OutboxEvent{}).Where("id IN ?", ids).Update("next_attempt_at", now.Add(lease)).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+func (r *outboxRepository) UpdateEvent(ctx context.Context, tx *gorm.DB, event entity.OutboxEvent) error {
+	if tx == nil {
+		tx = r.db
+	}
+
+	return tx.WithContext(ctx).Save(&event).Error
+}
@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Amierza/e-wallet/entity"
+	"gorm.io/gorm"
+)
+
+type (
+	TwoFactorRepository interface {
+		FindTwoFactor(ctx context.Context, tx *gorm.DB, userID string) (entity.TwoFactor, bool, error)
+		SaveTwoFactor(ctx context.Context, tx *gorm.DB, twoFactor entity.TwoFactor) error
+		ConfirmTwoFactor(ctx context.Context, tx *gorm.DB, userID string, now time.Time) error
+		UseStep(ctx context.Context, tx *gorm.DB, userID string, step int64, now time.Time) (bool, error)
+		ResetFailedAttempts(ctx context.Context, tx *gorm.DB, userID string) error
+		RecordFailedAttempt(ctx context.Context, tx *gorm.DB, userID string, maxAttempts int, lockedUntil time.Time) error
+		DeleteTwoFactor(ctx context.Context, tx *gorm.DB, userID string) error
+		ReplaceRecoveryCodes(ctx context.Context, tx *gorm.DB, userID string, codes []entity.RecoveryCode) error
+		UseRecoveryCode(ctx context.Context, tx *gorm.DB, userID string, codeHash string, now time.Time) (bool, error)
+	}
+
+	twoFactorRepository struct {
+		db *gorm.DB
+	}
+)
+
+func NewTwoFactorRepository(db *gorm.DB) TwoFactorRepository {
+	return &twoFactorRepository{
+		db: db,
+	}
+}
+
+func (r *twoFactorRepository) FindTwoFactor(ctx context.Context, tx *gorm.DB, userID string) (entity.TwoFactor, bool, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	var twoFactor entity.TwoFactor
+	err := tx.WithContext(ctx).Where("user_id = ?", userID).Take(&twoFactor).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return entity.TwoFactor{}, false, nil
+	}
+	if err != nil {
+		return entity.TwoFactor{}, false, err
+	}
+
+	return twoFactor, true, nil
+}
+
+func (r *twoFactorRepository) SaveTwoFactor(ctx context.Context, tx *gorm.DB, twoFactor entity.TwoFactor) error {
+	if tx == nil {
+		tx = r.db
+	}
+
+	return tx.WithContext(ctx).Omit("User").Save(&twoFactor).Error
+}
+
+func (r *twoFactorRepository) ConfirmTwoFactor(ctx context.Context, tx *gorm.DB, userID string, now time.Time) error {
+	if tx == nil {
+		tx = r.db
+	}
+
+	return tx.WithContext(ctx).Model(&entity.TwoFactor{}).
+		Where("user_id = ? AND confirmed_at IS NULL", userID).
+		Update("confirmed_at", now).Error
+}
+
+// UseStep marks a TOTP step as used and clears the failed attempts. It
+// reports false when that step or a later one was already used, or the
+// factor is locked, so a code is accepted once even by concurrent requests.
+func (r *twoFactorRepository) UseStep(ctx context.Context, tx *gorm.DB, userID string, step int64, now time.Time) (bool, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	res := tx.WithContext(ctx).Model(&entity.TwoFactor{}).
+		Where("user_id = ? AND last_used_step < ? AND (locked_until IS NULL OR locked_until <= ?)", userID, step, now).
+		Updates(map[string]any{"last_used_step": step, "failed_attempts": 0, "locked_until": nil})
+	if res.Error != nil {
+		return false, res.Error
+	}
+
+	return res.RowsAffected == 1, nil
+}
+
+func (r *twoFactorRepository) ResetFailedAttempts(ctx context.Context, tx *gorm.DB, userID string) error {
+	if tx == nil {
+		tx = r.db
+	}
+
+	return tx.WithContext(ctx).Model(&entity.TwoFactor{}).
+		Where("user_id = ?", userID).
+		Updates(map[string]any{"failed_attempts": 0, "locked_until": nil}).Error
+}
+
+// RecordFailedAttempt counts a failed code in the database rather than in
+// the caller, so concurrent guesses cannot overwrite each other's count. The
+// attempt that reaches maxAttempts locks the factor until lockedUntil and
+// starts the count over.
+func (r *twoFactorRepository) RecordFailedAttempt(ctx context.Context, tx *gorm.DB, userID string, maxAttempts int, lockedUntil time.Time) error {
+	if tx == nil {
+		tx = r.db
+	}
+
+	return tx.WithContext(ctx).Model(&entity.TwoFactor{}).
+		Where("user_id = ?", userID).
+		Updates(map[string]any{
+			"failed_attempts": gorm.Expr("CASE WHEN failed_attempts + 1 >= ? THEN 0 ELSE failed_attempts + 1 END", maxAttempts),
+			"locked_until":    gorm.Expr("CASE WHEN failed_attempts + 1 >= ? THEN ? ELSE locked_until END", maxAttempts, lockedUntil),
+		}).Error
+}
+
+func (r *twoFactorRepository) DeleteTwoFactor(ctx context.Context, tx *gorm.DB, userID string) error {
+	if tx == nil {
+		tx = r.db
+	}
+
+	return tx.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&entity.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&entity.TwoFactor{}).Error
+	})
+}
+
+func (r *twoFactorRepository) ReplaceRecoveryCodes(ctx context.Context, tx *gorm.DB, userID string, codes []entity.RecoveryCode) error {
+	if tx == nil {
+		tx = r.db
+	}
+
+	return tx.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&entity.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Omit("User").Create(&codes).Error
+	})
+}
+
+// UseRecoveryCode burns a code. It reports false if the code does not exist
+// or was already used, so each code works exactly once.
+func (r *twoFactorRepository) UseRecoveryCode(ctx context.Context, tx *gorm.DB, userID string, codeHash string, now time.Time) (bool, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	res := tx.WithContext(ctx).Model(&entity.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", now)
+	if res.Error != nil {
+		return false, res.Error
+	}
+
+	return res.RowsAffected == 1, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"math"
 	"strings"
+	"time"
 
 	"github.com/Amierza/e-wallet/dto"
 	"github.com/Amierza/e-wallet/entity"
@@ -14,14 +15,26 @@ type (
 	UserRepository interface {
 		RegisterUser(ctx context.Context, tx *gorm.DB, user entity.User) (entity.User, error)
 		CheckPhoneNumber(ctx context.Context, tx *gorm.DB, phoneNumber string) (entity.User, bool, error)
-		GetAllUsersWithPagination(ctx context.Context, tx *gorm.DB, req dto.PaginationRequest) (dto.GetAllUserRepositoryResponse, error)
+		CheckEmail(ctx context.Context, tx *gorm.DB, email string) (entity.User, bool, error)
+		GetAllUsersWithPagination(ctx context.Context, tx *gorm.DB, req dto.PaginationRequest, searchContact bool) (dto.GetAllUserRepositoryResponse, error)
 		FindUserByID(ctx context.Context, tx *gorm.DB, userID string) (entity.User, error)
 		CheckTargetUser(ctx context.Context, tx *gorm.DB, userID string) (entity.User, bool, error)
 		UpdateUser(ctx context.Context, tx *gorm.DB, user entity.User) error
+		UpdatePin(ctx context.Context, tx *gorm.DB, userID string, pinHash string) error
+		UpdateFrozenAt(ctx context.Context, tx *gorm.DB, userID string, frozenAt *time.Time) error
+		RevokeSessions(ctx context.Context, tx *gorm.DB, userID string, now time.Time) error
+		CreatePinHistory(ctx context.Context, tx *gorm.DB, history entity.PinHistory) error
+		GetRecentPinHistory(ctx context.Context, tx *gorm.DB, userID string, limit int) ([]entity.PinHistory, error)
 		CreateTopUp(ctx context.Context, tx *gorm.DB, topup entity.TopUp) error
 		CreatePayment(ctx context.Context, tx *gorm.DB, payment entity.Payment) error
 		CreateTransfer(ctx context.Context, tx *gorm.DB, transfer entity.Transfer) error
 		GetAllTransactionWithPagination(ctx context.Context, tx *gorm.DB, req dto.PaginationRequest) (dto.GetAllTransactionRepositoryResponse, error)
+		GetUserTransactionsBetween(ctx context.Context, tx *gorm.DB, userID string, from time.Time, to time.Time) (dto.GetAllTransactionRepositoryResponse, error)
+		GetTransactionsBetween(ctx context.Context, tx *gorm.DB, from time.Time, to time.Time) (dto.GetAllTransactionRepositoryResponse, error)
+		CreateWallet(ctx context.Context, tx *gorm.DB, wallet entity.Wallet) (entity.Wallet, error)
+		FindWallet(ctx context.Context, tx *gorm.DB, userID string, currency string) (entity.Wallet, bool, error)
+		GetWalletsByUserID(ctx context.Context, tx *gorm.DB, userID string) ([]entity.Wallet, error)
+		UpdateWallet(ctx context.Context, tx *gorm.DB, wallet entity.Wallet) error
 	}
 
 	userRepository struct {
@@ -60,6 +73,19 @@ func (r *userRepository) CheckPhoneNumber(ctx context.Context, tx *gorm.DB, phon
 	return user, true, nil
 }
 
+func (r *userRepository) CheckEmail(ctx context.Context, tx *gorm.DB, email string) (entity.User, bool, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	var user entity.User
+	if err := tx.WithContext(ctx).Where("email = ?", email).Take(&user).Error; err != nil {
+		return entity.User{}, false, err
+	}
+
+	return user, true, nil
+}
+
 func (r *userRepository) FindUserByID(ctx context.Context, tx *gorm.DB, userID string) (entity.User, error) {
 	if tx == nil {
 		tx = r.db
@@ -81,6 +107,55 @@ func (r *userRepository) UpdateUser(ctx context.Context, tx *gorm.DB, user entit
 	return tx.WithContext(ctx).Save(&user).Error
 }
 
+// UpdatePin only touches the pin column, so it is safe to call while other
+// requests are modifying the same user.
+func (r *userRepository) UpdatePin(ctx context.Context, tx *gorm.DB, userID string, pinHash string) error {
+	if tx == nil {
+		tx = r.db
+	}
+
+	return tx.WithContext(ctx).Model(&entity.User{}).Where("id = ?", userID).Update("pin", pinHash).Error
+}
+
+// UpdateFrozenAt sets or, with nil, clears the freeze without touching the
+// rest of the user.
+func (r *userRepository) UpdateFrozenAt(ctx context.Context, tx *gorm.DB, userID string, frozenAt *time.Time) error {
+	if tx == nil {
+		tx = r.db
+	}
+
+	return tx.WithContext(ctx).Model(&entity.User{}).Where("id = ?", userID).Update("frozen_at", frozenAt).Error
+}
+
+func (r *userRepository) RevokeSessions(ctx context.Context, tx *gorm.DB, userID string, now time.Time) error {
+	if tx == nil {
+		tx = r.db
+	}
+
+	return tx.WithContext(ctx).Model(&entity.User{}).Where("id = ?", userID).Update("sessions_revoked_at", now).Error
+}
+
+func (r *userRepository) CreatePinHistory(ctx context.Context, tx *gorm.DB, history entity.PinHistory) error {
+	if tx == nil {
+		tx = r.db
+	}
+
+	return tx.WithContext(ctx).Omit("User").Create(&history).Error
+}
+
+func (r *userRepository) GetRecentPinHistory(ctx context.Context, tx *gorm.DB, userID string, limit int) ([]entity.PinHistory, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	var histories []entity.PinHistory
+	if err := tx.WithContext(ctx).Where("user_id = ?", userID).Order("created_at DESC").Limit(limit).Find(&histories).Error; err != nil {
+		return nil, err
+	}
+
+	return histories, nil
+}
+
 func (r *userRepository) CreateTopUp(ctx context.Context, tx *gorm.DB, topup entity.TopUp) error {
 	if tx == nil {
 		tx = r.db
@@ -118,7 +193,56 @@ func (r *userRepository) CheckTargetUser(ctx context.Context, tx *gorm.DB, userI
 	return user, true, nil
 }
 
-func (r *userRepository) GetAllUsersWithPagination(ctx context.Context, tx *gorm.DB, req dto.PaginationRequest) (dto.GetAllUserRepositoryResponse, error) {
+func (r *userRepository) CreateWallet(ctx context.Context, tx *gorm.DB, wallet entity.Wallet) (entity.Wallet, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	if err := tx.WithContext(ctx).Create(&wallet).Error; err != nil {
+		return entity.Wallet{}, err
+	}
+
+	return wallet, nil
+}
+
+func (r *userRepository) FindWallet(ctx context.Context, tx *gorm.DB, userID string, currency string) (entity.Wallet, bool, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	var wallet entity.Wallet
+	if err := tx.WithContext(ctx).Where("user_id = ? AND currency = ?", userID, currency).Take(&wallet).Error; err != nil {
+		return entity.Wallet{}, false, err
+	}
+
+	return wallet, true, nil
+}
+
+func (r *userRepository) GetWalletsByUserID(ctx context.Context, tx *gorm.DB, userID string) ([]entity.Wallet, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	var wallets []entity.Wallet
+	if err := tx.WithContext(ctx).Where("user_id = ?", userID).Order("created_at ASC").Find(&wallets).Error; err != nil {
+		return nil, err
+	}
+
+	return wallets, nil
+}
+
+func (r *userRepository) UpdateWallet(ctx context.Context, tx *gorm.DB, wallet entity.Wallet) error {
+	if tx == nil {
+		tx = r.db
+	}
+
+	return tx.WithContext(ctx).Save(&wallet).Error
+}
+
+// GetAllUsersWithPagination searches names, and phone numbers and addresses
+// too when searchContact is set, so only callers allowed to see contact
+// details can find users by them.
+func (r *userRepository) GetAllUsersWithPagination(ctx context.Context, tx *gorm.DB, req dto.PaginationRequest, searchContact bool) (dto.GetAllUserRepositoryResponse, error) {
 	if tx == nil {
 		tx = r.db
 	}
@@ -139,15 +263,19 @@ func (r *userRepository) GetAllUsersWithPagination(ctx context.Context, tx *gorm
 
 	if req.Search != "" {
 		searchValue := "%" + strings.ToLower(req.Search) + "%"
-		query = query.Where("LOWER(first_name) LIKE ? OR LOWER(last_name) LIKE ? OR LOWER(phone_number) LIKE ? OR LOWER(address) LIKE ?",
-			searchValue, searchValue, searchValue, searchValue)
+		if searchContact {
+			query = query.Where("LOWER(first_name) LIKE ? OR LOWER(last_name) LIKE ? OR LOWER(phone_number) LIKE ? OR LOWER(address) LIKE ?",
+				searchValue, searchValue, searchValue, searchValue)
+		} else {
+			query = query.Where("LOWER(first_name) LIKE ? OR LOWER(last_name) LIKE ?", searchValue, searchValue)
+		}
 	}
 
 	if err := query.Count(&count).Error; err != nil {
 		return dto.GetAllUserRepositoryResponse{}, err
 	}
 
-	if err := query.Order("created_at DESC").Scopes(Paginate(req.Page, req.PerPage)).Find(&users).Error; err != nil {
+	if err := query.Preload("Wallets").Order("created_at DESC").Scopes(Paginate(req.Page, req.PerPage)).Find(&users).Error; err != nil {
 		return dto.GetAllUserRepositoryResponse{}, err
 	}
 
@@ -225,3 +353,57 @@ func (r *userRepository) GetAllTransactionWithPagination(ctx context.Context, tx
 		},
 	}, err
 }
+
+// GetUserTransactionsBetween returns every movement on the user's wallets in
+// [from, to), including transfers the user received.
+func (r *userRepository) GetUserTransactionsBetween(ctx context.Context, tx *gorm.DB, userID string, from time.Time, to time.Time) (dto.GetAllTransactionRepositoryResponse, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	var res dto.GetAllTransactionRepositoryResponse
+	period := "created_at >= ? AND created_at < ?"
+
+	if err := tx.WithContext(ctx).Where("user_id = ?", userID).Where(period, from, to).
+		Order("created_at ASC").Find(&res.TopUps).Error; err != nil {
+		return dto.GetAllTransactionRepositoryResponse{}, err
+	}
+
+	if err := tx.WithContext(ctx).Where("user_id = ?", userID).Where(period, from, to).
+		Order("created_at ASC").Find(&res.Payments).Error; err != nil {
+		return dto.GetAllTransactionRepositoryResponse{}, err
+	}
+
+	if err := tx.WithContext(ctx).Where("user_id = ? OR target_user_id = ?", userID, userID).Where(period, from, to).
+		Order("created_at ASC").Find(&res.Transfers).Error; err != nil {
+		return dto.GetAllTransactionRepositoryResponse{}, err
+	}
+
+	res.Count = int64(len(res.TopUps) + len(res.Payments) + len(res.Transfers))
+	return res, nil
+}
+
+// GetTransactionsBetween returns the movements of every user in [from, to).
+func (r *userRepository) GetTransactionsBetween(ctx context.Context, tx *gorm.DB, from time.Time, to time.Time) (dto.GetAllTransactionRepositoryResponse, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	var res dto.GetAllTransactionRepositoryResponse
+	period := "created_at >= ? AND created_at < ?"
+
+	if err := tx.WithContext(ctx).Where(period, from, to).Order("created_at ASC").Find(&res.TopUps).Error; err != nil {
+		return dto.GetAllTransactionRepositoryResponse{}, err
+	}
+
+	if err := tx.WithContext(ctx).Where(period, from, to).Order("created_at ASC").Find(&res.Payments).Error; err != nil {
+		return dto.GetAllTransactionRepositoryResponse{}, err
+	}
+
+	if err := tx.WithContext(ctx).Where(period, from, to).Order("created_at ASC").Find(&res.Transfers).Error; err != nil {
+		return dto.GetAllTransactionRepositoryResponse{}, err
+	}
+
+	res.Count = int64(len(res.TopUps) + len(res.Payments) + len(res.Transfers))
+	return res, nil
+}
@@ -0,0 +1,212 @@
+package repository
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/Amierza/e-wallet/constants"
+	"github.com/Amierza/e-wallet/dto"
+	"github.com/Amierza/e-wallet/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type (
+	WebhookRepository interface {
+		CreateEndpoint(ctx context.Context, tx *gorm.DB, endpoint entity.WebhookEndpoint) (entity.WebhookEndpoint, error)
+		GetEndpoints(ctx context.Context, tx *gorm.DB, userID string, includeGlobal bool) ([]entity.WebhookEndpoint, error)
+		GetActiveEndpointsForUser(ctx context.Context, tx *gorm.DB, userID string) ([]entity.WebhookEndpoint, error)
+		FindEndpointByID(ctx context.Context, tx *gorm.DB, endpointID string) (entity.WebhookEndpoint, error)
+		DeleteEndpoint(ctx context.Context, tx *gorm.DB, endpointID string) error
+		CreateDeliveries(ctx context.Context, tx *gorm.DB, deliveries []entity.WebhookDelivery) error
+		FindDeliveryByID(ctx context.Context, tx *gorm.DB, deliveryID string) (entity.WebhookDelivery, error)
+		GetDeliveriesWithPagination(ctx context.Context, tx *gorm.DB, endpointIDs []string, req dto.PaginationRequest) (dto.GetAllWebhookDeliveryRepositoryResponse, error)
+		ClaimDueDeliveries(ctx context.Context, tx *gorm.DB, now time.Time, lease time.Duration, limit int) ([]entity.WebhookDelivery, error)
+		UpdateDelivery(ctx context.Context, tx *gorm.DB, delivery entity.WebhookDelivery) error
+	}
+
+	webhookRepository struct {
+		db *gorm.DB
+	}
+)
+
+func NewWebhookRepository(db *gorm.DB) WebhookRepository {
+	return &webhookRepository{
+		db: db,
+	}
+}
+
+func (r *webhookRepository) CreateEndpoint(ctx context.Context, tx *gorm.DB, endpoint entity.WebhookEndpoint) (entity.WebhookEndpoint, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	if err := tx.WithContext(ctx).Create(&endpoint).Error; err != nil {
+		return entity.WebhookEndpoint{}, err
+	}
+
+	return endpoint, nil
+}
+
+func (r *webhookRepository) GetEndpoints(ctx context.Context, tx *gorm.DB, userID string, includeGlobal bool) ([]entity.WebhookEndpoint, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	query := tx.WithContext(ctx).Where("user_id = ?", userID)
+	if includeGlobal {
+		query = query.Or("user_id IS NULL")
+	}
+
+	var endpoints []entity.WebhookEndpoint
+	if err := query.Order("created_at DESC").Find(&endpoints).Error; err != nil {
+		return nil, err
+	}
+
+	return endpoints, nil
+}
+
+func (r *webhookRepository) GetActiveEndpointsForUser(ctx context.Context, tx *gorm.DB, userID string) ([]entity.WebhookEndpoint, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	var endpoints []entity.WebhookEndpoint
+	if err := tx.WithContext(ctx).Where("active = ? AND (user_id = ? OR user_id IS NULL)", true, userID).Find(&endpoints).Error; err != nil {
+		return nil, err
+	}
+
+	return endpoints, nil
+}
+
+func (r *webhookRepository) FindEndpointByID(ctx context.Context, tx *gorm.DB, endpointID string) (entity.WebhookEndpoint, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	var endpoint entity.WebhookEndpoint
+	if err := tx.WithContext(ctx).Where("id = ?", endpointID).Take(&endpoint).Error; err != nil {
+		return entity.WebhookEndpoint{}, err
+	}
+
+	return endpoint, nil
+}
+
+func (r *webhookRepository) DeleteEndpoint(ctx context.Context, tx *gorm.DB, endpointID string) error {
+	if tx == nil {
+		tx = r.db
+	}
+
+	return tx.WithContext(ctx).Where("id = ?", endpointID).Delete(&entity.WebhookEndpoint{}).Error
+}
+
+func (r *webhookRepository) CreateDeliveries(ctx context.Context, tx *gorm.DB, deliveries []entity.WebhookDelivery) error {
+	if tx == nil {
+		tx = r.db
+	}
+
+	return tx.WithContext(ctx).Omit("Endpoint").Create(&deliveries).Error
+}
+
+func (r *webhookRepository) FindDeliveryByID(ctx context.Context, tx *gorm.DB, deliveryID string) (entity.WebhookDelivery, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	var delivery entity.WebhookDelivery
+	if err := tx.WithContext(ctx).Preload("Endpoint").Where("id = ?", deliveryID).Take(&delivery).Error; err != nil {
+		return entity.WebhookDelivery{}, err
+	}
+
+	return delivery, nil
+}
+
+func (r *webhookRepository) GetDeliveriesWithPagination(ctx context.Context, tx *gorm.DB, endpointIDs []string, req dto.PaginationRequest) (dto.GetAllWebhookDeliveryRepositoryResponse, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	var deliveries []entity.WebhookDelivery
+	var err error
+	var count int64
+
+	if req.PerPage == 0 {
+		req.PerPage = 10
+	}
+
+	if req.Page == 0 {
+		req.Page = 1
+	}
+
+	query := tx.WithContext(ctx).Model(&entity.WebhookDelivery{}).Where("endpoint_id IN ?", endpointIDs)
+
+	if req.Search != "" {
+		query = query.Where("event_type = ? OR status = ?", req.Search, req.Search)
+	}
+
+	if err := query.Count(&count).Error; err != nil {
+		return dto.GetAllWebhookDeliveryRepositoryResponse{}, err
+	}
+
+	if err := query.Order("created_at DESC").Scopes(Paginate(req.Page, req.PerPage)).Find(&deliveries).Error; err != nil {
+		return dto.GetAllWebhookDeliveryRepositoryResponse{}, err
+	}
+
+	totalPage := int64(math.Ceil(float64(count) / float64(req.PerPage)))
+
+	return dto.GetAllWebhookDeliveryRepositoryResponse{
+		Deliveries: deliveries,
+		PaginationResponse: dto.PaginationResponse{
+			Page:    req.Page,
+			PerPage: req.PerPage,
+			Count:   count,
+			MaxPage: totalPage,
+		},
+	}, err
+}
+
+// ClaimDueDeliveries locks a batch of due deliveries and pushes their next
+// attempt out by lease, so concurrent dispatchers on other instances skip them
+// while this one is sending.
+func (r *webhookRepository) ClaimDueDeliveries(ctx context.Context, tx *gorm.DB, now time.Time, lease time.Duration, limit int) ([]entity.WebhookDelivery, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	var ids []string
+	err := tx.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&entity.WebhookDelivery{}).
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", constants.ENUM_WEBHOOK_STATUS_PENDING, now).
+			Order("next_attempt_at ASC").
+			Limit(limit).
+			Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+
+		if len(ids) == 0 {
+			return nil
+		}
+
+		return tx.Model(&entity.WebhookDelivery{}).Where("id IN ?", ids).Update("next_attempt_at", now.Add(lease)).Error
+	})
+	if err != nil || len(ids) == 0 {
+		return nil, err
+	}
+
+	var deliveries []entity.WebhookDelivery
+	if err := tx.WithContext(ctx).Preload("Endpoint").Where("id IN ?", ids).Find(&deliveries).Error; err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+func (r *webhookRepository) UpdateDelivery(ctx context.Context, tx *gorm.DB, delivery entity.WebhookDelivery) error {
+	if tx == nil {
+		tx = r.db
+	}
+
+	return tx.WithContext(ctx).Omit("Endpoint").Save(&delivery).Error
+}
@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"github.com/Amierza/e-wallet/controller"
+	"github.com/Amierza/e-wallet/middleware"
+	"github.com/Amierza/e-wallet/service"
+	"github.com/gin-gonic/gin"
+)
+
+func Device(route *gin.Engine, deviceController controller.DeviceController, jwtService service.JWTService) {
+	routes := route.Group("api/user/devices", middleware.Authenticate(jwtService))
+	{
+		// Session
+		routes.GET("", deviceController.GetAllDevice)
+		routes.DELETE("/:id", deviceController.RevokeDevice)
+	}
+}
@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"github.com/Amierza/e-wallet/constants"
+	"github.com/Amierza/e-wallet/controller"
+	"github.com/Amierza/e-wallet/middleware"
+	"github.com/Amierza/e-wallet/service"
+	"github.com/gin-gonic/gin"
+)
+
+func FX(route *gin.Engine, fxController controller.FXController, jwtService service.JWTService) {
+	routes := route.Group("api/fx")
+	{
+		// FX
+		routes.GET("/rates", middleware.Authenticate(jwtService), fxController.GetAllRate)
+		routes.POST("/quote", middleware.Authenticate(jwtService), fxController.CreateQuote)
+
+		// Admin
+		routes.POST("/rates", middleware.Authenticate(jwtService), middleware.OnlyAllow(constants.ENUM_ROLE_ADMIN), fxController.UpsertRate)
+		routes.GET("/revenue", middleware.Authenticate(jwtService), middleware.OnlyAllow(constants.ENUM_ROLE_ADMIN), fxController.GetRevenue)
+	}
+}
@@ -0,0 +1,13 @@
+package routes
+
+import (
+	"github.com/Amierza/e-wallet/controller"
+	"github.com/gin-gonic/gin"
+)
+
+func Health(route *gin.Engine, healthController controller.HealthController) {
+	// Probes and build info
+	route.GET("/healthz", healthController.Healthz)
+	route.GET("/readyz", healthController.Readyz)
+	route.GET("/version", healthController.Version)
+}
@@ -0,0 +1,13 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/Amierza/e-wallet/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+func Metrics(route *gin.Engine, handler http.Handler, token string) {
+	// Prometheus scrape endpoint
+	route.GET("/metrics", middleware.ScrapeToken(token), gin.WrapH(handler))
+}
@@ -0,0 +1,13 @@
+package routes
+
+import "github.com/gin-gonic/gin"
+
+// RateLimits are the rate-limit middlewares of the route groups that need one.
+type RateLimits struct {
+	Login       gin.HandlerFunc
+	TwoFactor   gin.HandlerFunc
+	Register    gin.HandlerFunc
+	OTP         gin.HandlerFunc
+	Transaction gin.HandlerFunc
+	Email       gin.HandlerFunc
+}
@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"github.com/Amierza/e-wallet/controller"
+	"github.com/Amierza/e-wallet/middleware"
+	"github.com/Amierza/e-wallet/service"
+	"github.com/gin-gonic/gin"
+)
+
+func Realtime(route *gin.Engine, realtimeController controller.RealtimeController, jwtService service.JWTService) {
+	routes := route.Group("api/realtime", middleware.AuthenticateStream(jwtService))
+	{
+		routes.GET("/ws", realtimeController.WebSocket)
+		routes.GET("/events", realtimeController.Stream)
+	}
+}
@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"github.com/Amierza/e-wallet/controller"
+	"github.com/Amierza/e-wallet/middleware"
+	"github.com/Amierza/e-wallet/service"
+	"github.com/gin-gonic/gin"
+)
+
+func TwoFactor(route *gin.Engine, twoFactorController controller.TwoFactorController, jwtService service.JWTService, deviceService service.DeviceService, limits RateLimits) {
+	routes := route.Group("api/user")
+	{
+		// Login
+		routes.POST("/login/2fa", limits.TwoFactor, twoFactorController.CompleteLogin)
+
+		// Management
+		routes.POST("/2fa/enroll", middleware.Authenticate(jwtService), twoFactorController.Enroll)
+		routes.POST("/2fa/confirm", middleware.Authenticate(jwtService), twoFactorController.Confirm)
+		routes.POST("/2fa/disable", middleware.Authenticate(jwtService), middleware.OnlyEstablishedDevice(deviceService), twoFactorController.Disable)
+		routes.POST("/2fa/recovery_codes", middleware.Authenticate(jwtService), twoFactorController.RegenerateRecoveryCodes)
+	}
+}
@@ -7,17 +7,28 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func User(route *gin.Engine, userController controller.UserController, jwtService service.JWTService) {
+func User(route *gin.Engine, userController controller.UserController, jwtService service.JWTService, deviceService service.DeviceService, limits RateLimits) {
 	routes := route.Group("api/user")
 	{
 		// User
-		routes.POST("/register", userController.Register)
-		routes.POST("/login", userController.Login)
-		routes.POST("/topup", middleware.Authenticate(jwtService), userController.TopUp)
-		routes.POST("/pay", middleware.Authenticate(jwtService), userController.Payment)
-		routes.POST("/transfer", middleware.Authenticate(jwtService), userController.Transfer)
+		routes.POST("/register", limits.Register, userController.Register)
+		routes.POST("/register/verify_phone", limits.OTP, userController.VerifyPhone)
+		routes.POST("/register/resend_otp", limits.OTP, userController.ResendPhoneOTP)
+		routes.GET("/register/verify_email", userController.VerifyEmail)
+		routes.POST("/email/resend_verification", middleware.Authenticate(jwtService), limits.Email, userController.ResendEmailVerification)
+		routes.POST("/statements/email", middleware.Authenticate(jwtService), limits.Email, userController.SendStatement)
+		routes.POST("/login", limits.Login, userController.Login)
+		routes.POST("/pin/forgot", limits.OTP, userController.ForgotPin)
+		routes.POST("/pin/verify_otp", limits.OTP, userController.VerifyPinReset)
+		routes.POST("/pin/reset", limits.OTP, userController.ResetPin)
+		routes.POST("/pin/change", middleware.Authenticate(jwtService), middleware.OnlyEstablishedDevice(deviceService), userController.ChangePin)
+		routes.POST("/topup", middleware.Authenticate(jwtService), limits.Transaction, userController.TopUp)
+		routes.POST("/pay", middleware.Authenticate(jwtService), limits.Transaction, middleware.OnlyEstablishedDevice(deviceService), userController.Payment)
+		routes.POST("/transfer", middleware.Authenticate(jwtService), limits.Transaction, middleware.OnlyEstablishedDevice(deviceService), userController.Transfer)
 		routes.GET("/get-all-user", middleware.Authenticate(jwtService), userController.GetAllUser)
 		routes.GET("/transactions", middleware.Authenticate(jwtService), userController.GetAllTransaction)
-		routes.POST("/update-profile", middleware.Authenticate(jwtService), userController.UpdateProfile)
+		routes.POST("/update-profile", middleware.Authenticate(jwtService), middleware.OnlyEstablishedDevice(deviceService), userController.UpdateProfile)
+		routes.GET("/wallets", middleware.Authenticate(jwtService), userController.GetAllWallet)
+		routes.POST("/wallets", middleware.Authenticate(jwtService), userController.OpenWallet)
 	}
 }
@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"github.com/Amierza/e-wallet/controller"
+	"github.com/Amierza/e-wallet/middleware"
+	"github.com/Amierza/e-wallet/service"
+	"github.com/gin-gonic/gin"
+)
+
+func Webhook(route *gin.Engine, webhookController controller.WebhookController, jwtService service.JWTService) {
+	routes := route.Group("api/webhooks", middleware.Authenticate(jwtService))
+	{
+		// Endpoint
+		routes.POST("/endpoints", webhookController.CreateEndpoint)
+		routes.GET("/endpoints", webhookController.GetAllEndpoint)
+		routes.DELETE("/endpoints/:id", webhookController.DeleteEndpoint)
+
+		// Delivery
+		routes.GET("/deliveries", webhookController.GetAllDelivery)
+		routes.POST("/deliveries/:id/redeliver", webhookController.Redeliver)
+	}
+}
@@ -0,0 +1,14 @@
+package routes
+
+import (
+	"github.com/Amierza/e-wallet/controller"
+	"github.com/gin-gonic/gin"
+)
+
+func WellKnown(route *gin.Engine, wellKnownController controller.WellKnownController) {
+	routes := route.Group(".well-known")
+	{
+		// Token verification keys
+		routes.GET("/jwks.json", wellKnownController.JWKS)
+	}
+}
@@ -0,0 +1,358 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/Amierza/e-wallet/constants"
+	"github.com/Amierza/e-wallet/dto"
+	"github.com/Amierza/e-wallet/entity"
+	"github.com/Amierza/e-wallet/repository"
+	"github.com/Amierza/e-wallet/view"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type (
+	// AdminService backs the operator commands. Callers are trusted, so it
+	// does not read a principal from the context.
+	AdminService interface {
+		CreateAdmin(ctx context.Context, req dto.AdminCreateRequest) (dto.UserResponse, error)
+		GetUser(ctx context.Context, req dto.UserLookupRequest) (dto.UserDetailResponse, error)
+		FreezeUser(ctx context.Context, req dto.UserLookupRequest) (dto.UserResponse, error)
+		UnfreezeUser(ctx context.Context, req dto.UserLookupRequest) (dto.UserResponse, error)
+		RevokeTokens(ctx context.Context, req dto.RevokeTokensRequest) (dto.RevokeTokensResponse, error)
+		ExportTransactions(ctx context.Context, req dto.ExportTransactionsRequest) ([]dto.TransactionExportResponse, error)
+	}
+
+	adminService struct {
+		userRepo   repository.UserRepository
+		deviceRepo repository.DeviceRepository
+		outboxRepo repository.OutboxRepository
+		transactor repository.Transactor
+	}
+)
+
+func NewAdminService(userRepo repository.UserRepository, deviceRepo repository.DeviceRepository, outboxRepo repository.OutboxRepository, transactor repository.Transactor) AdminService {
+	return &adminService{
+		userRepo:   userRepo,
+		deviceRepo: deviceRepo,
+		outboxRepo: outboxRepo,
+		transactor: transactor,
+	}
+}
+
+// CreateAdmin registers an operator account. The operator vouches for the
+// phone number and email, so both start out verified.
+func (s *adminService) CreateAdmin(ctx context.Context, req dto.AdminCreateRequest) (dto.UserResponse, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if req.PhoneNumber == "" {
+		return dto.UserResponse{}, dto.ErrUserLookupRequired
+	}
+
+	_, flag, err := s.userRepo.CheckPhoneNumber(ctx, nil, req.PhoneNumber)
+	if err == nil || flag {
+		return dto.UserResponse{}, dto.ErrPhoneNumberAlreadyExists
+	}
+
+	now := time.Now()
+	user := entity.User{
+		ID:              uuid.New(),
+		FirstName:       req.FirstName,
+		LastName:        req.LastName,
+		PhoneNumber:     req.PhoneNumber,
+		PhoneVerifiedAt: &now,
+		Role:            constants.ENUM_ROLE_ADMIN,
+		Wallets:         []entity.Wallet{newWallet(constants.ENUM_CURRENCY_DEFAULT)},
+	}
+
+	if err := SetPin(ctx, s.userRepo, &user, req.Pin); err != nil {
+		return dto.UserResponse{}, err
+	}
+
+	if req.Email != "" {
+		email := normalizeEmail(req.Email)
+		_, flag, err := s.userRepo.CheckEmail(ctx, nil, email)
+		if err == nil || flag {
+			return dto.UserResponse{}, dto.ErrEmailAlreadyExists
+		}
+		user.Email = &email
+		user.EmailVerifiedAt = &now
+	}
+
+	var created entity.User
+	err = s.transactor.WithTransaction(ctx, func(tx *gorm.DB) error {
+		created, err = s.userRepo.RegisterUser(ctx, tx, user)
+		if err != nil {
+			return dto.ErrCreateUser
+		}
+
+		return RecordPin(ctx, s.userRepo, tx, created)
+	})
+	if err != nil {
+		return dto.UserResponse{}, err
+	}
+
+	return view.User(view.AudienceAdmin, created), nil
+}
+
+func (s *adminService) GetUser(ctx context.Context, req dto.UserLookupRequest) (dto.UserDetailResponse, error) {
+	user, err := s.findUser(ctx, req)
+	if err != nil {
+		return dto.UserDetailResponse{}, err
+	}
+
+	user.Wallets, err = s.userRepo.GetWalletsByUserID(ctx, nil, user.ID.String())
+	if err != nil {
+		return dto.UserDetailResponse{}, dto.ErrGetWallets
+	}
+
+	devices, err := s.deviceRepo.GetActiveDevices(ctx, nil, user.ID.String())
+	if err != nil {
+		return dto.UserDetailResponse{}, dto.ErrGetDevice
+	}
+
+	return dto.UserDetailResponse{
+		UserResponse:  view.User(view.AudienceAdmin, user),
+		ActiveDevices: len(devices),
+	}, nil
+}
+
+// FreezeUser blocks the account and signs it out everywhere. Freezing an
+// already frozen account keeps the original freeze time.
+func (s *adminService) FreezeUser(ctx context.Context, req dto.UserLookupRequest) (dto.UserResponse, error) {
+	user, err := s.findUser(ctx, req)
+	if err != nil {
+		return dto.UserResponse{}, err
+	}
+
+	if user.FrozenAt != nil {
+		return view.User(view.AudienceAdmin, user), nil
+	}
+
+	now := time.Now()
+	err = s.transactor.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := s.userRepo.UpdateFrozenAt(ctx, tx, user.ID.String(), &now); err != nil {
+			return dto.ErrUpdateUser
+		}
+		if err := s.userRepo.RevokeSessions(ctx, tx, user.ID.String(), now); err != nil {
+			return dto.ErrUpdateUser
+		}
+		if err := s.deviceRepo.RevokeOtherDevices(ctx, tx, user.ID.String(), "", now); err != nil {
+			return dto.ErrRevokeDevice
+		}
+		return recordSessionRevoked(ctx, s.outboxRepo, tx, user.ID, "", now)
+	})
+	if err != nil {
+		return dto.UserResponse{}, err
+	}
+
+	user.FrozenAt = &now
+	return view.User(view.AudienceAdmin, user), nil
+}
+
+// UnfreezeUser lifts a freeze. Sessions stay revoked, so the user has to log
+// in again.
+func (s *adminService) UnfreezeUser(ctx context.Context, req dto.UserLookupRequest) (dto.UserResponse, error) {
+	user, err := s.findUser(ctx, req)
+	if err != nil {
+		return dto.UserResponse{}, err
+	}
+
+	if user.FrozenAt == nil {
+		return dto.UserResponse{}, dto.ErrAccountNotFrozen
+	}
+
+	if err := s.userRepo.UpdateFrozenAt(ctx, nil, user.ID.String(), nil); err != nil {
+		return dto.UserResponse{}, dto.ErrUpdateUser
+	}
+
+	user.FrozenAt = nil
+	return view.User(view.AudienceAdmin, user), nil
+}
+
+func (s *adminService) RevokeTokens(ctx context.Context, req dto.RevokeTokensRequest) (dto.RevokeTokensResponse, error) {
+	user, err := s.findUser(ctx, req.UserLookupRequest)
+	if err != nil {
+		return dto.RevokeTokensResponse{}, err
+	}
+
+	now := time.Now()
+	res := dto.RevokeTokensResponse{
+		UserID:    user.ID.String(),
+		DeviceID:  req.DeviceID,
+		RevokedAt: now,
+	}
+
+	if req.DeviceID != "" {
+		if _, err := uuid.Parse(req.DeviceID); err != nil {
+			return dto.RevokeTokensResponse{}, dto.ErrDeviceNotFound
+		}
+
+		err := s.transactor.WithTransaction(ctx, func(tx *gorm.DB) error {
+			revoked, err := s.deviceRepo.RevokeDevice(ctx, tx, user.ID.String(), req.DeviceID, now)
+			if err != nil {
+				return dto.ErrRevokeDevice
+			}
+			if !revoked {
+				return dto.ErrDeviceNotFound
+			}
+			return recordSessionRevoked(ctx, s.outboxRepo, tx, user.ID, req.DeviceID, now)
+		})
+		if err != nil {
+			return dto.RevokeTokensResponse{}, err
+		}
+		return res, nil
+	}
+
+	err = s.transactor.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := s.userRepo.RevokeSessions(ctx, tx, user.ID.String(), now); err != nil {
+			return dto.ErrUpdateUser
+		}
+		if err := s.deviceRepo.RevokeOtherDevices(ctx, tx, user.ID.String(), "", now); err != nil {
+			return dto.ErrRevokeDevice
+		}
+		return recordSessionRevoked(ctx, s.outboxRepo, tx, user.ID, "", now)
+	})
+	if err != nil {
+		return dto.RevokeTokensResponse{}, err
+	}
+
+	return res, nil
+}
+
+func (s *adminService) ExportTransactions(ctx context.Context, req dto.ExportTransactionsRequest) ([]dto.TransactionExportResponse, error) {
+	from, to, err := exportPeriod(req, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	var transactions dto.GetAllTransactionRepositoryResponse
+	if req.UserID != "" || req.PhoneNumber != "" {
+		user, err := s.findUser(ctx, req.UserLookupRequest)
+		if err != nil {
+			return nil, err
+		}
+		transactions, err = s.userRepo.GetUserTransactionsBetween(ctx, nil, user.ID.String(), from, to)
+		if err != nil {
+			return nil, dto.ErrGetTransactions
+		}
+	} else {
+		transactions, err = s.userRepo.GetTransactionsBetween(ctx, nil, from, to)
+		if err != nil {
+			return nil, dto.ErrGetTransactions
+		}
+	}
+
+	rows := []dto.TransactionExportResponse{}
+	for _, topup := range transactions.TopUps {
+		rows = append(rows, dto.TransactionExportResponse{
+			Type:          constants.ENUM_TRANSACTION_TOPUP,
+			ID:            topup.ID.String(),
+			UserID:        topup.UserID.String(),
+			Currency:      topup.Currency,
+			Amount:        topup.Amount,
+			BalanceBefore: topup.BalanceBefore,
+			BalanceAfter:  topup.BalanceAfter,
+			CreatedAt:     topup.CreatedAt,
+		})
+	}
+
+	for _, payment := range transactions.Payments {
+		rows = append(rows, dto.TransactionExportResponse{
+			Type:          constants.ENUM_TRANSACTION_PAYMENT,
+			ID:            payment.ID.String(),
+			UserID:        payment.UserID.String(),
+			Currency:      payment.Currency,
+			Amount:        payment.Amount,
+			BalanceBefore: payment.BalanceBefore,
+			BalanceAfter:  payment.BalanceAfter,
+			Remarks:       payment.Remarks,
+			CreatedAt:     payment.CreatedAt,
+		})
+	}
+
+	for _, transfer := range transactions.Transfers {
+		targetAmount := transfer.TargetAmount
+		rows = append(rows, dto.TransactionExportResponse{
+			Type:           constants.ENUM_TRANSACTION_TRANSFER,
+			ID:             transfer.ID.String(),
+			UserID:         transfer.UserID.String(),
+			TargetUserID:   transfer.TargetUserID.String(),
+			Currency:       transfer.Currency,
+			Amount:         transfer.Amount,
+			TargetCurrency: transfer.TargetCurrency,
+			TargetAmount:   &targetAmount,
+			BalanceBefore:  transfer.BalanceBefore,
+			BalanceAfter:   transfer.BalanceAfter,
+			Remarks:        transfer.Remarks,
+			CreatedAt:      transfer.CreatedAt,
+		})
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		return rows[i].CreatedAt.Before(rows[j].CreatedAt)
+	})
+
+	return rows, nil
+}
+
+func (s *adminService) findUser(ctx context.Context, req dto.UserLookupRequest) (entity.User, error) {
+	switch {
+	case req.UserID != "":
+		if _, err := uuid.Parse(req.UserID); err != nil {
+			return entity.User{}, dto.ErrUserNotFound
+		}
+		user, err := s.userRepo.FindUserByID(ctx, nil, req.UserID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return entity.User{}, dto.ErrUserNotFound
+		}
+		if err != nil {
+			return entity.User{}, dto.ErrGetUserFromUserID
+		}
+		return user, nil
+	case req.PhoneNumber != "":
+		user, flag, err := s.userRepo.CheckPhoneNumber(ctx, nil, req.PhoneNumber)
+		if errors.Is(err, gorm.ErrRecordNotFound) || err == nil && !flag {
+			return entity.User{}, dto.ErrUserNotFound
+		}
+		if err != nil {
+			return entity.User{}, dto.ErrGetUserFromUserID
+		}
+		return user, nil
+	default:
+		return entity.User{}, dto.ErrUserLookupRequired
+	}
+}
+
+// exportPeriod turns the inclusive dates of the request into a half-open
+// [from, to) range; a missing end means everything up to now.
+func exportPeriod(req dto.ExportTransactionsRequest, now time.Time) (time.Time, time.Time, error) {
+	var from time.Time
+	if req.From != "" {
+		day, err := time.Parse(time.DateOnly, req.From)
+		if err != nil {
+			return time.Time{}, time.Time{}, dto.ErrInvalidExportPeriod
+		}
+		from = day
+	}
+
+	to := now
+	if req.To != "" {
+		day, err := time.Parse(time.DateOnly, req.To)
+		if err != nil {
+			return time.Time{}, time.Time{}, dto.ErrInvalidExportPeriod
+		}
+		to = day.AddDate(0, 0, 1)
+	}
+
+	if !from.Before(to) {
+		return time.Time{}, time.Time{}, dto.ErrInvalidExportPeriod
+	}
+
+	return from, to, nil
+}
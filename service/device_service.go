@@ -0,0 +1,242 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/Amierza/e-wallet/auth"
+	"github.com/Amierza/e-wallet/constants"
+	"github.com/Amierza/e-wallet/dto"
+	"github.com/Amierza/e-wallet/entity"
+	"github.com/Amierza/e-wallet/notify"
+	"github.com/Amierza/e-wallet/repository"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type (
+	DeviceService interface {
+		Register(ctx context.Context, user entity.User, info dto.DeviceInfo) (entity.Device, error)
+		GetAllDevice(ctx context.Context) ([]dto.DeviceResponse, error)
+		RevokeDevice(ctx context.Context, deviceID string) error
+		RevokeOtherDevices(ctx context.Context, userID string, keepDeviceID string) error
+		RequireEstablished(ctx context.Context) error
+	}
+	deviceService struct {
+		deviceRepo repository.DeviceRepository
+		outboxRepo repository.OutboxRepository
+		transactor repository.Transactor
+		notifier   notify.Notifier
+	}
+)
+
+func NewDeviceService(deviceRepo repository.DeviceRepository, outboxRepo repository.OutboxRepository, transactor repository.Transactor, notifier notify.Notifier) DeviceService {
+	return &deviceService{
+		deviceRepo: deviceRepo,
+		outboxRepo: outboxRepo,
+		transactor: transactor,
+		notifier:   notifier,
+	}
+}
+
+// Register binds a successful login to a device. A client that sends back the
+// device ID of an earlier, still active login keeps that device; anything else
+// becomes a new device, and the user is told about it unless it is their
+// first one.
+func (s *deviceService) Register(ctx context.Context, user entity.User, info dto.DeviceInfo) (entity.Device, error) {
+	now := time.Now()
+
+	if info.DeviceID != "" {
+		if _, err := uuid.Parse(info.DeviceID); err == nil {
+			device, found, err := s.deviceRepo.FindDevice(ctx, nil, user.ID.String(), info.DeviceID)
+			if err != nil {
+				return entity.Device{}, dto.ErrGetDevice
+			}
+			if found && device.RevokedAt == nil {
+				applyDeviceInfo(&device, info)
+				device.LastSeenAt = now
+				if err := s.deviceRepo.UpdateDevice(ctx, nil, device); err != nil {
+					return entity.Device{}, dto.ErrCreateDevice
+				}
+				return device, nil
+			}
+		}
+	}
+
+	count, err := s.deviceRepo.CountDevices(ctx, nil, user.ID.String())
+	if err != nil {
+		return entity.Device{}, dto.ErrGetDevice
+	}
+
+	device := entity.Device{
+		ID:         uuid.New(),
+		UserID:     user.ID,
+		LastSeenAt: now,
+		CreatedAt:  now,
+	}
+	applyDeviceInfo(&device, info)
+
+	// The device used to sign in for the very first time is trusted right
+	// away, otherwise a new account could not move money for a day.
+	if count == 0 {
+		device.TrustedAt = &now
+	}
+
+	if err := s.deviceRepo.CreateDevice(ctx, nil, device); err != nil {
+		return entity.Device{}, dto.ErrCreateDevice
+	}
+
+	if count > 0 {
+		s.notifyNewDevice(ctx, user, device)
+	}
+
+	return device, nil
+}
+
+func applyDeviceInfo(device *entity.Device, info dto.DeviceInfo) {
+	if info.DeviceName != "" {
+		device.Name = info.DeviceName
+	}
+	if info.Platform != "" {
+		device.Platform = info.Platform
+	}
+	if info.PushToken != "" {
+		device.PushToken = info.PushToken
+	}
+	device.IPAddress = info.IPAddress
+	device.UserAgent = info.UserAgent
+}
+
+// notifyNewDevice is best effort: failing to deliver the alert must not fail
+// the login that triggered it.
+func (s *deviceService) notifyNewDevice(ctx context.Context, user entity.User, device entity.Device) {
+	msg := notify.Message{
+		Channel: notify.ChannelSMS,
+		To:      user.PhoneNumber,
+		Subject: "New sign-in to your e-wallet",
+		Body: fmt.Sprintf("Your e-wallet was signed in on a new device (%s) from %s at %s. If this was not you, sign that device out and change your PIN.",
+			deviceLabel(device), device.IPAddress, device.CreatedAt.Format(time.RFC1123)),
+	}
+	if user.Email != nil && user.EmailVerifiedAt != nil {
+		msg.Channel = notify.ChannelEmail
+		msg.To = *user.Email
+	}
+
+	if err := s.notifier.Send(ctx, msg); err != nil {
+		slog.WarnContext(ctx, "failed to send new device notification", slog.String("user_id", user.ID.String()), slog.Any("error", err))
+	}
+}
+
+func deviceLabel(device entity.Device) string {
+	switch {
+	case device.Name != "" && device.Platform != "":
+		return device.Name + ", " + device.Platform
+	case device.Name != "":
+		return device.Name
+	case device.Platform != "":
+		return device.Platform
+	case device.UserAgent != "":
+		return device.UserAgent
+	default:
+		return "unknown device"
+	}
+}
+
+func (s *deviceService) GetAllDevice(ctx context.Context) ([]dto.DeviceResponse, error) {
+	principal, ok := auth.FromContext(ctx)
+	if !ok {
+		return nil, dto.ErrGetUserFromToken
+	}
+
+	devices, err := s.deviceRepo.GetActiveDevices(ctx, nil, principal.UserID)
+	if err != nil {
+		return nil, dto.ErrGetDevice
+	}
+
+	now := time.Now()
+	datas := make([]dto.DeviceResponse, 0, len(devices))
+	for _, device := range devices {
+		trustedFrom := deviceTrustedFrom(device)
+		datas = append(datas, dto.DeviceResponse{
+			ID:          device.ID,
+			Name:        device.Name,
+			Platform:    device.Platform,
+			IPAddress:   device.IPAddress,
+			UserAgent:   device.UserAgent,
+			Current:     device.ID.String() == principal.DeviceID,
+			Trusted:     !now.Before(trustedFrom),
+			TrustedFrom: trustedFrom,
+			LastSeenAt:  device.LastSeenAt,
+			CreatedAt:   device.CreatedAt,
+		})
+	}
+
+	return datas, nil
+}
+
+func (s *deviceService) RevokeDevice(ctx context.Context, deviceID string) error {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return dto.ErrGetUserFromToken
+	}
+
+	if _, err := uuid.Parse(deviceID); err != nil {
+		return dto.ErrDeviceNotFound
+	}
+
+	now := time.Now()
+	return s.transactor.WithTransaction(ctx, func(tx *gorm.DB) error {
+		revoked, err := s.deviceRepo.RevokeDevice(ctx, tx, userID, deviceID, now)
+		if err != nil {
+			return dto.ErrRevokeDevice
+		}
+		if !revoked {
+			return dto.ErrDeviceNotFound
+		}
+
+		return recordSessionRevoked(ctx, s.outboxRepo, tx, uuid.MustParse(userID), deviceID, now)
+	})
+}
+
+func (s *deviceService) RevokeOtherDevices(ctx context.Context, userID string, keepDeviceID string) error {
+	if err := s.deviceRepo.RevokeOtherDevices(ctx, nil, userID, keepDeviceID, time.Now()); err != nil {
+		return dto.ErrRevokeDevice
+	}
+
+	return nil
+}
+
+// RequireEstablished guards sensitive operations: the caller's device must
+// have been trusted from the start or be older than a day.
+func (s *deviceService) RequireEstablished(ctx context.Context) error {
+	principal, ok := auth.FromContext(ctx)
+	if !ok {
+		return dto.ErrGetUserFromToken
+	}
+	if principal.DeviceID == "" {
+		return dto.ErrDeviceRequired
+	}
+
+	device, found, err := s.deviceRepo.FindDevice(ctx, nil, principal.UserID, principal.DeviceID)
+	if err != nil {
+		return dto.ErrGetDevice
+	}
+	if !found || device.RevokedAt != nil {
+		return dto.ErrDeviceRevoked
+	}
+
+	if time.Now().Before(deviceTrustedFrom(device)) {
+		return dto.ErrDeviceNotEstablished
+	}
+
+	return nil
+}
+
+func deviceTrustedFrom(device entity.Device) time.Time {
+	if device.TrustedAt != nil {
+		return *device.TrustedAt
+	}
+	return device.CreatedAt.Add(constants.ENUM_DEVICE_TRUST_SECOND * time.Second)
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Amierza/e-wallet/auth"
+	"github.com/Amierza/e-wallet/constants"
+	"github.com/Amierza/e-wallet/dto"
+	"github.com/Amierza/e-wallet/entity"
+	"github.com/Amierza/e-wallet/repository"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type fakeDeviceRepo struct {
+	repository.DeviceRepository
+
+	devices map[string]entity.Device
+}
+
+func (r *fakeDeviceRepo) CreateDevice(ctx context.Context, tx *gorm.DB, device entity.Device) error {
+	r.devices[device.ID.String()] = device
+	return nil
+}
+
+func (r *fakeDeviceRepo) UpdateDevice(ctx context.Context, tx *gorm.DB, device entity.Device) error {
+	r.devices[device.ID.String()] = device
+	return nil
+}
+
+func (r *fakeDeviceRepo) FindDevice(ctx context.Context, tx *gorm.DB, userID string, deviceID string) (entity.Device, bool, error) {
+	device, ok := r.devices[deviceID]
+	if !ok || device.UserID.String() != userID {
+		return entity.Device{}, false, nil
+	}
+	return device, true, nil
+}
+
+func (r *fakeDeviceRepo) CountDevices(ctx context.Context, tx *gorm.DB, userID string) (int64, error) {
+	var count int64
+	for _, device := range r.devices {
+		if device.UserID.String() == userID {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *fakeDeviceRepo) RevokeDevice(ctx context.Context, tx *gorm.DB, userID string, deviceID string, now time.Time) (bool, error) {
+	device, found, _ := r.FindDevice(ctx, tx, userID, deviceID)
+	if !found || device.RevokedAt != nil {
+		return false, nil
+	}
+	device.RevokedAt = &now
+	r.devices[deviceID] = device
+	return true, nil
+}
+
+func TestDeviceTrust(t *testing.T) {
+	user := entity.User{ID: uuid.New(), PhoneNumber: "+6281234567890"}
+	repo := &fakeDeviceRepo{devices: map[string]entity.Device{}}
+	outbox := &fakeOutboxRepo{}
+	notifier := &capturingNotifier{}
+	svc := NewDeviceService(repo, outbox, fakeTransactor{}, notifier)
+
+	signedIn := func(device entity.Device) context.Context {
+		return auth.WithPrincipal(context.Background(), auth.Principal{UserID: user.ID.String(), DeviceID: device.ID.String()})
+	}
+	register := func(info dto.DeviceInfo) entity.Device {
+		t.Helper()
+		device, err := svc.Register(context.Background(), user, info)
+		if err != nil {
+			t.Fatalf("Register() = %v", err)
+		}
+		return device
+	}
+
+	phone := register(dto.DeviceInfo{DeviceName: "Pixel"})
+	if err := svc.RequireEstablished(signedIn(phone)); err != nil {
+		t.Errorf("first device: RequireEstablished() = %v, want trusted at once", err)
+	}
+	if len(notifier.sent) != 0 {
+		t.Errorf("first device sent %d alerts, want none", len(notifier.sent))
+	}
+
+	laptop := register(dto.DeviceInfo{DeviceName: "Laptop", Platform: "web"})
+	if err := svc.RequireEstablished(signedIn(laptop)); !errors.Is(err, dto.ErrDeviceNotEstablished) {
+		t.Errorf("new device: RequireEstablished() = %v, want ErrDeviceNotEstablished", err)
+	}
+	if len(notifier.sent) != 1 || notifier.sent[0].To != user.PhoneNumber {
+		t.Errorf("new device alerts = %+v, want one to the user's phone", notifier.sent)
+	}
+
+	again := register(dto.DeviceInfo{DeviceID: laptop.ID.String()})
+	if again.ID != laptop.ID || len(repo.devices) != 2 || len(notifier.sent) != 1 {
+		t.Errorf("signing in again with the device ID made a new device or alert")
+	}
+	if again.Name != "Laptop" {
+		t.Errorf("device name = %q, want it kept when the client sends none", again.Name)
+	}
+
+	laptop.CreatedAt = laptop.CreatedAt.Add(-constants.ENUM_DEVICE_TRUST_SECOND * time.Second)
+	repo.devices[laptop.ID.String()] = laptop
+	if err := svc.RequireEstablished(signedIn(laptop)); err != nil {
+		t.Errorf("day-old device: RequireEstablished() = %v", err)
+	}
+
+	if err := svc.RevokeDevice(signedIn(phone), laptop.ID.String()); err != nil {
+		t.Fatalf("RevokeDevice() = %v", err)
+	}
+	if err := svc.RequireEstablished(signedIn(laptop)); !errors.Is(err, dto.ErrDeviceRevoked) {
+		t.Errorf("revoked device: RequireEstablished() = %v, want ErrDeviceRevoked", err)
+	}
+	if !slices.Equal(outbox.eventTypes(), []string{constants.ENUM_EVENT_SESSION_REVOKED}) {
+		t.Errorf("events = %v, want the session revocation recorded", outbox.eventTypes())
+	}
+
+	revived := register(dto.DeviceInfo{DeviceID: laptop.ID.String()})
+	if revived.ID == laptop.ID || revived.TrustedAt != nil {
+		t.Error("a revoked device ID was reused instead of starting an untrusted device")
+	}
+
+	if err := svc.RequireEstablished(auth.WithPrincipal(context.Background(), auth.Principal{UserID: user.ID.String()})); !errors.Is(err, dto.ErrDeviceRequired) {
+		t.Errorf("token without a device: RequireEstablished() = %v, want ErrDeviceRequired", err)
+	}
+}
@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/Amierza/e-wallet/constants"
+	"github.com/Amierza/e-wallet/entity"
+	"github.com/Amierza/e-wallet/repository"
+	"gorm.io/gorm"
+)
+
+// The fakes keep state in memory and implement only the repository methods
+// the tests reach; the embedded interface is nil, so any other call panics
+// and shows the test is exercising more than it set up.
+
+// fakeTransactor runs fn directly. It cannot roll back, so tests check that a
+// failing path stops before its first write instead.
+type fakeTransactor struct{}
+
+func (fakeTransactor) WithTransaction(ctx context.Context, fn func(tx *gorm.DB) error) error {
+	return fn(nil)
+}
+
+type fakeUserRepo struct {
+	repository.UserRepository
+
+	users     map[string]entity.User
+	wallets   map[string]entity.Wallet
+	transfers []entity.Transfer
+}
+
+func newFakeUserRepo(users ...entity.User) *fakeUserRepo {
+	repo := &fakeUserRepo{users: map[string]entity.User{}, wallets: map[string]entity.Wallet{}}
+	for _, user := range users {
+		repo.users[user.ID.String()] = user
+		for _, wallet := range user.Wallets {
+			repo.wallets[user.ID.String()+wallet.Currency] = wallet
+		}
+	}
+	return repo
+}
+
+func (r *fakeUserRepo) FindUserByID(ctx context.Context, tx *gorm.DB, userID string) (entity.User, error) {
+	user, ok := r.users[userID]
+	if !ok {
+		return entity.User{}, gorm.ErrRecordNotFound
+	}
+	return user, nil
+}
+
+func (r *fakeUserRepo) CheckTargetUser(ctx context.Context, tx *gorm.DB, userID string) (entity.User, bool, error) {
+	user, err := r.FindUserByID(ctx, tx, userID)
+	return user, err == nil, err
+}
+
+func (r *fakeUserRepo) FindWallet(ctx context.Context, tx *gorm.DB, userID string, currency string) (entity.Wallet, bool, error) {
+	wallet, ok := r.wallets[userID+currency]
+	if !ok {
+		return entity.Wallet{}, false, gorm.ErrRecordNotFound
+	}
+	return wallet, true, nil
+}
+
+func (r *fakeUserRepo) UpdateWallet(ctx context.Context, tx *gorm.DB, wallet entity.Wallet) error {
+	r.wallets[wallet.UserID.String()+wallet.Currency] = wallet
+	return nil
+}
+
+func (r *fakeUserRepo) CreateTransfer(ctx context.Context, tx *gorm.DB, transfer entity.Transfer) error {
+	r.transfers = append(r.transfers, transfer)
+	return nil
+}
+
+type fakeFXRepo struct {
+	repository.FXRepository
+
+	rates    []entity.FXRate
+	quotes   map[string]entity.FXQuote
+	revenues []entity.FXRevenue
+}
+
+func (r *fakeFXRepo) FindRate(ctx context.Context, tx *gorm.DB, baseCurrency, quoteCurrency string) (entity.FXRate, bool, error) {
+	for _, rate := range r.rates {
+		if rate.BaseCurrency == baseCurrency && rate.QuoteCurrency == quoteCurrency {
+			return rate, true, nil
+		}
+	}
+	return entity.FXRate{}, false, gorm.ErrRecordNotFound
+}
+
+func (r *fakeFXRepo) CreateRateIfMissing(ctx context.Context, tx *gorm.DB, rate entity.FXRate) (bool, error) {
+	if _, ok, _ := r.FindRate(ctx, tx, rate.BaseCurrency, rate.QuoteCurrency); ok {
+		return false, nil
+	}
+	r.rates = append(r.rates, rate)
+	return true, nil
+}
+
+func (r *fakeFXRepo) CreateQuote(ctx context.Context, tx *gorm.DB, quote entity.FXQuote) error {
+	if r.quotes == nil {
+		r.quotes = map[string]entity.FXQuote{}
+	}
+	r.quotes[quote.ID.String()] = quote
+	return nil
+}
+
+func (r *fakeFXRepo) FindQuoteByID(ctx context.Context, tx *gorm.DB, quoteID string) (entity.FXQuote, error) {
+	quote, ok := r.quotes[quoteID]
+	if !ok {
+		return entity.FXQuote{}, gorm.ErrRecordNotFound
+	}
+	return quote, nil
+}
+
+func (r *fakeFXRepo) MarkQuoteUsed(ctx context.Context, tx *gorm.DB, quoteID string, usedAt time.Time) (bool, error) {
+	quote, ok := r.quotes[quoteID]
+	if !ok || quote.UsedAt != nil || !quote.ExpiresAt.After(usedAt) {
+		return false, nil
+	}
+	quote.UsedAt = &usedAt
+	r.quotes[quoteID] = quote
+	return true, nil
+}
+
+func (r *fakeFXRepo) CreateRevenue(ctx context.Context, tx *gorm.DB, revenue entity.FXRevenue) error {
+	r.revenues = append(r.revenues, revenue)
+	return nil
+}
+
+type fakeOutboxRepo struct {
+	repository.OutboxRepository
+
+	events []entity.OutboxEvent
+}
+
+// CreateEvent numbers events in insertion order, like the sequence column.
+func (r *fakeOutboxRepo) CreateEvent(ctx context.Context, tx *gorm.DB, event entity.OutboxEvent) error {
+	event.Sequence = int64(len(r.events) + 1)
+	r.events = append(r.events, event)
+	return nil
+}
+
+// ClaimPendingEvents claims like the real query: due events that have no
+// older pending event of the same aggregate, oldest first, leased for lease.
+func (r *fakeOutboxRepo) ClaimPendingEvents(ctx context.Context, tx *gorm.DB, now time.Time, lease time.Duration, limit int) ([]entity.OutboxEvent, error) {
+	blocked := map[string]bool{}
+	var claimed []entity.OutboxEvent
+	for i, event := range r.events {
+		if event.Status != constants.ENUM_OUTBOX_STATUS_PENDING {
+			continue
+		}
+		aggregate := event.AggregateID.String()
+		if !blocked[aggregate] && !event.NextAttemptAt.After(now) && len(claimed) < limit {
+			r.events[i].NextAttemptAt = now.Add(lease)
+			claimed = append(claimed, event)
+		}
+		blocked[aggregate] = true
+	}
+	return claimed, nil
+}
+
+func (r *fakeOutboxRepo) UpdateEvent(ctx context.Context, tx *gorm.DB, event entity.OutboxEvent) error {
+	for i := range r.events {
+		if r.events[i].ID == event.ID {
+			r.events[i] = event
+		}
+	}
+	return nil
+}
+
+func (r *fakeOutboxRepo) eventTypes() []string {
+	var types []string
+	for _, event := range r.events {
+		types = append(types, event.EventType)
+	}
+	return types
+}
@@ -0,0 +1,270 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"math/big"
+	"os"
+	"time"
+
+	"github.com/Amierza/e-wallet/auth"
+	"github.com/Amierza/e-wallet/constants"
+	"github.com/Amierza/e-wallet/dto"
+	"github.com/Amierza/e-wallet/entity"
+	"github.com/Amierza/e-wallet/money"
+	"github.com/Amierza/e-wallet/repository"
+	"github.com/google/uuid"
+)
+
+type (
+	FXService interface {
+		GetAllRates(ctx context.Context) ([]dto.FXRateResponse, error)
+		UpsertRates(ctx context.Context, req dto.FXRateUpsertRequest) ([]dto.FXRateResponse, error)
+		SeedRatesFromFile(ctx context.Context, path string) (int, error)
+		CreateQuote(ctx context.Context, req dto.FXQuoteRequest) (dto.FXQuoteResponse, error)
+		GetRevenueSummary(ctx context.Context) ([]dto.FXRevenueResponse, error)
+	}
+	fxService struct {
+		fxRepo repository.FXRepository
+	}
+)
+
+const (
+	fxRateScale   = 12
+	fxBpsDivisor  = 10000
+	fxMaxSpreadBp = 10000
+)
+
+func NewFXService(fxRepo repository.FXRepository) FXService {
+	return &fxService{
+		fxRepo: fxRepo,
+	}
+}
+
+func (s *fxService) GetAllRates(ctx context.Context) ([]dto.FXRateResponse, error) {
+	rates, err := s.fxRepo.GetAllRates(ctx, nil)
+	if err != nil {
+		return nil, dto.ErrGetFXRates
+	}
+
+	var datas []dto.FXRateResponse
+	for _, rate := range rates {
+		datas = append(datas, dto.FXRateResponse{
+			ID:            rate.ID.String(),
+			BaseCurrency:  rate.BaseCurrency,
+			QuoteCurrency: rate.QuoteCurrency,
+			Rate:          rate.Rate,
+			SpreadBps:     rate.SpreadBps,
+			UpdatedAt:     rate.UpdatedAt,
+		})
+	}
+
+	return datas, nil
+}
+
+func (s *fxService) UpsertRates(ctx context.Context, req dto.FXRateUpsertRequest) ([]dto.FXRateResponse, error) {
+	rates, err := newFXRates(req)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, rate := range rates {
+		if err := s.fxRepo.UpsertRate(ctx, nil, rate); err != nil {
+			return nil, dto.ErrUpdateFXRate
+		}
+	}
+
+	return s.GetAllRates(ctx)
+}
+
+// SeedRatesFromFile stores the rates of pairs that have none yet and returns
+// how many it added. Existing rates are left alone, so a restart never
+// reverts a rate changed through the admin endpoint.
+func (s *fxService) SeedRatesFromFile(ctx context.Context, path string) (int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return 0, dto.ErrReadFXRateFile
+	}
+
+	var req dto.FXRateUpsertRequest
+	if err := json.Unmarshal(data, &req.Rates); err != nil {
+		return 0, dto.ErrReadFXRateFile
+	}
+
+	rates, err := newFXRates(req)
+	if err != nil {
+		return 0, err
+	}
+
+	created := 0
+	for _, rate := range rates {
+		ok, err := s.fxRepo.CreateRateIfMissing(ctx, nil, rate)
+		if err != nil {
+			return created, dto.ErrUpdateFXRate
+		}
+		if ok {
+			created++
+		}
+	}
+
+	return created, nil
+}
+
+func newFXRates(req dto.FXRateUpsertRequest) ([]entity.FXRate, error) {
+	var rates []entity.FXRate
+	for _, r := range req.Rates {
+		base := money.NormalizeCurrency(r.BaseCurrency)
+		quote := money.NormalizeCurrency(r.QuoteCurrency)
+		if !money.IsSupported(base) || !money.IsSupported(quote) {
+			return nil, dto.ErrUnsupportedCurrency
+		}
+
+		if base == quote {
+			return nil, dto.ErrSameCurrencyPair
+		}
+
+		rate, ok := parseFXRate(r.Rate)
+		if !ok {
+			return nil, dto.ErrInvalidFXRate
+		}
+
+		if r.SpreadBps < 0 || r.SpreadBps >= fxMaxSpreadBp {
+			return nil, dto.ErrInvalidFXSpread
+		}
+
+		rates = append(rates, entity.FXRate{
+			ID:            uuid.New(),
+			BaseCurrency:  base,
+			QuoteCurrency: quote,
+			Rate:          rate.FloatString(fxRateScale),
+			SpreadBps:     r.SpreadBps,
+		})
+	}
+
+	return rates, nil
+}
+
+func (s *fxService) CreateQuote(ctx context.Context, req dto.FXQuoteRequest) (dto.FXQuoteResponse, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return dto.FXQuoteResponse{}, dto.ErrGetUserFromToken
+	}
+
+	if !req.Amount.IsPositive() {
+		return dto.FXQuoteResponse{}, dto.ErrInvalidAmount
+	}
+
+	from := req.Amount.Currency
+	to := money.NormalizeCurrency(req.ToCurrency)
+	if !money.IsSupported(from) || !money.IsSupported(to) {
+		return dto.FXQuoteResponse{}, dto.ErrUnsupportedCurrency
+	}
+
+	if from == to {
+		return dto.FXQuoteResponse{}, dto.ErrSameCurrencyPair
+	}
+
+	midRate, spreadBps, err := s.findRate(ctx, from, to)
+	if err != nil {
+		return dto.FXQuoteResponse{}, err
+	}
+
+	rate := new(big.Rat).Mul(midRate, big.NewRat(fxBpsDivisor-spreadBps, fxBpsDivisor))
+
+	midAmount, err := req.Amount.Convert(to, midRate)
+	if err != nil {
+		return dto.FXQuoteResponse{}, dto.ErrCreateFXQuote
+	}
+
+	targetAmount, err := req.Amount.Convert(to, rate)
+	if err != nil {
+		return dto.FXQuoteResponse{}, dto.ErrCreateFXQuote
+	}
+
+	if !targetAmount.IsPositive() {
+		return dto.FXQuoteResponse{}, dto.ErrFXAmountTooSmall
+	}
+
+	spreadAmount, err := midAmount.Sub(targetAmount)
+	if err != nil {
+		return dto.FXQuoteResponse{}, dto.ErrCreateFXQuote
+	}
+
+	quote := entity.FXQuote{
+		ID:           uuid.New(),
+		UserID:       uuid.MustParse(userID),
+		FromCurrency: from,
+		ToCurrency:   to,
+		MidRate:      midRate.FloatString(fxRateScale),
+		Rate:         rate.FloatString(fxRateScale),
+		SpreadBps:    spreadBps,
+		SourceAmount: req.Amount,
+		TargetAmount: targetAmount,
+		SpreadAmount: spreadAmount,
+		ExpiresAt:    time.Now().Add(constants.ENUM_FX_QUOTE_TTL_SECOND * time.Second),
+	}
+
+	if err := s.fxRepo.CreateQuote(ctx, nil, quote); err != nil {
+		return dto.FXQuoteResponse{}, dto.ErrCreateFXQuote
+	}
+
+	return dto.FXQuoteResponse{
+		ID:           quote.ID.String(),
+		FromCurrency: quote.FromCurrency,
+		ToCurrency:   quote.ToCurrency,
+		MidRate:      quote.MidRate,
+		Rate:         quote.Rate,
+		SpreadBps:    quote.SpreadBps,
+		SourceAmount: quote.SourceAmount,
+		TargetAmount: quote.TargetAmount,
+		ExpiresAt:    quote.ExpiresAt,
+	}, nil
+}
+
+func (s *fxService) GetRevenueSummary(ctx context.Context) ([]dto.FXRevenueResponse, error) {
+	summary, err := s.fxRepo.GetRevenueSummary(ctx, nil)
+	if err != nil {
+		return nil, dto.ErrGetFXRevenue
+	}
+
+	return summary, nil
+}
+
+// findRate looks up the pair as stored and falls back to inverting the
+// opposite pair, so a single USD/IDR row also prices IDR to USD.
+func (s *fxService) findRate(ctx context.Context, from, to string) (*big.Rat, int64, error) {
+	if rate, flag, err := s.fxRepo.FindRate(ctx, nil, from, to); err == nil && flag {
+		mid, ok := parseFXRate(rate.Rate)
+		if !ok {
+			return nil, 0, dto.ErrInvalidFXRate
+		}
+		return mid, rate.SpreadBps, nil
+	}
+
+	rate, flag, err := s.fxRepo.FindRate(ctx, nil, to, from)
+	if err != nil || !flag {
+		return nil, 0, dto.ErrFXRateNotFound
+	}
+
+	mid, ok := parseFXRate(rate.Rate)
+	if !ok {
+		return nil, 0, dto.ErrInvalidFXRate
+	}
+
+	return new(big.Rat).Inv(mid), rate.SpreadBps, nil
+}
+
+func parseFXRate(rate string) (*big.Rat, bool) {
+	r, ok := new(big.Rat).SetString(rate)
+	if !ok || r.Sign() <= 0 {
+		return nil, false
+	}
+	return r, true
+}
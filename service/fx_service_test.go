@@ -0,0 +1,200 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Amierza/e-wallet/auth"
+	"github.com/Amierza/e-wallet/constants"
+	"github.com/Amierza/e-wallet/dto"
+	"github.com/Amierza/e-wallet/entity"
+	"github.com/Amierza/e-wallet/metrics"
+	"github.com/Amierza/e-wallet/money"
+	"github.com/Amierza/e-wallet/repository"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func amount(t *testing.T, value string, currency string) money.Amount {
+	t.Helper()
+	a, err := money.Parse(value, currency)
+	if err != nil {
+		t.Fatalf("money.Parse(%q, %q): %v", value, currency, err)
+	}
+	return a
+}
+
+func TestCreateQuote(t *testing.T) {
+	userID := uuid.New()
+	ctx := auth.WithPrincipal(context.Background(), auth.Principal{UserID: userID.String()})
+	fxRepo := &fakeFXRepo{rates: []entity.FXRate{
+		{BaseCurrency: "USD", QuoteCurrency: "IDR", Rate: "15500", SpreadBps: 100},
+	}}
+	fx := NewFXService(fxRepo)
+
+	quote, err := fx.CreateQuote(ctx, dto.FXQuoteRequest{Amount: amount(t, "10", "USD"), ToCurrency: "idr"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if quote.TargetAmount != amount(t, "153450", "IDR") {
+		t.Errorf("USD to IDR target = %v, want 153450.00 IDR after a 1%% spread", quote.TargetAmount)
+	}
+	stored := fxRepo.quotes[quote.ID]
+	if stored.UserID != userID || stored.SpreadAmount != amount(t, "1550", "IDR") {
+		t.Errorf("stored quote = %+v, want it owned by the caller with a 1550.00 IDR spread", stored)
+	}
+
+	// The opposite direction is priced from the same row, inverted.
+	quote, err = fx.CreateQuote(ctx, dto.FXQuoteRequest{Amount: amount(t, "155000", "IDR"), ToCurrency: "USD"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if quote.TargetAmount != amount(t, "9.90", "USD") {
+		t.Errorf("IDR to USD target = %v, want 9.90 USD", quote.TargetAmount)
+	}
+
+	// Rounding down can leave nothing to pay out.
+	if _, err := fx.CreateQuote(ctx, dto.FXQuoteRequest{Amount: amount(t, "1", "IDR"), ToCurrency: "USD"}); !errors.Is(err, dto.ErrFXAmountTooSmall) {
+		t.Errorf("CreateQuote(1 IDR) err = %v, want ErrFXAmountTooSmall", err)
+	}
+	if _, err := fx.CreateQuote(ctx, dto.FXQuoteRequest{Amount: amount(t, "1", "USD"), ToCurrency: "EUR"}); !errors.Is(err, dto.ErrFXRateNotFound) {
+		t.Errorf("CreateQuote(no rate) err = %v, want ErrFXRateNotFound", err)
+	}
+}
+
+func TestSeedRatesFromFileKeepsStoredRates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fx_rates.json")
+	rates := `[
+		{"base_currency": "USD", "quote_currency": "IDR", "rate": "15000", "spread_bps": 50},
+		{"base_currency": "EUR", "quote_currency": "IDR", "rate": "17000", "spread_bps": 50}
+	]`
+	if err := os.WriteFile(path, []byte(rates), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	fxRepo := &fakeFXRepo{rates: []entity.FXRate{
+		{BaseCurrency: "USD", QuoteCurrency: "IDR", Rate: "15500", SpreadBps: 100},
+	}}
+
+	created, err := NewFXService(fxRepo).SeedRatesFromFile(context.Background(), path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created != 1 {
+		t.Errorf("SeedRatesFromFile() created %d rates, want only the missing EUR/IDR", created)
+	}
+	if rate, _, _ := fxRepo.FindRate(context.Background(), nil, "USD", "IDR"); rate.Rate != "15500" || rate.SpreadBps != 100 {
+		t.Errorf("USD/IDR = %s at %d bps, want the stored 15500 at 100 bps kept", rate.Rate, rate.SpreadBps)
+	}
+}
+
+// racingFXRepo loses the race for the quote: it was still unused when read,
+// but another transfer marks it used first.
+type racingFXRepo struct {
+	*fakeFXRepo
+}
+
+func (r racingFXRepo) MarkQuoteUsed(ctx context.Context, tx *gorm.DB, quoteID string, usedAt time.Time) (bool, error) {
+	return false, nil
+}
+
+func TestTransferWithQuote(t *testing.T) {
+	sender, receiver, other := uuid.New(), uuid.New(), uuid.New()
+
+	setup := func(t *testing.T, edit func(*entity.FXQuote)) (*fakeUserRepo, *fakeFXRepo, *fakeOutboxRepo, uuid.UUID) {
+		t.Helper()
+		userRepo := newFakeUserRepo(
+			entity.User{ID: sender, Wallets: []entity.Wallet{{UserID: sender, Currency: "USD", Balance: amount(t, "100", "USD")}}},
+			entity.User{ID: receiver, Wallets: []entity.Wallet{{UserID: receiver, Currency: "IDR", Balance: amount(t, "0", "IDR")}}},
+		)
+		quote := entity.FXQuote{
+			ID:           uuid.New(),
+			UserID:       sender,
+			FromCurrency: "USD",
+			ToCurrency:   "IDR",
+			SourceAmount: amount(t, "10", "USD"),
+			TargetAmount: amount(t, "153450", "IDR"),
+			SpreadAmount: amount(t, "1550", "IDR"),
+			ExpiresAt:    time.Now().Add(time.Minute),
+		}
+		if edit != nil {
+			edit(&quote)
+		}
+		fxRepo := &fakeFXRepo{quotes: map[string]entity.FXQuote{quote.ID.String(): quote}}
+		return userRepo, fxRepo, &fakeOutboxRepo{}, quote.ID
+	}
+	transfer := func(userRepo *fakeUserRepo, fxRepo repository.FXRepository, outboxRepo *fakeOutboxRepo, quoteID uuid.UUID, sent money.Amount) (dto.TransferResponse, error) {
+		s := &userService{userRepo: userRepo, fxRepo: fxRepo, outboxRepo: outboxRepo, transactor: fakeTransactor{}, metrics: metrics.Nop{}}
+		ctx := auth.WithPrincipal(context.Background(), auth.Principal{UserID: sender.String()})
+		return s.TransferUser(ctx, dto.TransferRequest{TargetUser: receiver, Amount: sent, QuoteID: &quoteID})
+	}
+
+	t.Run("credits the quoted amount and books the spread", func(t *testing.T) {
+		userRepo, fxRepo, outboxRepo, quoteID := setup(t, nil)
+
+		res, err := transfer(userRepo, fxRepo, outboxRepo, quoteID, amount(t, "10", "USD"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.TargetAmount != amount(t, "153450", "IDR") || res.QuoteID != quoteID.String() {
+			t.Errorf("response = %+v, want 153450.00 IDR under quote %s", res, quoteID)
+		}
+		if got := userRepo.wallets[sender.String()+"USD"].Balance; got != amount(t, "90", "USD") {
+			t.Errorf("sender balance = %v, want 90.00 USD", got)
+		}
+		if got := userRepo.wallets[receiver.String()+"IDR"].Balance; got != amount(t, "153450", "IDR") {
+			t.Errorf("receiver balance = %v, want 153450.00 IDR", got)
+		}
+		if fxRepo.quotes[quoteID.String()].UsedAt == nil {
+			t.Error("quote was not marked used")
+		}
+		if len(fxRepo.revenues) != 1 || fxRepo.revenues[0].Amount != amount(t, "1550", "IDR") {
+			t.Errorf("revenues = %+v, want the 1550.00 IDR spread once", fxRepo.revenues)
+		}
+		want := []string{constants.ENUM_EVENT_TRANSFER_COMPLETED, constants.ENUM_EVENT_TRANSFER_RECEIVED}
+		if got := outboxRepo.eventTypes(); !slices.Equal(got, want) {
+			t.Errorf("events = %v, want %v", got, want)
+		}
+
+		if _, err := transfer(userRepo, fxRepo, outboxRepo, quoteID, amount(t, "10", "USD")); !errors.Is(err, dto.ErrFXQuoteAlreadyUsed) {
+			t.Errorf("second transfer with the quote err = %v, want ErrFXQuoteAlreadyUsed", err)
+		}
+	})
+
+	refused := []struct {
+		name    string
+		edit    func(*entity.FXQuote)
+		sent    string
+		racing  bool
+		wantErr error
+	}{
+		{name: "expired", edit: func(q *entity.FXQuote) { q.ExpiresAt = time.Now().Add(-time.Second) }, sent: "10", wantErr: dto.ErrFXQuoteExpired},
+		{name: "another user's quote", edit: func(q *entity.FXQuote) { q.UserID = other }, sent: "10", wantErr: dto.ErrFXQuoteNotFound},
+		{name: "different amount", sent: "11", wantErr: dto.ErrFXQuoteMismatch},
+		{name: "used by a concurrent transfer", sent: "10", racing: true, wantErr: dto.ErrFXQuoteAlreadyUsed},
+	}
+	for _, tt := range refused {
+		t.Run(tt.name, func(t *testing.T) {
+			userRepo, fxRepo, outboxRepo, quoteID := setup(t, tt.edit)
+
+			var repo repository.FXRepository = fxRepo
+			if tt.racing {
+				repo = racingFXRepo{fxRepo}
+			}
+			if _, err := transfer(userRepo, repo, outboxRepo, quoteID, amount(t, tt.sent, "USD")); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if got := userRepo.wallets[sender.String()+"USD"].Balance; got != amount(t, "100", "USD") {
+				t.Errorf("sender balance = %v, want it untouched", got)
+			}
+			if len(userRepo.transfers) != 0 || len(fxRepo.revenues) != 0 || len(outboxRepo.events) != 0 {
+				t.Error("a refused transfer wrote a transfer, revenue or event")
+			}
+		})
+	}
+}
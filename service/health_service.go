@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Amierza/e-wallet/buildinfo"
+	"github.com/Amierza/e-wallet/constants"
+	"github.com/Amierza/e-wallet/dto"
+	"github.com/Amierza/e-wallet/migrations"
+)
+
+type (
+	HealthService interface {
+		// Ready runs every readiness check and reports whether all passed.
+		Ready(ctx context.Context) (dto.ReadinessResponse, bool)
+		Version() dto.VersionResponse
+		// Track wraps a background worker so readiness fails while it is not
+		// running, including before it starts and after it returns.
+		Track(name string, worker func(context.Context)) func(context.Context)
+		// Drain makes readiness fail from now on, so traffic moves away
+		// before the server stops accepting connections.
+		Drain()
+	}
+	healthService struct {
+		db       *sql.DB
+		migrator *migrations.Migrator
+		draining atomic.Bool
+
+		mu      sync.Mutex
+		workers map[string]bool
+	}
+)
+
+func NewHealthService(db *sql.DB, migrator *migrations.Migrator) HealthService {
+	return &healthService{
+		db:       db,
+		migrator: migrator,
+		workers:  make(map[string]bool),
+	}
+}
+
+func (s *healthService) Ready(ctx context.Context) (dto.ReadinessResponse, bool) {
+	checks := map[string]func(context.Context) error{
+		"shutdown":   s.checkShutdown,
+		"database":   s.checkDatabase,
+		"migrations": s.checkMigrations,
+	}
+	s.mu.Lock()
+	for name := range s.workers {
+		checks["worker:"+name] = s.checkWorker(name)
+	}
+	s.mu.Unlock()
+
+	res := dto.ReadinessResponse{
+		Status: constants.ENUM_HEALTH_STATUS_OK,
+		Checks: make(map[string]dto.HealthCheckResponse, len(checks)),
+	}
+
+	var (
+		mu      sync.Mutex
+		running sync.WaitGroup
+	)
+	for name, check := range checks {
+		running.Add(1)
+		go func() {
+			defer running.Done()
+			result := runCheck(ctx, check)
+
+			mu.Lock()
+			defer mu.Unlock()
+			res.Checks[name] = result
+			if result.Status != constants.ENUM_HEALTH_STATUS_OK {
+				res.Status = constants.ENUM_HEALTH_STATUS_FAIL
+			}
+		}()
+	}
+	running.Wait()
+
+	return res, res.Status == constants.ENUM_HEALTH_STATUS_OK
+}
+
+// runCheck bounds a check by its own timeout, so one hanging dependency
+// cannot make the whole probe time out without saying which it was.
+func runCheck(ctx context.Context, check func(context.Context) error) dto.HealthCheckResponse {
+	ctx, cancel := context.WithTimeout(ctx, constants.ENUM_HEALTH_CHECK_TIMEOUT_SECOND*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	err := check(ctx)
+	result := dto.HealthCheckResponse{
+		Status:     constants.ENUM_HEALTH_STATUS_OK,
+		DurationMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		result.Status = constants.ENUM_HEALTH_STATUS_FAIL
+		result.Error = err.Error()
+	}
+	return result
+}
+
+func (s *healthService) checkShutdown(ctx context.Context) error {
+	if s.draining.Load() {
+		return dto.ErrShuttingDown
+	}
+	return nil
+}
+
+// The probes are unauthenticated, so driver errors, which name the host and
+// user, are logged rather than returned.
+func (s *healthService) checkDatabase(ctx context.Context) error {
+	if err := s.db.PingContext(ctx); err != nil {
+		slog.WarnContext(ctx, "readiness: database ping failed", slog.Any("error", err))
+		return dto.ErrDatabaseUnreachable
+	}
+	return nil
+}
+
+func (s *healthService) checkMigrations(ctx context.Context) error {
+	pending, err := s.migrator.Pending(ctx)
+	if err != nil {
+		slog.WarnContext(ctx, "readiness: reading migrations failed", slog.Any("error", err))
+		return dto.ErrGetMigrations
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(pending))
+	for _, migration := range pending {
+		names = append(names, fmt.Sprintf("%04d_%s", migration.Version, migration.Name))
+	}
+	return fmt.Errorf("%w: %s", dto.ErrPendingMigrations, strings.Join(names, ", "))
+}
+
+func (s *healthService) checkWorker(name string) func(context.Context) error {
+	return func(ctx context.Context) error {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if !s.workers[name] {
+			return dto.ErrWorkerNotRunning
+		}
+		return nil
+	}
+}
+
+func (s *healthService) Track(name string, worker func(context.Context)) func(context.Context) {
+	s.setWorker(name, false)
+	return func(ctx context.Context) {
+		s.setWorker(name, true)
+		defer s.setWorker(name, false)
+		worker(ctx)
+	}
+}
+
+func (s *healthService) setWorker(name string, running bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.workers[name] = running
+}
+
+func (s *healthService) Drain() {
+	s.draining.Store(true)
+}
+
+func (s *healthService) Version() dto.VersionResponse {
+	info := buildinfo.Get()
+	return dto.VersionResponse{
+		Version:   info.Version,
+		Commit:    info.Commit,
+		BuildTime: info.BuildTime,
+		GoVersion: info.GoVersion,
+		Modified:  info.Modified,
+	}
+}
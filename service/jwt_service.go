@@ -1,74 +1,94 @@
 package service
 
 import (
+	"context"
 	"fmt"
-	"os"
+	"slices"
 	"time"
 
+	"github.com/Amierza/e-wallet/auth"
+	"github.com/Amierza/e-wallet/config"
+	"github.com/Amierza/e-wallet/constants"
+	"github.com/Amierza/e-wallet/dto"
+	"github.com/Amierza/e-wallet/jwtkey"
+	"github.com/Amierza/e-wallet/repository"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
 type (
 	JWTService interface {
-		GenerateToken(userId string) (string, string, error)
+		GenerateToken(userId string, role string, deviceId string) (string, string, error)
 		ValidateToken(token string) (*jwt.Token, error)
-		GetUserIDByToken(accessToken string) (string, error)
+		GetPrincipalByToken(accessToken string) (auth.Principal, error)
+		ValidateSession(ctx context.Context, principal auth.Principal) error
+		GenerateChallengeToken(userId string) (string, time.Time, error)
+		GetUserIDByChallengeToken(challengeToken string) (string, error)
+		JWKS() jwtkey.JWKS
 	}
 
 	jwtCustomClaim struct {
-		UserID string `json:"user_id"`
+		UserID   string   `json:"user_id"`
+		Role     string   `json:"role"`
+		Scopes   []string `json:"scopes,omitempty"`
+		DeviceID string   `json:"device_id,omitempty"`
 		jwt.RegisteredClaims
 	}
 
 	jwtService struct {
-		secretKey string
-		issuer    string
+		userRepo   repository.UserRepository
+		deviceRepo repository.DeviceRepository
+		keys       *jwtkey.Set
+		parser     *jwt.Parser
+		issuer     string
+		accessTTL  time.Duration
+		refreshTTL time.Duration
 	}
 )
 
-func NewJWTService() JWTService {
+func NewJWTService(userRepo repository.UserRepository, deviceRepo repository.DeviceRepository, keys *jwtkey.Set, cfg config.JWT) JWTService {
 	return &jwtService{
-		secretKey: getSecretKey(),
-		issuer:    "Template",
+		userRepo:   userRepo,
+		deviceRepo: deviceRepo,
+		keys:       keys,
+		parser:     jwt.NewParser(jwt.WithValidMethods(keys.Algorithms())),
+		issuer:     cfg.Issuer,
+		accessTTL:  cfg.AccessTTL,
+		refreshTTL: cfg.RefreshTTL,
 	}
 }
 
-func getSecretKey() string {
-	secretKey := os.Getenv("JWT_SECRET")
-	if secretKey == "" {
-		secretKey = "Template"
-	}
-
-	return secretKey
-}
-
-func (j *jwtService) GenerateToken(userId string) (string, string, error) {
+func (j *jwtService) GenerateToken(userId string, role string, deviceId string) (string, string, error) {
 	accessClaims := jwtCustomClaim{
-		userId,
-		jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Second * 120)),
+		UserID:   userId,
+		Role:     role,
+		DeviceID: deviceId,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(j.accessTTL)),
 			Issuer:    j.issuer,
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
 
-	accessToken := jwt.NewWithClaims(jwt.SigningMethodHS256, accessClaims)
-	accessTokenString, err := accessToken.SignedString([]byte(j.secretKey))
+	accessTokenString, err := j.keys.Sign(accessClaims)
 	if err != nil {
 		return "", "", fmt.Errorf("failed to generate access_token: %v", err)
 	}
 
 	refreshClaims := jwtCustomClaim{
-		userId,
-		jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Second * 3600 * 24 * 7)),
+		UserID:   userId,
+		Role:     role,
+		DeviceID: deviceId,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(j.refreshTTL)),
 			Issuer:    j.issuer,
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
 
-	refreshToken := jwt.NewWithClaims(jwt.SigningMethodHS256, refreshClaims)
-	refreshTokenString, err := refreshToken.SignedString([]byte(j.secretKey))
+	refreshTokenString, err := j.keys.Sign(refreshClaims)
 	if err != nil {
 		return "", "", fmt.Errorf("failed to generate refresh_token: %v", err)
 	}
@@ -76,34 +96,126 @@ func (j *jwtService) GenerateToken(userId string) (string, string, error) {
 	return accessTokenString, refreshTokenString, nil
 }
 
-func (j *jwtService) parseToken(t_ *jwt.Token) (any, error) {
-	if _, ok := t_.Method.(*jwt.SigningMethodHMAC); !ok {
-		return nil, fmt.Errorf("unexpected signing method: %v", t_.Header["alg"])
+func (j *jwtService) ValidateToken(tokenString string) (*jwt.Token, error) {
+	token, err := j.parser.Parse(tokenString, j.keys.Keyfunc)
+	if err != nil {
+		return nil, err
 	}
 
-	return []byte(j.secretKey), nil
+	return token, nil
 }
 
-func (j *jwtService) ValidateToken(tokenString string) (*jwt.Token, error) {
-	token, err := jwt.Parse(tokenString, j.parseToken)
+func (j *jwtService) GetPrincipalByToken(accessTokenString string) (auth.Principal, error) {
+	claims := &jwtCustomClaim{}
+	token, err := j.parser.ParseWithClaims(accessTokenString, claims, j.keys.Keyfunc)
 	if err != nil {
-		return nil, err
+		return auth.Principal{}, err
 	}
 
-	return token, nil
+	if !token.Valid || claims.UserID == "" {
+		return auth.Principal{}, fmt.Errorf("invalid token")
+	}
+
+	// A two-factor challenge only proves the PIN; it is not an access token.
+	if slices.Contains(claims.Scopes, constants.ENUM_SCOPE_MFA_CHALLENGE) {
+		return auth.Principal{}, fmt.Errorf("invalid token")
+	}
+
+	principal := auth.Principal{
+		UserID:   claims.UserID,
+		Role:     claims.Role,
+		TokenID:  claims.ID,
+		Scopes:   claims.Scopes,
+		DeviceID: claims.DeviceID,
+	}
+	if claims.IssuedAt != nil {
+		principal.IssuedAt = claims.IssuedAt.Time
+	}
+	if claims.ExpiresAt != nil {
+		principal.ExpiresAt = claims.ExpiresAt.Time
+	}
+
+	return principal, nil
+}
+
+// ValidateSession rejects tokens of users that no longer exist or are frozen,
+// whose sessions were revoked after the token was issued, or whose device was
+// signed out. Token times only have second precision, so the revocation
+// instant is truncated the same way.
+func (j *jwtService) ValidateSession(ctx context.Context, principal auth.Principal) error {
+	user, err := j.userRepo.FindUserByID(ctx, nil, principal.UserID)
+	if err != nil {
+		return dto.ErrGetUserFromUserID
+	}
+
+	if user.FrozenAt != nil {
+		return dto.ErrAccountFrozen
+	}
+
+	if user.SessionsRevokedAt != nil && principal.IssuedAt.Before(user.SessionsRevokedAt.Truncate(time.Second)) {
+		return dto.ErrSessionRevoked
+	}
+
+	if principal.DeviceID == "" {
+		return nil
+	}
+
+	device, found, err := j.deviceRepo.FindDevice(ctx, nil, principal.UserID, principal.DeviceID)
+	if err != nil {
+		return dto.ErrGetDevice
+	}
+	if !found || device.RevokedAt != nil {
+		return dto.ErrDeviceRevoked
+	}
+
+	// Last seen only needs to be roughly right, so skip the write on most
+	// requests.
+	now := time.Now()
+	if now.Sub(device.LastSeenAt) >= constants.ENUM_DEVICE_LAST_SEEN_SECOND*time.Second {
+		j.deviceRepo.TouchDevice(ctx, nil, device.ID.String(), now)
+	}
+
+	return nil
 }
 
-func (j *jwtService) GetUserIDByToken(accessTokenString string) (string, error) {
-	token, err := j.ValidateToken(accessTokenString)
+// GenerateChallengeToken is handed out instead of a token pair when the PIN
+// was correct but a second factor is still required.
+func (j *jwtService) GenerateChallengeToken(userId string) (string, time.Time, error) {
+	expiresAt := time.Now().Add(constants.ENUM_MFA_CHALLENGE_TTL_SECOND * time.Second)
+	claims := jwtCustomClaim{
+		UserID: userId,
+		Scopes: []string{constants.ENUM_SCOPE_MFA_CHALLENGE},
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			Issuer:    j.issuer,
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	token, err := j.keys.Sign(claims)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to generate challenge_token: %v", err)
+	}
+
+	return token, expiresAt, nil
+}
+
+func (j *jwtService) GetUserIDByChallengeToken(challengeToken string) (string, error) {
+	claims := &jwtCustomClaim{}
+	token, err := j.parser.ParseWithClaims(challengeToken, claims, j.keys.Keyfunc)
 	if err != nil {
-		return "", nil
+		return "", err
 	}
 
-	claims, ok := token.Claims.(jwt.MapClaims)
-	if !ok || !token.Valid {
-		return "", fmt.Errorf("invalid token")
+	if !token.Valid || claims.UserID == "" || !slices.Contains(claims.Scopes, constants.ENUM_SCOPE_MFA_CHALLENGE) {
+		return "", fmt.Errorf("invalid challenge token")
 	}
 
-	userID := fmt.Sprintf("%v", claims["user_id"])
-	return userID, nil
+	return claims.UserID, nil
+}
+
+// JWKS publishes the public half of every key tokens are verified with.
+func (j *jwtService) JWKS() jwtkey.JWKS {
+	return j.keys.JWKS()
 }
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+
+	"github.com/Amierza/e-wallet/dto"
+	"github.com/Amierza/e-wallet/repository"
+	"gorm.io/gorm"
+)
+
+type (
+	LedgerService interface {
+		Verify(ctx context.Context, req dto.LedgerVerifyRequest) (dto.LedgerReportResponse, error)
+	}
+
+	ledgerService struct {
+		ledgerRepo repository.LedgerRepository
+		transactor repository.Transactor
+	}
+)
+
+func NewLedgerService(ledgerRepo repository.LedgerRepository, transactor repository.Transactor) LedgerService {
+	return &ledgerService{
+		ledgerRepo: ledgerRepo,
+		transactor: transactor,
+	}
+}
+
+// Verify checks that every wallet balance equals what its history adds up to
+// and that each transaction's balance_after follows from its own row. All
+// reads share one snapshot, so transfers committing meanwhile cannot show up
+// as a mismatch.
+func (s *ledgerService) Verify(ctx context.Context, req dto.LedgerVerifyRequest) (dto.LedgerReportResponse, error) {
+	res := dto.LedgerReportResponse{
+		WalletMismatches: []dto.LedgerWalletResponse{},
+		EntryMismatches:  []dto.LedgerEntryResponse{},
+	}
+
+	err := s.transactor.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Exec("SET TRANSACTION ISOLATION LEVEL REPEATABLE READ READ ONLY").Error; err != nil {
+			return dto.ErrVerifyLedger
+		}
+
+		wallets, err := s.ledgerRepo.GetWalletBalances(ctx, tx, req.UserID)
+		if err != nil {
+			return dto.ErrVerifyLedger
+		}
+		res.WalletsChecked = len(wallets)
+		for _, wallet := range wallets {
+			if wallet.Balance.Minor != wallet.Expected.Minor {
+				res.WalletMismatches = append(res.WalletMismatches, wallet)
+			}
+		}
+
+		entries, err := s.ledgerRepo.GetBrokenEntries(ctx, tx, req.UserID)
+		if err != nil {
+			return dto.ErrVerifyLedger
+		}
+		res.EntryMismatches = append(res.EntryMismatches, entries...)
+
+		res.EntriesChecked, err = s.ledgerRepo.CountEntries(ctx, tx, req.UserID)
+		if err != nil {
+			return dto.ErrVerifyLedger
+		}
+
+		return nil
+	})
+	if err != nil {
+		return dto.LedgerReportResponse{}, err
+	}
+
+	return res, nil
+}
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	crand "crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/Amierza/e-wallet/constants"
+	"github.com/Amierza/e-wallet/dto"
+	"github.com/Amierza/e-wallet/entity"
+	"github.com/Amierza/e-wallet/notify"
+	"github.com/Amierza/e-wallet/repository"
+	"github.com/Amierza/e-wallet/view"
+	"github.com/google/uuid"
+)
+
+type (
+	OTPService interface {
+		Issue(ctx context.Context, purpose string, channel string, target string) (dto.OTPResponse, error)
+		// IssueDecoy records a code exactly like Issue but sends nothing, for
+		// targets that must not be told apart from real ones.
+		IssueDecoy(ctx context.Context, purpose string, channel string, target string) (dto.OTPResponse, error)
+		Verify(ctx context.Context, purpose string, target string, code string) error
+	}
+	otpService struct {
+		otpRepo  repository.OTPRepository
+		notifier notify.Notifier
+		secret   []byte
+	}
+)
+
+var otpSubjects = map[string]string{
+	constants.ENUM_OTP_PURPOSE_PHONE_VERIFICATION: "Verify your phone number",
+	constants.ENUM_OTP_PURPOSE_PIN_RESET:          "Reset your PIN",
+}
+
+func NewOTPService(otpRepo repository.OTPRepository, notifier notify.Notifier, secret string) OTPService {
+	return &otpService{
+		otpRepo:  otpRepo,
+		notifier: notifier,
+		secret:   []byte(secret),
+	}
+}
+
+func (s *otpService) Issue(ctx context.Context, purpose string, channel string, target string) (dto.OTPResponse, error) {
+	return s.issue(ctx, purpose, channel, target, true)
+}
+
+func (s *otpService) IssueDecoy(ctx context.Context, purpose string, channel string, target string) (dto.OTPResponse, error) {
+	return s.issue(ctx, purpose, channel, target, false)
+}
+
+func (s *otpService) issue(ctx context.Context, purpose string, channel string, target string, send bool) (dto.OTPResponse, error) {
+	now := time.Now()
+	cooldown := constants.ENUM_OTP_RESEND_COOLDOWN_SECOND * time.Second
+
+	latest, found, err := s.otpRepo.FindLatestOTP(ctx, nil, purpose, target)
+	if err != nil {
+		return dto.OTPResponse{}, dto.ErrCreateOTP
+	}
+	if found && now.Before(latest.CreatedAt.Add(cooldown)) {
+		return dto.OTPResponse{}, dto.ErrOTPResendCooldown
+	}
+
+	code, err := newOTPCode()
+	if err != nil {
+		return dto.OTPResponse{}, dto.ErrCreateOTP
+	}
+
+	otp := entity.OTP{
+		ID:          uuid.New(),
+		Purpose:     purpose,
+		Channel:     channel,
+		Target:      target,
+		MaxAttempts: constants.ENUM_OTP_MAX_ATTEMPT,
+		ExpiresAt:   now.Add(constants.ENUM_OTP_TTL_SECOND * time.Second),
+	}
+	otp.CodeHash = s.hashCode(otp.ID, code)
+
+	otp, err = s.otpRepo.CreateOTP(ctx, nil, otp)
+	if err != nil {
+		return dto.OTPResponse{}, dto.ErrCreateOTP
+	}
+
+	if send {
+		msg := notify.Message{
+			Channel: channel,
+			To:      target,
+			Subject: otpSubjects[purpose],
+			Body: fmt.Sprintf("Your e-wallet code is %s. It expires in %d minutes. Never share this code with anyone.",
+				code, constants.ENUM_OTP_TTL_SECOND/60),
+		}
+		if err := s.notifier.Send(ctx, msg); err != nil {
+			return dto.OTPResponse{}, dto.ErrSendOTP
+		}
+	}
+
+	return dto.OTPResponse{
+		Channel:     channel,
+		Target:      view.Mask(target),
+		ExpiresAt:   otp.ExpiresAt,
+		ResendAfter: otp.CreatedAt.Add(cooldown),
+	}, nil
+}
+
+func (s *otpService) Verify(ctx context.Context, purpose string, target string, code string) error {
+	otp, found, err := s.otpRepo.FindLatestOTP(ctx, nil, purpose, target)
+	if err != nil || !found || otp.ConsumedAt != nil {
+		return dto.ErrOTPNotFound
+	}
+
+	if time.Now().After(otp.ExpiresAt) {
+		return dto.ErrOTPExpired
+	}
+
+	// Every guess, right or wrong, takes an attempt before the code is
+	// compared. Checking otp.Attempts as read above would let a burst of
+	// parallel guesses all pass before any of them is counted.
+	counted, err := s.otpRepo.IncrementOTPAttempts(ctx, nil, otp.ID)
+	if err != nil {
+		return dto.ErrOTPInvalid
+	}
+	if !counted {
+		return dto.ErrOTPTooManyAttempts
+	}
+
+	if !hmac.Equal([]byte(s.hashCode(otp.ID, code)), []byte(otp.CodeHash)) {
+		return dto.ErrOTPInvalid
+	}
+
+	consumed, err := s.otpRepo.ConsumeOTP(ctx, nil, otp.ID, time.Now())
+	if err != nil || !consumed {
+		return dto.ErrOTPNotFound
+	}
+
+	return nil
+}
+
+// hashCode binds the code to its row so two rows with the same code never
+// share a hash, and keys it so a database dump alone cannot brute-force it.
+func (s *otpService) hashCode(otpID uuid.UUID, code string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(otpID.String() + ":" + code))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func newOTPCode() (string, error) {
+	limit := new(big.Int).Exp(big.NewInt(10), big.NewInt(constants.ENUM_OTP_LENGTH), nil)
+	n, err := crand.Int(crand.Reader, limit)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%0*d", constants.ENUM_OTP_LENGTH, n), nil
+}
@@ -0,0 +1,233 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Amierza/e-wallet/constants"
+	"github.com/Amierza/e-wallet/dto"
+	"github.com/Amierza/e-wallet/entity"
+	"github.com/Amierza/e-wallet/notify"
+	"github.com/Amierza/e-wallet/repository"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// fakeOTPRepo applies the same conditions as the SQL updates under a mutex.
+// If reads is set, FindLatestOTP waits until that many callers have read, so
+// every guess sees the row before any of them has been counted; a caller whose
+// context carries a hold channel then waits for it to close.
+type fakeOTPRepo struct {
+	repository.OTPRepository
+
+	mu    sync.Mutex
+	otps  []entity.OTP
+	reads *sync.WaitGroup
+}
+
+type holdKey struct{}
+
+func (r *fakeOTPRepo) CreateOTP(ctx context.Context, tx *gorm.DB, otp entity.OTP) (entity.OTP, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	otp.CreatedAt = time.Now()
+	r.otps = append(r.otps, otp)
+	return otp, nil
+}
+
+func (r *fakeOTPRepo) FindLatestOTP(ctx context.Context, tx *gorm.DB, purpose string, target string) (entity.OTP, bool, error) {
+	r.mu.Lock()
+	var latest entity.OTP
+	found := false
+	for _, otp := range r.otps {
+		if otp.Purpose == purpose && otp.Target == target {
+			latest, found = otp, true
+		}
+	}
+	r.mu.Unlock()
+
+	if r.reads != nil {
+		r.reads.Done()
+		r.reads.Wait()
+	}
+	if hold, ok := ctx.Value(holdKey{}).(chan struct{}); ok {
+		<-hold
+	}
+	return latest, found, nil
+}
+
+func (r *fakeOTPRepo) IncrementOTPAttempts(ctx context.Context, tx *gorm.DB, otpID uuid.UUID) (bool, error) {
+	return r.update(otpID, func(otp *entity.OTP) bool {
+		if otp.Attempts >= otp.MaxAttempts || otp.ConsumedAt != nil {
+			return false
+		}
+		otp.Attempts++
+		return true
+	})
+}
+
+func (r *fakeOTPRepo) ConsumeOTP(ctx context.Context, tx *gorm.DB, otpID uuid.UUID, now time.Time) (bool, error) {
+	return r.update(otpID, func(otp *entity.OTP) bool {
+		if otp.ConsumedAt != nil {
+			return false
+		}
+		otp.ConsumedAt = &now
+		return true
+	})
+}
+
+func (r *fakeOTPRepo) update(otpID uuid.UUID, apply func(otp *entity.OTP) bool) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range r.otps {
+		if r.otps[i].ID == otpID {
+			return apply(&r.otps[i]), nil
+		}
+	}
+	return false, nil
+}
+
+type capturingNotifier struct {
+	sent []notify.Message
+}
+
+func (n *capturingNotifier) Send(ctx context.Context, msg notify.Message) error {
+	n.sent = append(n.sent, msg)
+	return nil
+}
+
+var otpCodePattern = regexp.MustCompile(`\b\d{6}\b`)
+
+// issueOTP sends a phone verification code to target and returns the code the
+// user received.
+func issueOTP(t *testing.T, svc OTPService, notifier *capturingNotifier, target string) string {
+	t.Helper()
+	if _, err := svc.Issue(context.Background(), constants.ENUM_OTP_PURPOSE_PHONE_VERIFICATION, notify.ChannelSMS, target); err != nil {
+		t.Fatalf("Issue() = %v", err)
+	}
+	code := otpCodePattern.FindString(notifier.sent[len(notifier.sent)-1].Body)
+	if code == "" {
+		t.Fatalf("no code in %q", notifier.sent[len(notifier.sent)-1].Body)
+	}
+	return code
+}
+
+// wrongCode returns a code of the same length that is not code.
+func wrongCode(code string) string {
+	if code == "000000" {
+		return "000001"
+	}
+	return "000000"
+}
+
+func TestOTPVerify(t *testing.T) {
+	const target = "+6281234567890"
+	purpose := constants.ENUM_OTP_PURPOSE_PHONE_VERIFICATION
+	ctx := context.Background()
+
+	t.Run("correct code is accepted once", func(t *testing.T) {
+		notifier := &capturingNotifier{}
+		svc := NewOTPService(&fakeOTPRepo{}, notifier, "secret")
+		code := issueOTP(t, svc, notifier, target)
+
+		if err := svc.Verify(ctx, purpose, target, code); err != nil {
+			t.Fatalf("Verify(correct) = %v", err)
+		}
+		if err := svc.Verify(ctx, purpose, target, code); !errors.Is(err, dto.ErrOTPNotFound) {
+			t.Errorf("Verify(reused) = %v, want ErrOTPNotFound", err)
+		}
+	})
+
+	t.Run("code is bound to its purpose", func(t *testing.T) {
+		notifier := &capturingNotifier{}
+		svc := NewOTPService(&fakeOTPRepo{}, notifier, "secret")
+		code := issueOTP(t, svc, notifier, target)
+
+		if err := svc.Verify(ctx, constants.ENUM_OTP_PURPOSE_PIN_RESET, target, code); !errors.Is(err, dto.ErrOTPNotFound) {
+			t.Errorf("Verify(other purpose) = %v, want ErrOTPNotFound", err)
+		}
+	})
+
+	t.Run("expired code is rejected", func(t *testing.T) {
+		notifier := &capturingNotifier{}
+		repo := &fakeOTPRepo{}
+		svc := NewOTPService(repo, notifier, "secret")
+		code := issueOTP(t, svc, notifier, target)
+		repo.otps[0].ExpiresAt = time.Now().Add(-time.Second)
+
+		if err := svc.Verify(ctx, purpose, target, code); !errors.Is(err, dto.ErrOTPExpired) {
+			t.Errorf("Verify(expired) = %v, want ErrOTPExpired", err)
+		}
+	})
+
+	t.Run("locks after max attempts", func(t *testing.T) {
+		notifier := &capturingNotifier{}
+		svc := NewOTPService(&fakeOTPRepo{}, notifier, "secret")
+		code := issueOTP(t, svc, notifier, target)
+
+		for i := 0; i < constants.ENUM_OTP_MAX_ATTEMPT; i++ {
+			if err := svc.Verify(ctx, purpose, target, wrongCode(code)); !errors.Is(err, dto.ErrOTPInvalid) {
+				t.Fatalf("guess %d = %v, want ErrOTPInvalid", i+1, err)
+			}
+		}
+		if err := svc.Verify(ctx, purpose, target, code); !errors.Is(err, dto.ErrOTPTooManyAttempts) {
+			t.Errorf("Verify(correct) after lockout = %v, want ErrOTPTooManyAttempts", err)
+		}
+	})
+}
+
+// TestOTPVerifyCountsParallelGuesses sends a burst of wrong guesses and the
+// right code, all reading the row before any is counted. The right code is
+// checked last, after the wrong ones used up the attempts it saw as free.
+func TestOTPVerifyCountsParallelGuesses(t *testing.T) {
+	const (
+		target  = "+6281234567890"
+		guesses = 4 * constants.ENUM_OTP_MAX_ATTEMPT
+	)
+	purpose := constants.ENUM_OTP_PURPOSE_PHONE_VERIFICATION
+	notifier := &capturingNotifier{}
+	repo := &fakeOTPRepo{}
+	svc := NewOTPService(repo, notifier, "secret")
+	code := issueOTP(t, svc, notifier, target)
+
+	repo.reads = &sync.WaitGroup{}
+	repo.reads.Add(guesses + 1)
+	hold := make(chan struct{})
+	correct := make(chan error, 1)
+	go func() {
+		ctx := context.WithValue(context.Background(), holdKey{}, hold)
+		correct <- svc.Verify(ctx, purpose, target, code)
+	}()
+
+	errs := make(chan error, guesses)
+	for i := 0; i < guesses; i++ {
+		go func() {
+			errs <- svc.Verify(context.Background(), purpose, target, wrongCode(code))
+		}()
+	}
+
+	checked := 0
+	for i := 0; i < guesses; i++ {
+		switch err := <-errs; {
+		case errors.Is(err, dto.ErrOTPInvalid):
+			checked++
+		case !errors.Is(err, dto.ErrOTPTooManyAttempts):
+			t.Fatalf("Verify() = %v", err)
+		}
+	}
+	close(hold)
+
+	if err := <-correct; !errors.Is(err, dto.ErrOTPTooManyAttempts) {
+		t.Errorf("Verify(correct) after the burst = %v, want ErrOTPTooManyAttempts", err)
+	}
+	if checked != constants.ENUM_OTP_MAX_ATTEMPT {
+		t.Errorf("%d of %d parallel guesses were checked, want %d", checked, guesses, constants.ENUM_OTP_MAX_ATTEMPT)
+	}
+	if otp := repo.otps[0]; otp.Attempts != constants.ENUM_OTP_MAX_ATTEMPT || otp.ConsumedAt != nil {
+		t.Errorf("attempts = %d, consumed = %v; want %d and not consumed", otp.Attempts, otp.ConsumedAt != nil, constants.ENUM_OTP_MAX_ATTEMPT)
+	}
+}
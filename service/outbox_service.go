@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Amierza/e-wallet/constants"
+	"github.com/Amierza/e-wallet/dto"
+	"github.com/Amierza/e-wallet/entity"
+	"github.com/Amierza/e-wallet/event"
+	"github.com/Amierza/e-wallet/repository"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type (
+	OutboxService interface {
+		Relay(ctx context.Context) (int, error)
+		StartRelay(ctx context.Context)
+	}
+	outboxService struct {
+		outboxRepo repository.OutboxRepository
+		sinks      []event.Sink
+	}
+)
+
+func NewOutboxService(outboxRepo repository.OutboxRepository, sinks ...event.Sink) OutboxService {
+	return &outboxService{
+		outboxRepo: outboxRepo,
+		sinks:      sinks,
+	}
+}
+
+// Relay publishes one batch of pending outbox events to every sink. A sink
+// that already accepted an event is skipped when the event is retried.
+func (s *outboxService) Relay(ctx context.Context) (int, error) {
+	events, err := s.outboxRepo.ClaimPendingEvents(ctx, nil, time.Now(),
+		constants.ENUM_OUTBOX_CLAIM_LEASE_SECOND*time.Second, constants.ENUM_OUTBOX_RELAY_BATCH)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, outboxEvent := range events {
+		s.relay(ctx, outboxEvent)
+	}
+
+	return len(events), nil
+}
+
+func (s *outboxService) StartRelay(ctx context.Context) {
+	ticker := time.NewTicker(constants.ENUM_OUTBOX_POLL_INTERVAL_SECOND * time.Second)
+	defer ticker.Stop()
+
+	for {
+		// A batch that already started is finished even when ctx is cancelled,
+		// so stopping never leaves it half done.
+		if _, err := s.Relay(context.WithoutCancel(ctx)); err != nil {
+			slog.ErrorContext(ctx, "outbox relay failed", slog.Any("error", err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *outboxService) relay(ctx context.Context, outboxEvent entity.OutboxEvent) {
+	e := event.Event{
+		ID:          outboxEvent.ID.String(),
+		Sequence:    outboxEvent.Sequence,
+		Type:        outboxEvent.EventType,
+		AggregateID: outboxEvent.AggregateID.String(),
+		Payload:     json.RawMessage(outboxEvent.Payload),
+		OccurredAt:  outboxEvent.CreatedAt,
+	}
+
+	var failures []string
+	for _, sink := range s.sinks {
+		if slices.Contains(outboxEvent.DeliveredSinks, sink.Name()) {
+			continue
+		}
+
+		if err := sink.Publish(ctx, e); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", sink.Name(), err))
+			continue
+		}
+		outboxEvent.DeliveredSinks = append(outboxEvent.DeliveredSinks, sink.Name())
+	}
+
+	now := time.Now()
+	outboxEvent.Attempts++
+
+	switch {
+	case len(failures) == 0:
+		outboxEvent.Status = constants.ENUM_OUTBOX_STATUS_PUBLISHED
+		outboxEvent.PublishedAt = &now
+		outboxEvent.LastError = ""
+	case outboxEvent.Attempts >= constants.ENUM_OUTBOX_MAX_ATTEMPT:
+		// Give up so the rest of this user's events are not blocked forever.
+		outboxEvent.Status = constants.ENUM_OUTBOX_STATUS_FAILED
+		outboxEvent.LastError = strings.Join(failures, "; ")
+		slog.ErrorContext(ctx, "outbox relay: event dropped",
+			slog.String("event_id", outboxEvent.ID.String()),
+			slog.Int("attempts", outboxEvent.Attempts),
+			slog.String("error", outboxEvent.LastError),
+		)
+	default:
+		outboxEvent.LastError = strings.Join(failures, "; ")
+		outboxEvent.NextAttemptAt = now.Add(webhookBackoff(outboxEvent.Attempts))
+	}
+
+	if err := s.outboxRepo.UpdateEvent(ctx, nil, outboxEvent); err != nil {
+		slog.ErrorContext(ctx, "outbox relay: update event failed", slog.String("event_id", outboxEvent.ID.String()), slog.Any("error", err))
+	}
+}
+
+// recordSessionRevoked writes a session.revoked event inside tx, so every
+// instance closes the realtime streams of the revoked tokens: those of
+// deviceID, or of every device of the user when it is empty.
+func recordSessionRevoked(ctx context.Context, outboxRepo repository.OutboxRepository, tx *gorm.DB, userID uuid.UUID, deviceID string, now time.Time) error {
+	outboxEvent, err := newOutboxEvent(constants.ENUM_EVENT_SESSION_REVOKED, userID, dto.SessionRevokedEvent{
+		UserID:    userID.String(),
+		DeviceID:  deviceID,
+		RevokedAt: now,
+	})
+	if err != nil {
+		return dto.ErrRecordEvent
+	}
+
+	if err := outboxRepo.CreateEvent(ctx, tx, outboxEvent); err != nil {
+		return dto.ErrRecordEvent
+	}
+
+	return nil
+}
+
+func newOutboxEvent(eventType string, aggregateID uuid.UUID, data any) (entity.OutboxEvent, error) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return entity.OutboxEvent{}, err
+	}
+
+	return entity.OutboxEvent{
+		ID:            uuid.New(),
+		AggregateID:   aggregateID,
+		EventType:     eventType,
+		Payload:       string(payload),
+		Status:        constants.ENUM_OUTBOX_STATUS_PENDING,
+		NextAttemptAt: time.Now(),
+	}, nil
+}
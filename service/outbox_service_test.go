@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Amierza/e-wallet/constants"
+	"github.com/Amierza/e-wallet/event"
+	"github.com/google/uuid"
+)
+
+type recordingSink struct {
+	name      string
+	failures  int
+	published []string
+}
+
+func (s *recordingSink) Name() string {
+	return s.name
+}
+
+func (s *recordingSink) Publish(ctx context.Context, e event.Event) error {
+	if s.failures > 0 {
+		s.failures--
+		return errors.New("sink unavailable")
+	}
+	s.published = append(s.published, e.Type)
+	return nil
+}
+
+// makeDue moves every pending event's next attempt into the past, as if the
+// retry backoff had elapsed.
+func makeDue(repo *fakeOutboxRepo) {
+	for i := range repo.events {
+		repo.events[i].NextAttemptAt = time.Now().Add(-time.Second)
+	}
+}
+
+func record(t *testing.T, repo *fakeOutboxRepo, aggregate uuid.UUID, eventType string) {
+	t.Helper()
+	outboxEvent, err := newOutboxEvent(eventType, aggregate, map[string]string{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.CreateEvent(context.Background(), nil, outboxEvent); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRelayKeepsEachAggregateInOrder(t *testing.T) {
+	ctx := context.Background()
+	alice, bob := uuid.New(), uuid.New()
+	repo := &fakeOutboxRepo{}
+	record(t, repo, alice, "a1")
+	record(t, repo, bob, "b1")
+	record(t, repo, alice, "a2")
+	record(t, repo, alice, "a3")
+	record(t, repo, bob, "b2")
+
+	sink := &recordingSink{name: "test"}
+	relay := NewOutboxService(repo, sink)
+
+	var batches [][]string
+	for {
+		before := len(sink.published)
+		n, err := relay.Relay(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n == 0 {
+			break
+		}
+		batches = append(batches, slices.Clone(sink.published[before:]))
+	}
+
+	want := [][]string{{"a1", "b1"}, {"a2", "b2"}, {"a3"}}
+	if !slices.EqualFunc(batches, want, slices.Equal[[]string]) {
+		t.Errorf("batches = %v, want %v: one event per user at a time, in the order recorded", batches, want)
+	}
+}
+
+func TestRelayRetriesOnlyTheFailedSink(t *testing.T) {
+	ctx := context.Background()
+	user := uuid.New()
+	repo := &fakeOutboxRepo{}
+	record(t, repo, user, "first")
+	record(t, repo, user, "second")
+
+	bus := &recordingSink{name: "bus"}
+	webhooks := &recordingSink{name: "webhook", failures: 1}
+	relay := NewOutboxService(repo, bus, webhooks)
+
+	if _, err := relay.Relay(ctx); err != nil {
+		t.Fatal(err)
+	}
+	first := repo.events[0]
+	if first.Status != constants.ENUM_OUTBOX_STATUS_PENDING || first.Attempts != 1 || !slices.Equal(first.DeliveredSinks, []string{"bus"}) {
+		t.Fatalf("after a webhook failure: status %s, attempts %d, delivered to %v; want pending, 1, [bus]",
+			first.Status, first.Attempts, first.DeliveredSinks)
+	}
+	if !first.NextAttemptAt.After(time.Now()) || first.LastError == "" {
+		t.Errorf("failed event next attempt %s, error %q; want a later retry with the error kept", first.NextAttemptAt, first.LastError)
+	}
+
+	// The second event waits behind the first even though it is due.
+	if n, _ := relay.Relay(ctx); n != 0 {
+		t.Fatalf("Relay() claimed %d events while the user's first one was still pending", n)
+	}
+
+	makeDue(repo)
+	for {
+		if n, err := relay.Relay(ctx); err != nil || n == 0 {
+			break
+		}
+	}
+
+	if !slices.Equal(bus.published, []string{"first", "second"}) {
+		t.Errorf("bus got %v, want each event once", bus.published)
+	}
+	if !slices.Equal(webhooks.published, []string{"first", "second"}) {
+		t.Errorf("webhook sink got %v, want the retried event before the next one", webhooks.published)
+	}
+	for _, e := range repo.events {
+		if e.Status != constants.ENUM_OUTBOX_STATUS_PUBLISHED || e.PublishedAt == nil {
+			t.Errorf("event %s is %s, want published", e.EventType, e.Status)
+		}
+	}
+}
+
+func TestRelayGivesUpAndUnblocksTheNextEvent(t *testing.T) {
+	ctx := context.Background()
+	user := uuid.New()
+	repo := &fakeOutboxRepo{}
+	record(t, repo, user, "poison")
+	record(t, repo, user, "next")
+
+	sink := &recordingSink{name: "test", failures: constants.ENUM_OUTBOX_MAX_ATTEMPT}
+	relay := NewOutboxService(repo, sink)
+
+	for attempt := 1; attempt <= constants.ENUM_OUTBOX_MAX_ATTEMPT; attempt++ {
+		makeDue(repo)
+		if n, err := relay.Relay(ctx); err != nil || n != 1 {
+			t.Fatalf("attempt %d: Relay() = (%d, %v), want only the failing event", attempt, n, err)
+		}
+	}
+	if status := repo.events[0].Status; status != constants.ENUM_OUTBOX_STATUS_FAILED {
+		t.Fatalf("after %d attempts the event is %s, want failed", constants.ENUM_OUTBOX_MAX_ATTEMPT, status)
+	}
+
+	if _, err := relay.Relay(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(sink.published, []string{"next"}) || repo.events[1].Status != constants.ENUM_OUTBOX_STATUS_PUBLISHED {
+		t.Errorf("published %v, want the next event once the failed one was dropped", sink.published)
+	}
+}
@@ -0,0 +1,33 @@
+package service
+
+import (
+	"github.com/Amierza/e-wallet/constants"
+	"github.com/Amierza/e-wallet/dto"
+)
+
+// validatePin enforces the shape of a PIN: a fixed number of digits that is
+// not one repeated digit or a run such as 123456 or 987654.
+func validatePin(pin string) error {
+	if len(pin) != constants.ENUM_PIN_LENGTH {
+		return dto.ErrPinLength
+	}
+
+	for _, c := range pin {
+		if c < '0' || c > '9' {
+			return dto.ErrPinNotNumeric
+		}
+	}
+
+	repeated, ascending, descending := true, true, true
+	for i := 1; i < len(pin); i++ {
+		diff := int(pin[i]) - int(pin[i-1])
+		repeated = repeated && diff == 0
+		ascending = ascending && diff == 1
+		descending = descending && diff == -1
+	}
+	if repeated || ascending || descending {
+		return dto.ErrPinTooSimple
+	}
+
+	return nil
+}
@@ -0,0 +1,26 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Amierza/e-wallet/dto"
+)
+
+func TestValidatePin(t *testing.T) {
+	pins := map[error][]string{
+		nil:                  {"482913", "112233", "123457", "024680"},
+		dto.ErrPinLength:     {"", "12345", "1234567", "١٢٣٤٥٦"},
+		dto.ErrPinNotNumeric: {"12a456"},
+		// Repeated digits and straight runs up or down.
+		dto.ErrPinTooSimple: {"000000", "777777", "123456", "456789", "987654", "543210"},
+	}
+
+	for want, group := range pins {
+		for _, pin := range group {
+			if err := validatePin(pin); !errors.Is(err, want) {
+				t.Errorf("validatePin(%q) = %v, want %v", pin, err, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+
+	"github.com/Amierza/e-wallet/auth"
+	"github.com/Amierza/e-wallet/constants"
+	"github.com/Amierza/e-wallet/dto"
+	"github.com/Amierza/e-wallet/event"
+	"github.com/Amierza/e-wallet/money"
+	"github.com/Amierza/e-wallet/realtime"
+)
+
+type (
+	RealtimeService interface {
+		Subscribe(ctx context.Context) (<-chan realtime.Message, func(), error)
+		HandleEvent(ctx context.Context, e event.Event) error
+	}
+	realtimeService struct {
+		hub *realtime.Hub
+	}
+)
+
+var realtimeEvents = []string{
+	constants.ENUM_EVENT_TOPUP_COMPLETED,
+	constants.ENUM_EVENT_PAYMENT_COMPLETED,
+	constants.ENUM_EVENT_TRANSFER_COMPLETED,
+	constants.ENUM_EVENT_TRANSFER_RECEIVED,
+}
+
+func NewRealtimeService(hub *realtime.Hub) RealtimeService {
+	return &realtimeService{
+		hub: hub,
+	}
+}
+
+// Subscribe opens a stream for the caller's device that ends when the access
+// token it was opened with expires or is revoked.
+func (s *realtimeService) Subscribe(ctx context.Context) (<-chan realtime.Message, func(), error) {
+	principal, ok := auth.FromContext(ctx)
+	if !ok {
+		return nil, nil, dto.ErrGetUserFromToken
+	}
+
+	messages, cancel := s.hub.Subscribe(principal.UserID, principal.DeviceID, principal.ExpiresAt)
+	return messages, cancel, nil
+}
+
+// HandleEvent is subscribed to the event bus and turns each money movement
+// into a transaction notification plus the wallet balance after it, and each
+// session revocation into the end of the streams it covers.
+func (s *realtimeService) HandleEvent(ctx context.Context, e event.Event) error {
+	if e.Type == constants.ENUM_EVENT_SESSION_REVOKED {
+		var revoked dto.SessionRevokedEvent
+		if err := json.Unmarshal(e.Payload, &revoked); err != nil {
+			return err
+		}
+
+		return s.hub.Publish(ctx, realtime.Message{
+			Type:     realtime.MessageTypeSessionRevoked,
+			UserID:   e.AggregateID,
+			DeviceID: revoked.DeviceID,
+		})
+	}
+
+	if !slices.Contains(realtimeEvents, e.Type) {
+		return nil
+	}
+
+	transaction, err := json.Marshal(dto.RealtimeTransactionData{
+		EventID:   e.ID,
+		EventType: e.Type,
+		Payload:   e.Payload,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := s.hub.Publish(ctx, realtime.Message{
+		Type:   realtime.MessageTypeTransaction,
+		UserID: e.AggregateID,
+		Data:   transaction,
+	}); err != nil {
+		return err
+	}
+
+	var payload struct {
+		BalanceAfter money.Amount `json:"balance_after"`
+	}
+	if err := json.Unmarshal(e.Payload, &payload); err != nil {
+		return err
+	}
+
+	balance, err := json.Marshal(dto.RealtimeBalanceData{
+		Currency: payload.BalanceAfter.Currency,
+		Balance:  payload.BalanceAfter,
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.hub.Publish(ctx, realtime.Message{
+		Type:   realtime.MessageTypeBalance,
+		UserID: e.AggregateID,
+		Data:   balance,
+	})
+}
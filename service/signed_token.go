@@ -0,0 +1,131 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Amierza/e-wallet/dto"
+)
+
+// Signed tokens carry "<field>|...|<expiry>" signed with the configured
+// signing secret. The domain keeps a token minted for one purpose from being
+// accepted by another.
+const (
+	emailTokenDomain    = "email-verification:"
+	pinResetTokenDomain = "pin-reset:"
+)
+
+var (
+	errSignedTokenInvalid = errors.New("signed token is invalid")
+	errSignedTokenExpired = errors.New("signed token is expired")
+)
+
+// SignedTokens mints and checks the signed tokens behind email verification
+// links and PIN resets. baseURL is where those links point.
+type SignedTokens struct {
+	secret  []byte
+	baseURL string
+}
+
+func NewSignedTokens(secret string, baseURL string) *SignedTokens {
+	return &SignedTokens{
+		secret:  []byte(secret),
+		baseURL: strings.TrimRight(baseURL, "/"),
+	}
+}
+
+func (t *SignedTokens) signToken(domain string, expiresAt time.Time, fields ...string) string {
+	payload := strings.Join(append(fields, strconv.FormatInt(expiresAt.Unix(), 10)), "|")
+	return base64.RawURLEncoding.EncodeToString([]byte(payload)) + "." +
+		base64.RawURLEncoding.EncodeToString(t.signedTokenMAC(domain, payload))
+}
+
+func (t *SignedTokens) parseToken(domain string, token string, fields int, now time.Time) ([]string, error) {
+	encodedPayload, encodedMAC, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, errSignedTokenInvalid
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return nil, errSignedTokenInvalid
+	}
+
+	mac, err := base64.RawURLEncoding.DecodeString(encodedMAC)
+	if err != nil || !hmac.Equal(mac, t.signedTokenMAC(domain, string(payload))) {
+		return nil, errSignedTokenInvalid
+	}
+
+	parts := strings.Split(string(payload), "|")
+	if len(parts) != fields+1 {
+		return nil, errSignedTokenInvalid
+	}
+
+	expiresAt, err := strconv.ParseInt(parts[fields], 10, 64)
+	if err != nil {
+		return nil, errSignedTokenInvalid
+	}
+	if now.After(time.Unix(expiresAt, 0)) {
+		return nil, errSignedTokenExpired
+	}
+
+	return parts[:fields], nil
+}
+
+func (t *SignedTokens) signedTokenMAC(domain string, payload string) []byte {
+	mac := hmac.New(sha256.New, t.secret)
+	mac.Write([]byte(domain + payload))
+	return mac.Sum(nil)
+}
+
+// Binding the address means a verification link stops working as soon as the
+// user changes their email again.
+func (t *SignedTokens) signEmailToken(userID string, email string, expiresAt time.Time) string {
+	return t.signToken(emailTokenDomain, expiresAt, userID, email)
+}
+
+func (t *SignedTokens) parseEmailToken(token string, now time.Time) (string, string, error) {
+	fields, err := t.parseToken(emailTokenDomain, token, 2, now)
+	if errors.Is(err, errSignedTokenExpired) {
+		return "", "", dto.ErrVerificationLinkExpired
+	}
+	if err != nil {
+		return "", "", dto.ErrVerificationLinkInvalid
+	}
+
+	return fields[0], fields[1], nil
+}
+
+func (t *SignedTokens) emailVerificationLink(token string) string {
+	return fmt.Sprintf("%s/api/user/%s?token=%s", t.baseURL, VERIFY_EMAIL_ROUTE, url.QueryEscape(token))
+}
+
+// Binding a fingerprint of the current PIN hash makes a reset token single
+// use: once the PIN changes, the fingerprint no longer matches.
+func (t *SignedTokens) signPinResetToken(userID string, pinHash string, expiresAt time.Time) string {
+	return t.signToken(pinResetTokenDomain, expiresAt, userID, pinFingerprint(pinHash))
+}
+
+func (t *SignedTokens) parsePinResetToken(token string, now time.Time) (string, string, error) {
+	fields, err := t.parseToken(pinResetTokenDomain, token, 2, now)
+	if errors.Is(err, errSignedTokenExpired) {
+		return "", "", dto.ErrPinResetTokenExpired
+	}
+	if err != nil {
+		return "", "", dto.ErrPinResetTokenInvalid
+	}
+
+	return fields[0], fields[1], nil
+}
+
+func pinFingerprint(pinHash string) string {
+	sum := sha256.Sum256([]byte(pinHash))
+	return base64.RawURLEncoding.EncodeToString(sum[:16])
+}
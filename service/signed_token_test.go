@@ -0,0 +1,96 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Amierza/e-wallet/dto"
+)
+
+func TestSignedToken(t *testing.T) {
+	tokens := NewSignedTokens("secret", "https://wallet.example/")
+	now := time.Unix(1_700_000_000, 0)
+	valid := tokens.signToken(emailTokenDomain, now.Add(time.Hour), "user-1", "a@example.com")
+	payload, mac, _ := strings.Cut(valid, ".")
+
+	tests := []struct {
+		name    string
+		tokens  *SignedTokens
+		domain  string
+		token   string
+		fields  int
+		now     time.Time
+		want    []string
+		wantErr error
+	}{
+		{"valid", tokens, emailTokenDomain, valid, 2, now, []string{"user-1", "a@example.com"}, nil},
+		{"at expiry", tokens, emailTokenDomain, valid, 2, now.Add(time.Hour), []string{"user-1", "a@example.com"}, nil},
+		{"expired", tokens, emailTokenDomain, valid, 2, now.Add(time.Hour + time.Second), nil, errSignedTokenExpired},
+		{"other domain", tokens, pinResetTokenDomain, valid, 2, now, nil, errSignedTokenInvalid},
+		{"other secret", NewSignedTokens("other", ""), emailTokenDomain, valid, 2, now, nil, errSignedTokenInvalid},
+		{"field count", tokens, emailTokenDomain, valid, 1, now, nil, errSignedTokenInvalid},
+		{"tampered payload", tokens, emailTokenDomain, "x" + payload + "." + mac, 2, now, nil, errSignedTokenInvalid},
+		{"tampered mac", tokens, emailTokenDomain, payload + "." + mac[1:], 2, now, nil, errSignedTokenInvalid},
+		{"no separator", tokens, emailTokenDomain, payload, 2, now, nil, errSignedTokenInvalid},
+		{"empty", tokens, emailTokenDomain, "", 2, now, nil, errSignedTokenInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.tokens.parseToken(tt.domain, tt.token, tt.fields, tt.now)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("parseToken() err = %v, want %v", err, tt.wantErr)
+			}
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("parseToken() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEmailToken(t *testing.T) {
+	tokens := NewSignedTokens("secret", "https://wallet.example/")
+	now := time.Unix(1_700_000_000, 0)
+	token := tokens.signEmailToken("user-1", "a@example.com", now.Add(time.Hour))
+
+	userID, email, err := tokens.parseEmailToken(token, now)
+	if err != nil || userID != "user-1" || email != "a@example.com" {
+		t.Errorf("parseEmailToken() = (%q, %q, %v), want (user-1, a@example.com, nil)", userID, email, err)
+	}
+	if _, _, err := tokens.parseEmailToken(token, now.Add(2*time.Hour)); !errors.Is(err, dto.ErrVerificationLinkExpired) {
+		t.Errorf("parseEmailToken(expired) err = %v, want ErrVerificationLinkExpired", err)
+	}
+	if _, _, err := tokens.parseEmailToken(token+"x", now); !errors.Is(err, dto.ErrVerificationLinkInvalid) {
+		t.Errorf("parseEmailToken(tampered) err = %v, want ErrVerificationLinkInvalid", err)
+	}
+	if _, _, err := tokens.parsePinResetToken(token, now); !errors.Is(err, dto.ErrPinResetTokenInvalid) {
+		t.Errorf("parsePinResetToken(email token) err = %v, want ErrPinResetTokenInvalid", err)
+	}
+
+	link := tokens.emailVerificationLink("a+b")
+	if want := "https://wallet.example/api/user/" + VERIFY_EMAIL_ROUTE + "?token=a%2Bb"; link != want {
+		t.Errorf("emailVerificationLink() = %q, want %q", link, want)
+	}
+}
+
+func TestPinResetToken(t *testing.T) {
+	tokens := NewSignedTokens("secret", "")
+	now := time.Unix(1_700_000_000, 0)
+	token := tokens.signPinResetToken("user-1", "$2a$12$old", now.Add(10*time.Minute))
+
+	userID, fingerprint, err := tokens.parsePinResetToken(token, now)
+	if err != nil || userID != "user-1" {
+		t.Fatalf("parsePinResetToken() = (%q, %v), want user-1", userID, err)
+	}
+	if fingerprint != pinFingerprint("$2a$12$old") {
+		t.Error("parsePinResetToken() fingerprint does not match the PIN hash it was signed with")
+	}
+	if fingerprint == pinFingerprint("$2a$12$new") {
+		t.Error("fingerprint still matches after the PIN hash changed")
+	}
+	if _, _, err := tokens.parsePinResetToken(token, now.Add(11*time.Minute)); !errors.Is(err, dto.ErrPinResetTokenExpired) {
+		t.Errorf("parsePinResetToken(expired) err = %v, want ErrPinResetTokenExpired", err)
+	}
+}
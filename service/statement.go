@@ -0,0 +1,97 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Amierza/e-wallet/constants"
+	"github.com/Amierza/e-wallet/dto"
+	"github.com/Amierza/e-wallet/entity"
+	"github.com/Amierza/e-wallet/money"
+)
+
+type statementLine struct {
+	at      time.Time
+	kind    string
+	amount  string
+	balance string
+	remarks string
+}
+
+// statementPeriod turns the inclusive From/To dates of the request into a
+// half-open [from, to) range, defaulting to the last few weeks.
+func statementPeriod(req dto.StatementRequest, now time.Time) (time.Time, time.Time, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	to := today.AddDate(0, 0, 1)
+	if req.To != "" {
+		day, err := time.Parse(time.DateOnly, req.To)
+		if err != nil {
+			return time.Time{}, time.Time{}, dto.ErrInvalidStatementPeriod
+		}
+		to = day.AddDate(0, 0, 1)
+	}
+
+	from := to.AddDate(0, 0, -constants.ENUM_STATEMENT_DEFAULT_DAYS)
+	if req.From != "" {
+		day, err := time.Parse(time.DateOnly, req.From)
+		if err != nil {
+			return time.Time{}, time.Time{}, dto.ErrInvalidStatementPeriod
+		}
+		from = day
+	}
+
+	if !from.Before(to) || to.Sub(from) > constants.ENUM_STATEMENT_MAX_DAYS*24*time.Hour {
+		return time.Time{}, time.Time{}, dto.ErrInvalidStatementPeriod
+	}
+
+	return from, to, nil
+}
+
+func formatStatement(user entity.User, from time.Time, to time.Time, transactions dto.GetAllTransactionRepositoryResponse) string {
+	var lines []statementLine
+
+	for _, topup := range transactions.TopUps {
+		lines = append(lines, statementLine{topup.CreatedAt, "TOP UP", "+" + statementAmount(topup.Amount), statementAmount(topup.BalanceAfter), ""})
+	}
+
+	for _, payment := range transactions.Payments {
+		lines = append(lines, statementLine{payment.CreatedAt, "PAYMENT", "-" + statementAmount(payment.Amount), statementAmount(payment.BalanceAfter), payment.Remarks})
+	}
+
+	for _, transfer := range transactions.Transfers {
+		if transfer.UserID == user.ID {
+			lines = append(lines, statementLine{transfer.CreatedAt, "TRANSFER OUT", "-" + statementAmount(transfer.Amount), statementAmount(transfer.BalanceAfter), transfer.Remarks})
+			continue
+		}
+		// The receiver's balance after the transfer is not stored on the row.
+		lines = append(lines, statementLine{transfer.CreatedAt, "TRANSFER IN", "+" + statementAmount(transfer.TargetAmount), "", transfer.Remarks})
+	}
+
+	sort.SliceStable(lines, func(i, j int) bool {
+		return lines[i].at.Before(lines[j].at)
+	})
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Statement for %s %s (%s)\n", user.FirstName, user.LastName, user.PhoneNumber)
+	fmt.Fprintf(&b, "Period: %s to %s\n\n", from.Format(time.DateOnly), to.AddDate(0, 0, -1).Format(time.DateOnly))
+
+	if len(lines) == 0 {
+		b.WriteString("No transactions in this period.\n")
+		return b.String()
+	}
+
+	fmt.Fprintf(&b, "%-20s %-13s %20s %20s  %s\n", "Date", "Type", "Amount", "Balance", "Remarks")
+	for _, line := range lines {
+		fmt.Fprintf(&b, "%-20s %-13s %20s %20s  %s\n",
+			line.at.UTC().Format("2006-01-02 15:04:05"), line.kind, line.amount, line.balance, line.remarks)
+	}
+
+	return b.String()
+}
+
+func statementAmount(a money.Amount) string {
+	return a.String() + " " + a.Currency
+}
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+
+	"github.com/Amierza/e-wallet/dto"
+	"github.com/Amierza/e-wallet/logging"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+)
+
+var tracer = otel.Tracer("github.com/Amierza/e-wallet/service")
+
+// traced runs fn in a span called name, marking the span failed when fn
+// returns an error. The message is scrubbed the way log lines are.
+func traced[T any](ctx context.Context, name string, fn func(context.Context) (T, error)) (T, error) {
+	ctx, span := tracer.Start(ctx, name)
+	defer span.End()
+
+	res, err := fn(ctx)
+	if err != nil {
+		span.SetStatus(codes.Error, logging.Scrub(err.Error()))
+	}
+	return res, err
+}
+
+// tracedUserService wraps every UserService method in a span, so the
+// repository spans of one call are grouped under it.
+type tracedUserService struct {
+	next UserService
+}
+
+func NewTracedUserService(next UserService) UserService {
+	return &tracedUserService{next: next}
+}
+
+func (s *tracedUserService) RegisterUser(ctx context.Context, req dto.UserCreateRequest) (dto.UserResponse, error) {
+	return traced(ctx, "UserService.RegisterUser", func(ctx context.Context) (dto.UserResponse, error) {
+		return s.next.RegisterUser(ctx, req)
+	})
+}
+
+func (s *tracedUserService) GetAllUserWithPagination(ctx context.Context, req dto.PaginationRequest) (dto.UserPaginationResponse, error) {
+	return traced(ctx, "UserService.GetAllUserWithPagination", func(ctx context.Context) (dto.UserPaginationResponse, error) {
+		return s.next.GetAllUserWithPagination(ctx, req)
+	})
+}
+
+func (s *tracedUserService) LoginUser(ctx context.Context, req dto.UserLoginRequest) (dto.UserLoginResponse, error) {
+	return traced(ctx, "UserService.LoginUser", func(ctx context.Context) (dto.UserLoginResponse, error) {
+		return s.next.LoginUser(ctx, req)
+	})
+}
+
+func (s *tracedUserService) TopUpUser(ctx context.Context, req dto.TopUpRequest) (dto.TopUpResponse, error) {
+	return traced(ctx, "UserService.TopUpUser", func(ctx context.Context) (dto.TopUpResponse, error) {
+		return s.next.TopUpUser(ctx, req)
+	})
+}
+
+func (s *tracedUserService) PaymentUser(ctx context.Context, req dto.PaymentRequest) (dto.PaymentResponse, error) {
+	return traced(ctx, "UserService.PaymentUser", func(ctx context.Context) (dto.PaymentResponse, error) {
+		return s.next.PaymentUser(ctx, req)
+	})
+}
+
+func (s *tracedUserService) TransferUser(ctx context.Context, req dto.TransferRequest) (dto.TransferResponse, error) {
+	return traced(ctx, "UserService.TransferUser", func(ctx context.Context) (dto.TransferResponse, error) {
+		return s.next.TransferUser(ctx, req)
+	})
+}
+
+func (s *tracedUserService) GetAllTransactionWithPagination(ctx context.Context, req dto.PaginationRequest) (dto.TransactionPaginationResponse, error) {
+	return traced(ctx, "UserService.GetAllTransactionWithPagination", func(ctx context.Context) (dto.TransactionPaginationResponse, error) {
+		return s.next.GetAllTransactionWithPagination(ctx, req)
+	})
+}
+
+func (s *tracedUserService) UpdateProfileUser(ctx context.Context, req dto.UpdateProfileRequest) (dto.UserResponse, error) {
+	return traced(ctx, "UserService.UpdateProfileUser", func(ctx context.Context) (dto.UserResponse, error) {
+		return s.next.UpdateProfileUser(ctx, req)
+	})
+}
+
+func (s *tracedUserService) GetAllWallet(ctx context.Context) ([]dto.WalletResponse, error) {
+	return traced(ctx, "UserService.GetAllWallet", func(ctx context.Context) ([]dto.WalletResponse, error) {
+		return s.next.GetAllWallet(ctx)
+	})
+}
+
+func (s *tracedUserService) OpenWallet(ctx context.Context, req dto.OpenWalletRequest) (dto.WalletResponse, error) {
+	return traced(ctx, "UserService.OpenWallet", func(ctx context.Context) (dto.WalletResponse, error) {
+		return s.next.OpenWallet(ctx, req)
+	})
+}
+
+func (s *tracedUserService) VerifyPhone(ctx context.Context, req dto.VerifyPhoneRequest) (dto.UserResponse, error) {
+	return traced(ctx, "UserService.VerifyPhone", func(ctx context.Context) (dto.UserResponse, error) {
+		return s.next.VerifyPhone(ctx, req)
+	})
+}
+
+func (s *tracedUserService) ResendPhoneOTP(ctx context.Context, req dto.ResendOTPRequest) (dto.OTPResponse, error) {
+	return traced(ctx, "UserService.ResendPhoneOTP", func(ctx context.Context) (dto.OTPResponse, error) {
+		return s.next.ResendPhoneOTP(ctx, req)
+	})
+}
+
+func (s *tracedUserService) VerifyEmail(ctx context.Context, req dto.VerifyEmailRequest) (dto.UserResponse, error) {
+	return traced(ctx, "UserService.VerifyEmail", func(ctx context.Context) (dto.UserResponse, error) {
+		return s.next.VerifyEmail(ctx, req)
+	})
+}
+
+func (s *tracedUserService) ResendEmailVerification(ctx context.Context) (dto.EmailVerificationResponse, error) {
+	return traced(ctx, "UserService.ResendEmailVerification", func(ctx context.Context) (dto.EmailVerificationResponse, error) {
+		return s.next.ResendEmailVerification(ctx)
+	})
+}
+
+func (s *tracedUserService) SendStatement(ctx context.Context, req dto.StatementRequest) (dto.StatementResponse, error) {
+	return traced(ctx, "UserService.SendStatement", func(ctx context.Context) (dto.StatementResponse, error) {
+		return s.next.SendStatement(ctx, req)
+	})
+}
+
+func (s *tracedUserService) ForgotPin(ctx context.Context, req dto.ForgotPinRequest) (dto.OTPResponse, error) {
+	return traced(ctx, "UserService.ForgotPin", func(ctx context.Context) (dto.OTPResponse, error) {
+		return s.next.ForgotPin(ctx, req)
+	})
+}
+
+func (s *tracedUserService) VerifyPinReset(ctx context.Context, req dto.VerifyPinResetRequest) (dto.VerifyPinResetResponse, error) {
+	return traced(ctx, "UserService.VerifyPinReset", func(ctx context.Context) (dto.VerifyPinResetResponse, error) {
+		return s.next.VerifyPinReset(ctx, req)
+	})
+}
+
+func (s *tracedUserService) ResetPin(ctx context.Context, req dto.ResetPinRequest) error {
+	_, err := traced(ctx, "UserService.ResetPin", func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, s.next.ResetPin(ctx, req)
+	})
+	return err
+}
+
+func (s *tracedUserService) ChangePin(ctx context.Context, req dto.ChangePinRequest) (dto.UserLoginResponse, error) {
+	return traced(ctx, "UserService.ChangePin", func(ctx context.Context) (dto.UserLoginResponse, error) {
+		return s.next.ChangePin(ctx, req)
+	})
+}
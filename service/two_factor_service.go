@@ -0,0 +1,396 @@
+package service
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	crand "crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Amierza/e-wallet/auth"
+	"github.com/Amierza/e-wallet/constants"
+	"github.com/Amierza/e-wallet/dto"
+	"github.com/Amierza/e-wallet/entity"
+	"github.com/Amierza/e-wallet/helpers"
+	"github.com/Amierza/e-wallet/metrics"
+	"github.com/Amierza/e-wallet/repository"
+	"github.com/Amierza/e-wallet/totp"
+	"github.com/google/uuid"
+)
+
+type (
+	TwoFactorService interface {
+		Enroll(ctx context.Context) (dto.TwoFactorEnrollResponse, error)
+		Confirm(ctx context.Context, req dto.TwoFactorCodeRequest) (dto.RecoveryCodesResponse, error)
+		Disable(ctx context.Context, req dto.TwoFactorDisableRequest) error
+		RegenerateRecoveryCodes(ctx context.Context, req dto.TwoFactorCodeRequest) (dto.RecoveryCodesResponse, error)
+		IsEnabled(ctx context.Context, userID string) (bool, error)
+		CompleteLogin(ctx context.Context, req dto.TwoFactorLoginRequest) (dto.UserLoginResponse, error)
+	}
+	twoFactorService struct {
+		twoFactorRepo repository.TwoFactorRepository
+		userRepo      repository.UserRepository
+		jwtService    JWTService
+		devices       DeviceService
+		key           []byte
+		metrics       metrics.Metrics
+	}
+)
+
+func NewTwoFactorService(twoFactorRepo repository.TwoFactorRepository, userRepo repository.UserRepository, jwtService JWTService, devices DeviceService, encryptionKey string, metrics metrics.Metrics) TwoFactorService {
+	return &twoFactorService{
+		twoFactorRepo: twoFactorRepo,
+		userRepo:      userRepo,
+		jwtService:    jwtService,
+		devices:       devices,
+		key:           deriveTwoFactorKey(encryptionKey),
+		metrics:       metrics,
+	}
+}
+
+// deriveTwoFactorKey derives the AES-256 key that seals TOTP secrets at rest.
+func deriveTwoFactorKey(secret string) []byte {
+	sum := sha256.Sum256([]byte("totp-secret:" + secret))
+	return sum[:]
+}
+
+func (s *twoFactorService) Enroll(ctx context.Context) (dto.TwoFactorEnrollResponse, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return dto.TwoFactorEnrollResponse{}, dto.ErrGetUserFromToken
+	}
+
+	user, err := s.userRepo.FindUserByID(ctx, nil, userID)
+	if err != nil {
+		return dto.TwoFactorEnrollResponse{}, dto.ErrGetUserFromUserID
+	}
+
+	existing, found, err := s.twoFactorRepo.FindTwoFactor(ctx, nil, userID)
+	if err != nil {
+		return dto.TwoFactorEnrollResponse{}, dto.ErrGetTwoFactor
+	}
+	if found && existing.ConfirmedAt != nil {
+		return dto.TwoFactorEnrollResponse{}, dto.ErrTwoFactorAlreadyEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return dto.TwoFactorEnrollResponse{}, dto.ErrSaveTwoFactor
+	}
+
+	sealed, err := s.seal(secret)
+	if err != nil {
+		return dto.TwoFactorEnrollResponse{}, dto.ErrSaveTwoFactor
+	}
+
+	// Enrolling again before confirming simply replaces the pending secret.
+	twoFactor := entity.TwoFactor{
+		UserID:    user.ID,
+		Secret:    sealed,
+		CreatedAt: existing.CreatedAt,
+	}
+	if err := s.twoFactorRepo.SaveTwoFactor(ctx, nil, twoFactor); err != nil {
+		return dto.TwoFactorEnrollResponse{}, dto.ErrSaveTwoFactor
+	}
+
+	return dto.TwoFactorEnrollResponse{
+		Secret:     secret,
+		OTPAuthURI: totp.URI(constants.ENUM_TOTP_ISSUER, user.PhoneNumber, secret),
+	}, nil
+}
+
+func (s *twoFactorService) Confirm(ctx context.Context, req dto.TwoFactorCodeRequest) (dto.RecoveryCodesResponse, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return dto.RecoveryCodesResponse{}, dto.ErrGetUserFromToken
+	}
+
+	twoFactor, found, err := s.twoFactorRepo.FindTwoFactor(ctx, nil, userID)
+	if err != nil {
+		return dto.RecoveryCodesResponse{}, dto.ErrGetTwoFactor
+	}
+	if !found {
+		return dto.RecoveryCodesResponse{}, dto.ErrTwoFactorNotEnrolled
+	}
+	if twoFactor.ConfirmedAt != nil {
+		return dto.RecoveryCodesResponse{}, dto.ErrTwoFactorAlreadyEnabled
+	}
+
+	if err := s.verify(ctx, &twoFactor, req.Code, ""); err != nil {
+		return dto.RecoveryCodesResponse{}, err
+	}
+
+	if err := s.twoFactorRepo.ConfirmTwoFactor(ctx, nil, userID, time.Now()); err != nil {
+		return dto.RecoveryCodesResponse{}, dto.ErrSaveTwoFactor
+	}
+
+	return s.issueRecoveryCodes(ctx, twoFactor.UserID)
+}
+
+func (s *twoFactorService) Disable(ctx context.Context, req dto.TwoFactorDisableRequest) error {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return dto.ErrGetUserFromToken
+	}
+
+	user, err := s.userRepo.FindUserByID(ctx, nil, userID)
+	if err != nil {
+		return dto.ErrGetUserFromUserID
+	}
+
+	checkPin, err := helpers.ChcekPin(user.Pin, []byte(req.Pin))
+	if err != nil || !checkPin {
+		return dto.ErrPinNotMatch
+	}
+
+	twoFactor, err := s.findEnabled(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.verify(ctx, &twoFactor, req.Code, req.RecoveryCode); err != nil {
+		return err
+	}
+
+	if err := s.twoFactorRepo.DeleteTwoFactor(ctx, nil, userID); err != nil {
+		return dto.ErrSaveTwoFactor
+	}
+
+	return nil
+}
+
+func (s *twoFactorService) RegenerateRecoveryCodes(ctx context.Context, req dto.TwoFactorCodeRequest) (dto.RecoveryCodesResponse, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return dto.RecoveryCodesResponse{}, dto.ErrGetUserFromToken
+	}
+
+	twoFactor, err := s.findEnabled(ctx, userID)
+	if err != nil {
+		return dto.RecoveryCodesResponse{}, err
+	}
+
+	if err := s.verify(ctx, &twoFactor, req.Code, ""); err != nil {
+		return dto.RecoveryCodesResponse{}, err
+	}
+
+	return s.issueRecoveryCodes(ctx, twoFactor.UserID)
+}
+
+func (s *twoFactorService) IsEnabled(ctx context.Context, userID string) (bool, error) {
+	twoFactor, found, err := s.twoFactorRepo.FindTwoFactor(ctx, nil, userID)
+	if err != nil {
+		return false, dto.ErrGetTwoFactor
+	}
+
+	return found && twoFactor.ConfirmedAt != nil, nil
+}
+
+func (s *twoFactorService) CompleteLogin(ctx context.Context, req dto.TwoFactorLoginRequest) (dto.UserLoginResponse, error) {
+	res, err := s.completeLogin(ctx, req)
+	if err != nil {
+		s.metrics.LoginFailed(err)
+	}
+
+	return res, err
+}
+
+func (s *twoFactorService) completeLogin(ctx context.Context, req dto.TwoFactorLoginRequest) (dto.UserLoginResponse, error) {
+	userID, err := s.jwtService.GetUserIDByChallengeToken(req.ChallengeToken)
+	if err != nil {
+		return dto.UserLoginResponse{}, dto.ErrTwoFactorChallenge
+	}
+
+	user, err := s.userRepo.FindUserByID(ctx, nil, userID)
+	if err != nil {
+		return dto.UserLoginResponse{}, dto.ErrGetUserFromUserID
+	}
+
+	twoFactor, err := s.findEnabled(ctx, userID)
+	if err != nil {
+		return dto.UserLoginResponse{}, err
+	}
+
+	if err := s.verify(ctx, &twoFactor, req.Code, req.RecoveryCode); err != nil {
+		return dto.UserLoginResponse{}, err
+	}
+
+	device, err := s.devices.Register(ctx, user, req.DeviceInfo)
+	if err != nil {
+		return dto.UserLoginResponse{}, err
+	}
+
+	accessToken, refreshToken, err := s.jwtService.GenerateToken(user.ID.String(), user.Role, device.ID.String())
+	if err != nil {
+		return dto.UserLoginResponse{}, err
+	}
+
+	return dto.UserLoginResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		DeviceID:     device.ID.String(),
+	}, nil
+}
+
+func (s *twoFactorService) findEnabled(ctx context.Context, userID string) (entity.TwoFactor, error) {
+	twoFactor, found, err := s.twoFactorRepo.FindTwoFactor(ctx, nil, userID)
+	if err != nil {
+		return entity.TwoFactor{}, dto.ErrGetTwoFactor
+	}
+	if !found || twoFactor.ConfirmedAt == nil {
+		return entity.TwoFactor{}, dto.ErrTwoFactorNotEnabled
+	}
+
+	return twoFactor, nil
+}
+
+// verify accepts either a TOTP code or a recovery code. A TOTP step is only
+// accepted once, and repeated failures lock the factor for a while. Both are
+// enforced by the repository in single statements, so parallel requests can
+// neither replay a code nor slip extra guesses past the lock.
+func (s *twoFactorService) verify(ctx context.Context, twoFactor *entity.TwoFactor, code string, recoveryCode string) error {
+	now := time.Now()
+	if twoFactor.LockedUntil != nil && now.Before(*twoFactor.LockedUntil) {
+		return dto.ErrTwoFactorLocked
+	}
+
+	userID := twoFactor.UserID.String()
+	var ok bool
+	switch {
+	case code != "":
+		secret, err := s.open(twoFactor.Secret)
+		if err != nil {
+			return dto.ErrGetTwoFactor
+		}
+
+		if step, valid := totp.Validate(secret, code, now, constants.ENUM_TOTP_SKEW_STEP); valid {
+			ok, err = s.twoFactorRepo.UseStep(ctx, nil, userID, step, now)
+			if err != nil {
+				return dto.ErrSaveTwoFactor
+			}
+			if ok {
+				twoFactor.LastUsedStep = step
+			}
+		}
+	case recoveryCode != "":
+		used, err := s.twoFactorRepo.UseRecoveryCode(ctx, nil, userID, s.hashRecoveryCode(recoveryCode), now)
+		if err != nil {
+			return dto.ErrGetTwoFactor
+		}
+		if used {
+			if err := s.twoFactorRepo.ResetFailedAttempts(ctx, nil, userID); err != nil {
+				return dto.ErrSaveTwoFactor
+			}
+		}
+		ok = used
+	default:
+		return dto.ErrTwoFactorCodeRequired
+	}
+
+	if !ok {
+		lockedUntil := now.Add(constants.ENUM_TOTP_LOCK_SECOND * time.Second)
+		if err := s.twoFactorRepo.RecordFailedAttempt(ctx, nil, userID, constants.ENUM_TOTP_MAX_ATTEMPT, lockedUntil); err != nil {
+			return dto.ErrSaveTwoFactor
+		}
+		return dto.ErrTwoFactorCodeInvalid
+	}
+
+	twoFactor.FailedAttempts = 0
+	twoFactor.LockedUntil = nil
+	return nil
+}
+
+// issueRecoveryCodes replaces any previous set. Only hashes are stored; the
+// plain codes are shown to the user this once.
+func (s *twoFactorService) issueRecoveryCodes(ctx context.Context, userID uuid.UUID) (dto.RecoveryCodesResponse, error) {
+	plain := make([]string, 0, constants.ENUM_TOTP_RECOVERY_CODES)
+	codes := make([]entity.RecoveryCode, 0, constants.ENUM_TOTP_RECOVERY_CODES)
+
+	for range constants.ENUM_TOTP_RECOVERY_CODES {
+		code, err := newRecoveryCode()
+		if err != nil {
+			return dto.RecoveryCodesResponse{}, dto.ErrCreateRecoveryCodes
+		}
+
+		plain = append(plain, code)
+		codes = append(codes, entity.RecoveryCode{
+			ID:       uuid.New(),
+			UserID:   userID,
+			CodeHash: s.hashRecoveryCode(code),
+		})
+	}
+
+	if err := s.twoFactorRepo.ReplaceRecoveryCodes(ctx, nil, userID.String(), codes); err != nil {
+		return dto.RecoveryCodesResponse{}, dto.ErrCreateRecoveryCodes
+	}
+
+	return dto.RecoveryCodesResponse{RecoveryCodes: plain}, nil
+}
+
+// Recovery codes carry 50 random bits, so a keyed SHA-256 is enough and keeps
+// lookup a single indexed query instead of a slow hash per stored code.
+func (s *twoFactorService) hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte("recovery-code:" + normalized))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func newRecoveryCode() (string, error) {
+	raw := make([]byte, 7)
+	if _, err := crand.Read(raw); err != nil {
+		return "", err
+	}
+
+	code := strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(raw))[:10]
+	return code[:5] + "-" + code[5:], nil
+}
+
+func (s *twoFactorService) seal(secret string) (string, error) {
+	gcm, err := s.cipher()
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := crand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(gcm.Seal(nonce, nonce, []byte(secret), nil)), nil
+}
+
+func (s *twoFactorService) open(sealed string) (string, error) {
+	gcm, err := s.cipher()
+	if err != nil {
+		return "", err
+	}
+
+	data, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil || len(data) < gcm.NonceSize() {
+		return "", errors.New("sealed totp secret is malformed")
+	}
+
+	plain, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", err
+	}
+
+	return string(plain), nil
+}
+
+func (s *twoFactorService) cipher() (cipher.AEAD, error) {
+	block, err := aes.NewCipher(s.key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
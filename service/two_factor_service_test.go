@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Amierza/e-wallet/auth"
+	"github.com/Amierza/e-wallet/constants"
+	"github.com/Amierza/e-wallet/dto"
+	"github.com/Amierza/e-wallet/entity"
+	"github.com/Amierza/e-wallet/metrics"
+	"github.com/Amierza/e-wallet/repository"
+	"github.com/Amierza/e-wallet/totp"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// fakeTwoFactorRepo mirrors the conditional updates of the real repository
+// for a single user.
+type fakeTwoFactorRepo struct {
+	repository.TwoFactorRepository
+
+	twoFactor *entity.TwoFactor
+	recovery  []entity.RecoveryCode
+}
+
+func (r *fakeTwoFactorRepo) FindTwoFactor(ctx context.Context, tx *gorm.DB, userID string) (entity.TwoFactor, bool, error) {
+	if r.twoFactor == nil {
+		return entity.TwoFactor{}, false, nil
+	}
+	return *r.twoFactor, true, nil
+}
+
+func (r *fakeTwoFactorRepo) SaveTwoFactor(ctx context.Context, tx *gorm.DB, twoFactor entity.TwoFactor) error {
+	r.twoFactor = &twoFactor
+	return nil
+}
+
+func (r *fakeTwoFactorRepo) ConfirmTwoFactor(ctx context.Context, tx *gorm.DB, userID string, now time.Time) error {
+	if r.twoFactor.ConfirmedAt == nil {
+		r.twoFactor.ConfirmedAt = &now
+	}
+	return nil
+}
+
+func (r *fakeTwoFactorRepo) UseStep(ctx context.Context, tx *gorm.DB, userID string, step int64, now time.Time) (bool, error) {
+	tf := r.twoFactor
+	if tf.LastUsedStep >= step || tf.LockedUntil != nil && tf.LockedUntil.After(now) {
+		return false, nil
+	}
+	tf.LastUsedStep, tf.FailedAttempts, tf.LockedUntil = step, 0, nil
+	return true, nil
+}
+
+func (r *fakeTwoFactorRepo) ResetFailedAttempts(ctx context.Context, tx *gorm.DB, userID string) error {
+	r.twoFactor.FailedAttempts, r.twoFactor.LockedUntil = 0, nil
+	return nil
+}
+
+func (r *fakeTwoFactorRepo) RecordFailedAttempt(ctx context.Context, tx *gorm.DB, userID string, maxAttempts int, lockedUntil time.Time) error {
+	r.twoFactor.FailedAttempts++
+	if r.twoFactor.FailedAttempts >= maxAttempts {
+		r.twoFactor.FailedAttempts, r.twoFactor.LockedUntil = 0, &lockedUntil
+	}
+	return nil
+}
+
+func (r *fakeTwoFactorRepo) ReplaceRecoveryCodes(ctx context.Context, tx *gorm.DB, userID string, codes []entity.RecoveryCode) error {
+	r.recovery = codes
+	return nil
+}
+
+func (r *fakeTwoFactorRepo) UseRecoveryCode(ctx context.Context, tx *gorm.DB, userID string, codeHash string, now time.Time) (bool, error) {
+	for i, code := range r.recovery {
+		if code.CodeHash == codeHash && code.UsedAt == nil {
+			r.recovery[i].UsedAt = &now
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// enrolledTwoFactor enrolls and confirms a user and returns the service, the
+// repository, a context signed in as the user, and the TOTP secret.
+func enrolledTwoFactor(t *testing.T) (*twoFactorService, *fakeTwoFactorRepo, context.Context, string) {
+	t.Helper()
+	user := entity.User{ID: uuid.New(), PhoneNumber: "+6281234567890"}
+	repo := &fakeTwoFactorRepo{}
+	svc := NewTwoFactorService(repo, newFakeUserRepo(user), nil, nil, "key", metrics.Nop{}).(*twoFactorService)
+	ctx := auth.WithPrincipal(context.Background(), auth.Principal{UserID: user.ID.String()})
+
+	enrolled, err := svc.Enroll(ctx)
+	if err != nil {
+		t.Fatalf("Enroll() = %v", err)
+	}
+	if repo.twoFactor.Secret == enrolled.Secret {
+		t.Fatal("the TOTP secret is stored in the clear")
+	}
+
+	code, _ := totp.CodeAt(enrolled.Secret, totp.Step(time.Now()))
+	recovery, err := svc.Confirm(ctx, dto.TwoFactorCodeRequest{Code: code})
+	if err != nil {
+		t.Fatalf("Confirm() = %v", err)
+	}
+	if len(recovery.RecoveryCodes) != constants.ENUM_TOTP_RECOVERY_CODES {
+		t.Fatalf("Confirm() returned %d recovery codes", len(recovery.RecoveryCodes))
+	}
+	return svc, repo, ctx, enrolled.Secret
+}
+
+func TestTwoFactorCodeIsUsedOnce(t *testing.T) {
+	svc, repo, ctx, secret := enrolledTwoFactor(t)
+
+	if enabled, _ := svc.IsEnabled(ctx, repo.twoFactor.UserID.String()); !enabled {
+		t.Fatal("IsEnabled() = false after Confirm")
+	}
+
+	// The code that confirmed the factor cannot be replayed.
+	current, _ := totp.CodeAt(secret, totp.Step(time.Now()))
+	if _, err := svc.RegenerateRecoveryCodes(ctx, dto.TwoFactorCodeRequest{Code: current}); !errors.Is(err, dto.ErrTwoFactorCodeInvalid) {
+		t.Fatalf("replayed code = %v, want ErrTwoFactorCodeInvalid", err)
+	}
+
+	next, _ := totp.CodeAt(secret, totp.Step(time.Now())+1)
+	if _, err := svc.RegenerateRecoveryCodes(ctx, dto.TwoFactorCodeRequest{Code: next}); err != nil {
+		t.Errorf("code of the next step = %v", err)
+	}
+}
+
+func TestTwoFactorRecoveryCodeIsUsedOnce(t *testing.T) {
+	svc, repo, ctx, secret := enrolledTwoFactor(t)
+
+	next, _ := totp.CodeAt(secret, totp.Step(time.Now())+1)
+	codes, err := svc.RegenerateRecoveryCodes(ctx, dto.TwoFactorCodeRequest{Code: next})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tf := *repo.twoFactor
+	if err := svc.verify(ctx, &tf, "", codes.RecoveryCodes[0]); err != nil {
+		t.Fatalf("verify(recovery code) = %v", err)
+	}
+	if err := svc.verify(ctx, &tf, "", codes.RecoveryCodes[0]); !errors.Is(err, dto.ErrTwoFactorCodeInvalid) {
+		t.Errorf("verify(used recovery code) = %v, want ErrTwoFactorCodeInvalid", err)
+	}
+}
+
+func TestTwoFactorLocksAfterFailedAttempts(t *testing.T) {
+	svc, repo, ctx, secret := enrolledTwoFactor(t)
+
+	for i := 0; i < constants.ENUM_TOTP_MAX_ATTEMPT; i++ {
+		if _, err := svc.RegenerateRecoveryCodes(ctx, dto.TwoFactorCodeRequest{Code: "000000"}); !errors.Is(err, dto.ErrTwoFactorCodeInvalid) {
+			t.Fatalf("wrong code %d = %v", i+1, err)
+		}
+	}
+	if repo.twoFactor.LockedUntil == nil {
+		t.Fatalf("not locked after %d wrong codes", constants.ENUM_TOTP_MAX_ATTEMPT)
+	}
+
+	next, _ := totp.CodeAt(secret, totp.Step(time.Now())+1)
+	if _, err := svc.RegenerateRecoveryCodes(ctx, dto.TwoFactorCodeRequest{Code: next}); !errors.Is(err, dto.ErrTwoFactorLocked) {
+		t.Errorf("valid code while locked = %v, want ErrTwoFactorLocked", err)
+	}
+}
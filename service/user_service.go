@@ -2,14 +2,25 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"sort"
+	"strings"
 	"sync"
+	"time"
 
+	"github.com/Amierza/e-wallet/auth"
+	"github.com/Amierza/e-wallet/constants"
 	"github.com/Amierza/e-wallet/dto"
 	"github.com/Amierza/e-wallet/entity"
 	"github.com/Amierza/e-wallet/helpers"
+	"github.com/Amierza/e-wallet/metrics"
+	"github.com/Amierza/e-wallet/money"
+	"github.com/Amierza/e-wallet/notify"
 	"github.com/Amierza/e-wallet/repository"
+	"github.com/Amierza/e-wallet/view"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type (
@@ -22,10 +33,30 @@ type (
 		TransferUser(ctx context.Context, req dto.TransferRequest) (dto.TransferResponse, error)
 		GetAllTransactionWithPagination(ctx context.Context, req dto.PaginationRequest) (dto.TransactionPaginationResponse, error)
 		UpdateProfileUser(ctx context.Context, req dto.UpdateProfileRequest) (dto.UserResponse, error)
+		GetAllWallet(ctx context.Context) ([]dto.WalletResponse, error)
+		OpenWallet(ctx context.Context, req dto.OpenWalletRequest) (dto.WalletResponse, error)
+		VerifyPhone(ctx context.Context, req dto.VerifyPhoneRequest) (dto.UserResponse, error)
+		ResendPhoneOTP(ctx context.Context, req dto.ResendOTPRequest) (dto.OTPResponse, error)
+		VerifyEmail(ctx context.Context, req dto.VerifyEmailRequest) (dto.UserResponse, error)
+		ResendEmailVerification(ctx context.Context) (dto.EmailVerificationResponse, error)
+		SendStatement(ctx context.Context, req dto.StatementRequest) (dto.StatementResponse, error)
+		ForgotPin(ctx context.Context, req dto.ForgotPinRequest) (dto.OTPResponse, error)
+		VerifyPinReset(ctx context.Context, req dto.VerifyPinResetRequest) (dto.VerifyPinResetResponse, error)
+		ResetPin(ctx context.Context, req dto.ResetPinRequest) error
+		ChangePin(ctx context.Context, req dto.ChangePinRequest) (dto.UserLoginResponse, error)
 	}
 	userService struct {
 		userRepo   repository.UserRepository
+		fxRepo     repository.FXRepository
+		outboxRepo repository.OutboxRepository
+		transactor repository.Transactor
+		otpService OTPService
+		notifier   notify.Notifier
 		jwtService JWTService
+		twoFactor  TwoFactorService
+		devices    DeviceService
+		tokens     *SignedTokens
+		metrics    metrics.Metrics
 	}
 )
 
@@ -34,14 +65,22 @@ var (
 )
 
 const (
-	LOCAL_URL          = "http://localhost:8080"
 	VERIFY_EMAIL_ROUTE = "register/verify_email"
 )
 
-func NewUserService(userRepo repository.UserRepository, jwtService JWTService) UserService {
+func NewUserService(userRepo repository.UserRepository, fxRepo repository.FXRepository, outboxRepo repository.OutboxRepository, transactor repository.Transactor, otpService OTPService, notifier notify.Notifier, jwtService JWTService, twoFactor TwoFactorService, devices DeviceService, tokens *SignedTokens, metrics metrics.Metrics) UserService {
 	return &userService{
 		userRepo:   userRepo,
+		fxRepo:     fxRepo,
+		outboxRepo: outboxRepo,
+		transactor: transactor,
+		otpService: otpService,
+		notifier:   notifier,
 		jwtService: jwtService,
+		twoFactor:  twoFactor,
+		devices:    devices,
+		tokens:     tokens,
+		metrics:    metrics,
 	}
 }
 
@@ -55,33 +94,306 @@ func (s *userService) RegisterUser(ctx context.Context, req dto.UserCreateReques
 	}
 
 	user := entity.User{
+		ID:          uuid.New(),
 		FirstName:   req.FirstName,
 		LastName:    req.LastName,
 		PhoneNumber: req.PhoneNumber,
 		Address:     req.Address,
-		Pin:         req.Pin,
-		Balance:     0,
+		Wallets:     []entity.Wallet{newWallet(constants.ENUM_CURRENCY_DEFAULT)},
 	}
 
-	userReg, err := s.userRepo.RegisterUser(ctx, nil, user)
+	if err := SetPin(ctx, s.userRepo, &user, req.Pin); err != nil {
+		return dto.UserResponse{}, err
+	}
+
+	if req.Email != "" {
+		email := normalizeEmail(req.Email)
+		_, flag, err := s.userRepo.CheckEmail(ctx, nil, email)
+		if err == nil || flag {
+			return dto.UserResponse{}, dto.ErrEmailAlreadyExists
+		}
+		user.Email = &email
+	}
+
+	var userReg entity.User
+	err = s.transactor.WithTransaction(ctx, func(tx *gorm.DB) error {
+		created, err := s.userRepo.RegisterUser(ctx, tx, user)
+		if err != nil {
+			return dto.ErrCreateUser
+		}
+		userReg = created
+
+		return RecordPin(ctx, s.userRepo, tx, userReg)
+	})
 	if err != nil {
-		return dto.UserResponse{}, dto.ErrCreateUser
+		return dto.UserResponse{}, err
 	}
 
-	return dto.UserResponse{
-		ID:          userReg.ID.String(),
-		FirstName:   userReg.FirstName,
-		LastName:    userReg.LastName,
-		PhoneNumber: userReg.PhoneNumber,
-		Address:     userReg.Address,
-		Pin:         userReg.Pin,
-	}, nil
+	res := view.User(view.AudienceSelf, userReg)
+
+	// The account already exists at this point, so a failed send is not a
+	// failed registration; the user can ask for the code or link again.
+	otp, err := s.otpService.Issue(ctx, constants.ENUM_OTP_PURPOSE_PHONE_VERIFICATION, notify.ChannelSMS, userReg.PhoneNumber)
+	if err == nil {
+		res.Verification = &otp
+	}
+
+	if userReg.Email != nil {
+		s.sendEmailVerification(ctx, userReg)
+	}
+
+	return res, nil
 }
 
-func (s *userService) LoginUser(ctx context.Context, req dto.UserLoginRequest) (dto.UserLoginResponse, error) {
+func (s *userService) VerifyPhone(ctx context.Context, req dto.VerifyPhoneRequest) (dto.UserResponse, error) {
+	user, flag, err := s.userRepo.CheckPhoneNumber(ctx, nil, req.PhoneNumber)
+	if err != nil || !flag {
+		return dto.UserResponse{}, dto.ErrPhoneNumberNotFound
+	}
+
+	if user.PhoneVerifiedAt != nil {
+		return dto.UserResponse{}, dto.ErrPhoneAlreadyVerified
+	}
+
+	if err := s.otpService.Verify(ctx, constants.ENUM_OTP_PURPOSE_PHONE_VERIFICATION, user.PhoneNumber, req.Code); err != nil {
+		return dto.UserResponse{}, err
+	}
+
+	now := time.Now()
+	user.PhoneVerifiedAt = &now
+	if err := s.userRepo.UpdateUser(ctx, nil, user); err != nil {
+		return dto.UserResponse{}, dto.ErrUpdateUser
+	}
+
+	return view.User(view.AudienceSelf, user), nil
+}
+
+func (s *userService) ResendPhoneOTP(ctx context.Context, req dto.ResendOTPRequest) (dto.OTPResponse, error) {
+	user, flag, err := s.userRepo.CheckPhoneNumber(ctx, nil, req.PhoneNumber)
+	if err != nil || !flag {
+		return dto.OTPResponse{}, dto.ErrPhoneNumberNotFound
+	}
+
+	if user.PhoneVerifiedAt != nil {
+		return dto.OTPResponse{}, dto.ErrPhoneAlreadyVerified
+	}
+
+	return s.otpService.Issue(ctx, constants.ENUM_OTP_PURPOSE_PHONE_VERIFICATION, notify.ChannelSMS, user.PhoneNumber)
+}
+
+// ForgotPin answers the same whether or not the number has an account, so it
+// cannot be used to find out which numbers are registered. Unknown numbers
+// get a decoy code that is never sent, which makes cooldowns and
+// VerifyPinReset behave the same for them too.
+func (s *userService) ForgotPin(ctx context.Context, req dto.ForgotPinRequest) (dto.OTPResponse, error) {
+	_, flag, err := s.userRepo.CheckPhoneNumber(ctx, nil, req.PhoneNumber)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return dto.OTPResponse{}, dto.ErrCreateOTP
+	}
+	if !flag {
+		return s.otpService.IssueDecoy(ctx, constants.ENUM_OTP_PURPOSE_PIN_RESET, notify.ChannelSMS, req.PhoneNumber)
+	}
+
+	return s.otpService.Issue(ctx, constants.ENUM_OTP_PURPOSE_PIN_RESET, notify.ChannelSMS, req.PhoneNumber)
+}
+
+func (s *userService) VerifyPinReset(ctx context.Context, req dto.VerifyPinResetRequest) (dto.VerifyPinResetResponse, error) {
+	if err := s.otpService.Verify(ctx, constants.ENUM_OTP_PURPOSE_PIN_RESET, req.PhoneNumber, req.Code); err != nil {
+		return dto.VerifyPinResetResponse{}, err
+	}
+
+	// Only reachable for an unknown number by guessing a code nobody was sent.
 	user, flag, err := s.userRepo.CheckPhoneNumber(ctx, nil, req.PhoneNumber)
 	if err != nil || !flag {
-		return dto.UserLoginResponse{}, dto.ErrPhoneNumberNotFound
+		return dto.VerifyPinResetResponse{}, dto.ErrOTPInvalid
+	}
+
+	expiresAt := time.Now().Add(constants.ENUM_PIN_RESET_TOKEN_TTL_SECOND * time.Second)
+	return dto.VerifyPinResetResponse{
+		ResetToken: s.tokens.signPinResetToken(user.ID.String(), user.Pin, expiresAt),
+		ExpiresAt:  expiresAt,
+	}, nil
+}
+
+func (s *userService) ResetPin(ctx context.Context, req dto.ResetPinRequest) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	userID, fingerprint, err := s.tokens.parsePinResetToken(req.ResetToken, time.Now())
+	if err != nil {
+		return err
+	}
+
+	user, err := s.userRepo.FindUserByID(ctx, nil, userID)
+	if err != nil {
+		return dto.ErrGetUserFromUserID
+	}
+
+	// The PIN already changed since the token was issued, so it was used.
+	if pinFingerprint(user.Pin) != fingerprint {
+		return dto.ErrPinResetTokenInvalid
+	}
+
+	if err := SetPin(ctx, s.userRepo, &user, req.NewPin); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	user.SessionsRevokedAt = &now
+	if err := s.savePin(ctx, user); err != nil {
+		return err
+	}
+
+	if err := s.devices.RevokeOtherDevices(ctx, user.ID.String(), ""); err != nil {
+		return err
+	}
+
+	return recordSessionRevoked(ctx, s.outboxRepo, nil, user.ID, "", now)
+}
+
+// ChangePin revokes every session of the user and hands the caller a fresh
+// token pair, so only the device that changed the PIN stays signed in.
+func (s *userService) ChangePin(ctx context.Context, req dto.ChangePinRequest) (dto.UserLoginResponse, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	principal, ok := auth.FromContext(ctx)
+	if !ok {
+		return dto.UserLoginResponse{}, dto.ErrGetUserFromToken
+	}
+
+	user, err := s.userRepo.FindUserByID(ctx, nil, principal.UserID)
+	if err != nil {
+		return dto.UserLoginResponse{}, dto.ErrGetUserFromUserID
+	}
+
+	checkPin, err := helpers.ChcekPin(user.Pin, []byte(req.OldPin))
+	if err != nil || !checkPin {
+		return dto.UserLoginResponse{}, dto.ErrOldPinNotMatch
+	}
+
+	if err := SetPin(ctx, s.userRepo, &user, req.NewPin); err != nil {
+		return dto.UserLoginResponse{}, err
+	}
+
+	now := time.Now()
+	user.SessionsRevokedAt = &now
+	if err := s.savePin(ctx, user); err != nil {
+		return dto.UserLoginResponse{}, err
+	}
+
+	if err := s.devices.RevokeOtherDevices(ctx, user.ID.String(), principal.DeviceID); err != nil {
+		return dto.UserLoginResponse{}, err
+	}
+
+	// The caller's own token is revoked too, so its streams end as well and
+	// reconnect with the new one.
+	if err := recordSessionRevoked(ctx, s.outboxRepo, nil, user.ID, "", now); err != nil {
+		return dto.UserLoginResponse{}, err
+	}
+
+	accessToken, refreshToken, err := s.jwtService.GenerateToken(user.ID.String(), user.Role, principal.DeviceID)
+	if err != nil {
+		return dto.UserLoginResponse{}, err
+	}
+
+	return dto.UserLoginResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		DeviceID:     principal.DeviceID,
+	}, nil
+}
+
+// SetPin is the only place a PIN is turned into a hash. It enforces the PIN
+// policy and rejects any of the user's recent PINs, then stores the new hash
+// on the user; callers persist it with savePin or RecordPin.
+func SetPin(ctx context.Context, userRepo repository.UserRepository, user *entity.User, pin string) error {
+	if err := validatePin(pin); err != nil {
+		return err
+	}
+
+	previous := []string{}
+	if user.Pin != "" {
+		previous = append(previous, user.Pin)
+	}
+
+	histories, err := userRepo.GetRecentPinHistory(ctx, nil, user.ID.String(), constants.ENUM_PIN_HISTORY)
+	if err != nil {
+		return dto.ErrGetUserFromUserID
+	}
+	for _, history := range histories {
+		previous = append(previous, history.PinHash)
+	}
+
+	for _, hash := range previous {
+		if same, _ := helpers.ChcekPin(hash, []byte(pin)); same {
+			return dto.ErrPinReused
+		}
+	}
+
+	hashedPin, err := helpers.HashPin(pin)
+	if err != nil {
+		return dto.ErrHashPin
+	}
+
+	user.Pin = hashedPin
+	return nil
+}
+
+func (s *userService) savePin(ctx context.Context, user entity.User) error {
+	return s.transactor.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := s.userRepo.UpdateUser(ctx, tx, user); err != nil {
+			return dto.ErrUpdateUser
+		}
+
+		return RecordPin(ctx, s.userRepo, tx, user)
+	})
+}
+
+// RecordPin adds the user's current PIN hash to their history, so SetPin
+// refuses it later.
+func RecordPin(ctx context.Context, userRepo repository.UserRepository, tx *gorm.DB, user entity.User) error {
+	history := entity.PinHistory{
+		ID:      uuid.New(),
+		UserID:  user.ID,
+		PinHash: user.Pin,
+	}
+	if err := userRepo.CreatePinHistory(ctx, tx, history); err != nil {
+		return dto.ErrUpdateUser
+	}
+
+	return nil
+}
+
+func (s *userService) LoginUser(ctx context.Context, req dto.UserLoginRequest) (dto.UserLoginResponse, error) {
+	res, err := s.login(ctx, req)
+	if err != nil {
+		s.metrics.LoginFailed(err)
+	}
+
+	return res, err
+}
+
+func (s *userService) login(ctx context.Context, req dto.UserLoginRequest) (dto.UserLoginResponse, error) {
+	var (
+		user entity.User
+		flag bool
+		err  error
+	)
+
+	switch {
+	case req.Email != "":
+		user, flag, err = s.userRepo.CheckEmail(ctx, nil, normalizeEmail(req.Email))
+		if err != nil || !flag {
+			return dto.UserLoginResponse{}, dto.ErrEmailNotFound
+		}
+	case req.PhoneNumber != "":
+		user, flag, err = s.userRepo.CheckPhoneNumber(ctx, nil, req.PhoneNumber)
+		if err != nil || !flag {
+			return dto.UserLoginResponse{}, dto.ErrPhoneNumberNotFound
+		}
+	default:
+		return dto.UserLoginResponse{}, dto.ErrLoginIdentifierRequired
 	}
 
 	checkPin, err := helpers.ChcekPin(user.Pin, []byte(req.Pin))
@@ -89,7 +401,53 @@ func (s *userService) LoginUser(ctx context.Context, req dto.UserLoginRequest) (
 		return dto.UserLoginResponse{}, dto.ErrPinNotMatch
 	}
 
-	accessToken, refreshToken, err := s.jwtService.GenerateToken(user.ID.String())
+	if user.FrozenAt != nil {
+		return dto.UserLoginResponse{}, dto.ErrAccountFrozen
+	}
+
+	// The plain PIN is only available here, so this is where hashes made
+	// with older parameters get upgraded. A failed upgrade is retried on the
+	// next login rather than failing this one.
+	if helpers.PinNeedsRehash(user.Pin) {
+		if hashedPin, err := helpers.HashPin(req.Pin); err == nil {
+			s.userRepo.UpdatePin(ctx, nil, user.ID.String(), hashedPin)
+		}
+	}
+
+	// Whichever identifier was used to log in must be one the user proved
+	// they own.
+	if req.Email != "" && user.EmailVerifiedAt == nil {
+		return dto.UserLoginResponse{}, dto.ErrEmailNotVerified
+	}
+	if req.Email == "" && user.PhoneVerifiedAt == nil {
+		return dto.UserLoginResponse{}, dto.ErrPhoneNotVerified
+	}
+
+	// With two-factor enabled the PIN alone only earns a short-lived
+	// challenge; tokens are issued once it is completed with a code.
+	enabled, err := s.twoFactor.IsEnabled(ctx, user.ID.String())
+	if err != nil {
+		return dto.UserLoginResponse{}, err
+	}
+	if enabled {
+		challengeToken, expiresAt, err := s.jwtService.GenerateChallengeToken(user.ID.String())
+		if err != nil {
+			return dto.UserLoginResponse{}, err
+		}
+
+		return dto.UserLoginResponse{
+			TwoFactorRequired:  true,
+			ChallengeToken:     challengeToken,
+			ChallengeExpiresAt: &expiresAt,
+		}, nil
+	}
+
+	device, err := s.devices.Register(ctx, user, req.DeviceInfo)
+	if err != nil {
+		return dto.UserLoginResponse{}, err
+	}
+
+	accessToken, refreshToken, err := s.jwtService.GenerateToken(user.ID.String(), user.Role, device.ID.String())
 	if err != nil {
 		return dto.UserLoginResponse{}, err
 	}
@@ -97,16 +455,22 @@ func (s *userService) LoginUser(ctx context.Context, req dto.UserLoginRequest) (
 	return dto.UserLoginResponse{
 		AccessToken:  accessToken,
 		RefreshToken: refreshToken,
+		DeviceID:     device.ID.String(),
 	}, nil
 }
 
 func (s *userService) TopUpUser(ctx context.Context, req dto.TopUpRequest) (dto.TopUpResponse, error) {
+	res, err := s.topUp(ctx, req)
+	s.observeTransaction(constants.ENUM_TRANSACTION_TOPUP, req.Amount, err)
+
+	return res, err
+}
+
+func (s *userService) topUp(ctx context.Context, req dto.TopUpRequest) (dto.TopUpResponse, error) {
 	mu.Lock()
 	defer mu.Unlock()
 
-	token := ctx.Value("Authorization").(string)
-
-	userID, err := s.jwtService.GetUserIDByToken(token)
+	userID, err := auth.UserID(ctx)
 	if err != nil {
 		return dto.TopUpResponse{}, dto.ErrGetUserFromToken
 	}
@@ -116,40 +480,72 @@ func (s *userService) TopUpUser(ctx context.Context, req dto.TopUpRequest) (dto.
 		return dto.TopUpResponse{}, dto.ErrGetUserFromUserID
 	}
 
-	balanceBefore := user.Balance
-	user.Balance += req.Amount
-
-	if err := s.userRepo.UpdateUser(ctx, nil, user); err != nil {
-		return dto.TopUpResponse{}, dto.ErrUpdateUserBalance
+	if !req.Amount.IsPositive() {
+		return dto.TopUpResponse{}, dto.ErrInvalidAmount
 	}
 
-	newTopup := entity.TopUp{
-		ID:            uuid.New(),
-		UserID:        user.ID,
-		Amount:        req.Amount,
-		BalanceBefore: balanceBefore,
-		BalanceAfter:  user.Balance,
-	}
+	var res dto.TopUpResponse
+	err = s.transactor.WithTransaction(ctx, func(tx *gorm.DB) error {
+		wallet, flag, err := s.userRepo.FindWallet(ctx, tx, user.ID.String(), req.Amount.Currency)
+		if err != nil || !flag {
+			return dto.ErrWalletNotFound
+		}
+
+		balanceBefore := wallet.Balance
+		wallet.Balance, err = wallet.Balance.Add(req.Amount)
+		if err != nil {
+			return dto.ErrUpdateWalletBalance
+		}
+
+		if err := s.userRepo.UpdateWallet(ctx, tx, wallet); err != nil {
+			return dto.ErrUpdateWalletBalance
+		}
+
+		newTopup := entity.TopUp{
+			ID:            uuid.New(),
+			UserID:        user.ID,
+			Currency:      wallet.Currency,
+			Amount:        req.Amount,
+			BalanceBefore: balanceBefore,
+			BalanceAfter:  wallet.Balance,
+		}
+
+		if err := s.userRepo.CreateTopUp(ctx, tx, newTopup); err != nil {
+			return dto.ErrCreateTopUp
+		}
+
+		res = dto.TopUpResponse{
+			ID:            newTopup.ID.String(),
+			Currency:      wallet.Currency,
+			AmountTopUp:   req.Amount,
+			BalanceBefore: balanceBefore,
+			BalanceAfter:  wallet.Balance,
+		}
 
-	if err := s.userRepo.CreateTopUp(ctx, nil, newTopup); err != nil {
-		return dto.TopUpResponse{}, dto.ErrCreateTopUp
+		return s.recordEvent(ctx, tx, constants.ENUM_EVENT_TOPUP_COMPLETED, user.ID, dto.TopUpCompletedEvent{
+			UserID:        user.ID.String(),
+			TopUpResponse: res,
+		})
+	})
+	if err != nil {
+		return dto.TopUpResponse{}, err
 	}
 
-	return dto.TopUpResponse{
-		ID:            newTopup.ID.String(),
-		AmountTopUp:   req.Amount,
-		BalanceBefore: balanceBefore,
-		BalanceAfter:  user.Balance,
-	}, nil
+	return res, nil
 }
 
 func (s *userService) PaymentUser(ctx context.Context, req dto.PaymentRequest) (dto.PaymentResponse, error) {
+	res, err := s.payment(ctx, req)
+	s.observeTransaction(constants.ENUM_TRANSACTION_PAYMENT, req.Amount, err)
+
+	return res, err
+}
+
+func (s *userService) payment(ctx context.Context, req dto.PaymentRequest) (dto.PaymentResponse, error) {
 	mu.Lock()
 	defer mu.Unlock()
 
-	token := ctx.Value("Authorization").(string)
-
-	userID, err := s.jwtService.GetUserIDByToken(token)
+	userID, err := auth.UserID(ctx)
 	if err != nil {
 		return dto.PaymentResponse{}, dto.ErrGetUserFromToken
 	}
@@ -159,46 +555,74 @@ func (s *userService) PaymentUser(ctx context.Context, req dto.PaymentRequest) (
 		return dto.PaymentResponse{}, dto.ErrGetUserFromUserID
 	}
 
-	if user.Balance < req.Amount {
-		return dto.PaymentResponse{}, dto.ErrInsufficientBalance
+	if !req.Amount.IsPositive() {
+		return dto.PaymentResponse{}, dto.ErrInvalidAmount
 	}
 
-	balanceBefore := user.Balance
-	user.Balance -= req.Amount
+	var res dto.PaymentResponse
+	err = s.transactor.WithTransaction(ctx, func(tx *gorm.DB) error {
+		wallet, flag, err := s.userRepo.FindWallet(ctx, tx, user.ID.String(), req.Amount.Currency)
+		if err != nil || !flag {
+			return dto.ErrWalletNotFound
+		}
+
+		balanceBefore := wallet.Balance
+		wallet.Balance, err = wallet.Balance.Sub(req.Amount)
+		if err != nil || wallet.Balance.IsNegative() {
+			return dto.ErrInsufficientBalance
+		}
 
-	if err := s.userRepo.UpdateUser(ctx, nil, user); err != nil {
-		return dto.PaymentResponse{}, dto.ErrUpdateUserBalance
-	}
+		if err := s.userRepo.UpdateWallet(ctx, tx, wallet); err != nil {
+			return dto.ErrUpdateWalletBalance
+		}
 
-	newPayment := entity.Payment{
-		ID:            uuid.New(),
-		UserID:        user.ID,
-		Amount:        req.Amount,
-		Remarks:       req.Remarks,
-		BalanceBefore: balanceBefore,
-		BalanceAfter:  user.Balance,
-	}
+		newPayment := entity.Payment{
+			ID:            uuid.New(),
+			UserID:        user.ID,
+			Currency:      wallet.Currency,
+			Amount:        req.Amount,
+			Remarks:       req.Remarks,
+			BalanceBefore: balanceBefore,
+			BalanceAfter:  wallet.Balance,
+		}
+
+		if err := s.userRepo.CreatePayment(ctx, tx, newPayment); err != nil {
+			return dto.ErrCreatePayment
+		}
 
-	if err := s.userRepo.CreatePayment(ctx, nil, newPayment); err != nil {
-		return dto.PaymentResponse{}, dto.ErrCreatePayment
+		res = dto.PaymentResponse{
+			ID:            newPayment.ID.String(),
+			Currency:      wallet.Currency,
+			AmountPayment: req.Amount,
+			Remarks:       req.Remarks,
+			BalanceBefore: balanceBefore,
+			BalanceAfter:  wallet.Balance,
+		}
+
+		return s.recordEvent(ctx, tx, constants.ENUM_EVENT_PAYMENT_COMPLETED, user.ID, dto.PaymentCompletedEvent{
+			UserID:          user.ID.String(),
+			PaymentResponse: res,
+		})
+	})
+	if err != nil {
+		return dto.PaymentResponse{}, err
 	}
 
-	return dto.PaymentResponse{
-		ID:            newPayment.ID.String(),
-		AmountPayment: req.Amount,
-		Remarks:       req.Remarks,
-		BalanceBefore: balanceBefore,
-		BalanceAfter:  user.Balance,
-	}, nil
+	return res, nil
 }
 
 func (s *userService) TransferUser(ctx context.Context, req dto.TransferRequest) (dto.TransferResponse, error) {
+	res, err := s.transfer(ctx, req)
+	s.observeTransaction(constants.ENUM_TRANSACTION_TRANSFER, req.Amount, err)
+
+	return res, err
+}
+
+func (s *userService) transfer(ctx context.Context, req dto.TransferRequest) (dto.TransferResponse, error) {
 	mu.Lock()
 	defer mu.Unlock()
 
-	token := ctx.Value("Authorization").(string)
-
-	userID, err := s.jwtService.GetUserIDByToken(token)
+	userID, err := auth.UserID(ctx)
 	if err != nil {
 		return dto.TransferResponse{}, dto.ErrGetUserFromToken
 	}
@@ -217,68 +641,162 @@ func (s *userService) TransferUser(ctx context.Context, req dto.TransferRequest)
 		return dto.TransferResponse{}, dto.ErrCannotTransferToOwnAccount
 	}
 
-	if user.Balance < req.Amount {
-		return dto.TransferResponse{}, dto.ErrInsufficientBalance
+	if !req.Amount.IsPositive() {
+		return dto.TransferResponse{}, dto.ErrInvalidAmount
 	}
 
-	balanceBefore := user.Balance
+	targetAmount := req.Amount
 
-	user.Balance -= req.Amount
+	var quote entity.FXQuote
+	if req.QuoteID != nil {
+		quote, err = s.fxRepo.FindQuoteByID(ctx, nil, req.QuoteID.String())
+		if err != nil || quote.UserID != user.ID {
+			return dto.TransferResponse{}, dto.ErrFXQuoteNotFound
+		}
 
-	targetUser.Balance += req.Amount
+		if quote.UsedAt != nil {
+			return dto.TransferResponse{}, dto.ErrFXQuoteAlreadyUsed
+		}
 
-	if err := s.userRepo.UpdateUser(ctx, nil, user); err != nil {
-		return dto.TransferResponse{}, dto.ErrUpdateUserBalance
-	}
+		if time.Now().After(quote.ExpiresAt) {
+			return dto.TransferResponse{}, dto.ErrFXQuoteExpired
+		}
 
-	if err := s.userRepo.UpdateUser(ctx, nil, targetUser); err != nil {
-		return dto.TransferResponse{}, dto.ErrUpdateUserBalance
-	}
+		if req.Amount != quote.SourceAmount {
+			return dto.TransferResponse{}, dto.ErrFXQuoteMismatch
+		}
 
-	newTransfer := entity.Transfer{
-		ID:            uuid.New(),
-		UserID:        user.ID,
-		TargetUserID:  req.TargetUser,
-		Amount:        req.Amount,
-		Remarks:       req.Remarks,
-		BalanceBefore: balanceBefore,
-		BalanceAfter:  user.Balance,
+		targetAmount = quote.TargetAmount
 	}
 
-	if err := s.userRepo.CreateTransfer(ctx, nil, newTransfer); err != nil {
-		return dto.TransferResponse{}, dto.ErrCreateTransfer
+	var res dto.TransferResponse
+	err = s.transactor.WithTransaction(ctx, func(tx *gorm.DB) error {
+		wallet, flag, err := s.userRepo.FindWallet(ctx, tx, user.ID.String(), req.Amount.Currency)
+		if err != nil || !flag {
+			return dto.ErrWalletNotFound
+		}
+
+		targetWallet, flag, err := s.userRepo.FindWallet(ctx, tx, targetUser.ID.String(), targetAmount.Currency)
+		if err != nil || !flag {
+			return dto.ErrTargetWalletNotFound
+		}
+
+		balanceBefore := wallet.Balance
+
+		wallet.Balance, err = wallet.Balance.Sub(req.Amount)
+		if err != nil || wallet.Balance.IsNegative() {
+			return dto.ErrInsufficientBalance
+		}
+
+		targetWallet.Balance, err = targetWallet.Balance.Add(targetAmount)
+		if err != nil {
+			return dto.ErrUpdateWalletBalance
+		}
+
+		if req.QuoteID != nil {
+			used, err := s.fxRepo.MarkQuoteUsed(ctx, tx, quote.ID.String(), time.Now())
+			if err != nil || !used {
+				return dto.ErrFXQuoteAlreadyUsed
+			}
+		}
+
+		if err := s.userRepo.UpdateWallet(ctx, tx, wallet); err != nil {
+			return dto.ErrUpdateWalletBalance
+		}
+
+		if err := s.userRepo.UpdateWallet(ctx, tx, targetWallet); err != nil {
+			return dto.ErrUpdateWalletBalance
+		}
+
+		newTransfer := entity.Transfer{
+			ID:             uuid.New(),
+			UserID:         user.ID,
+			TargetUserID:   req.TargetUser,
+			Currency:       req.Amount.Currency,
+			Amount:         req.Amount,
+			TargetCurrency: targetAmount.Currency,
+			TargetAmount:   targetAmount,
+			QuoteID:        req.QuoteID,
+			Remarks:        req.Remarks,
+			BalanceBefore:  balanceBefore,
+			BalanceAfter:   wallet.Balance,
+		}
+
+		if err := s.userRepo.CreateTransfer(ctx, tx, newTransfer); err != nil {
+			return dto.ErrCreateTransfer
+		}
+
+		res = dto.TransferResponse{
+			ID:             newTransfer.ID.String(),
+			TargetUserID:   req.TargetUser.String(),
+			Currency:       req.Amount.Currency,
+			AmountTransfer: req.Amount,
+			TargetCurrency: targetAmount.Currency,
+			TargetAmount:   targetAmount,
+			Remarks:        req.Remarks,
+			BalanceBefore:  balanceBefore,
+			BalanceAfter:   wallet.Balance,
+		}
+
+		if req.QuoteID != nil {
+			revenue := entity.FXRevenue{
+				ID:         uuid.New(),
+				QuoteID:    quote.ID,
+				TransferID: newTransfer.ID,
+				Currency:   quote.ToCurrency,
+				Amount:     quote.SpreadAmount,
+			}
+
+			if err := s.fxRepo.CreateRevenue(ctx, tx, revenue); err != nil {
+				return dto.ErrCreateFXRevenue
+			}
+
+			res.QuoteID = quote.ID.String()
+		}
+
+		if err := s.recordEvent(ctx, tx, constants.ENUM_EVENT_TRANSFER_COMPLETED, user.ID, dto.TransferCompletedEvent{
+			UserID:           user.ID.String(),
+			TransferResponse: res,
+		}); err != nil {
+			return err
+		}
+
+		return s.recordEvent(ctx, tx, constants.ENUM_EVENT_TRANSFER_RECEIVED, targetUser.ID, dto.TransferReceivedEvent{
+			UserID:       targetUser.ID.String(),
+			TransferID:   newTransfer.ID.String(),
+			SenderUserID: user.ID.String(),
+			Amount:       targetAmount,
+			Remarks:      req.Remarks,
+			BalanceAfter: targetWallet.Balance,
+			ReceivedAt:   time.Now(),
+		})
+	})
+	if err != nil {
+		return dto.TransferResponse{}, err
 	}
 
-	return dto.TransferResponse{
-		ID:             newTransfer.ID.String(),
-		TargetUserID:   req.TargetUser.String(),
-		AmountTransfer: req.Amount,
-		Remarks:        req.Remarks,
-		BalanceBefore:  balanceBefore,
-		BalanceAfter:   user.Balance,
-	}, nil
+	return res, nil
+}
+
+// observeTransaction counts a money movement by its outcome. Volume is
+// counted in the currency that left the payer's wallet.
+func (s *userService) observeTransaction(kind string, amount money.Amount, err error) {
+	if err != nil {
+		s.metrics.TransactionFailed(kind, err)
+		return
+	}
+	s.metrics.TransactionSucceeded(kind, amount)
 }
 
 func (s *userService) GetAllUserWithPagination(ctx context.Context, req dto.PaginationRequest) (dto.UserPaginationResponse, error) {
-	dataWithPaginate, err := s.userRepo.GetAllUsersWithPagination(ctx, nil, req)
+	dataWithPaginate, err := s.userRepo.GetAllUsersWithPagination(ctx, nil, req, auth.IsAdmin(ctx))
 	if err != nil {
 		return dto.UserPaginationResponse{}, err
 	}
 
-	var datas []dto.AllUserResponse
-	for _, user := range dataWithPaginate.Users {
-		data := dto.AllUserResponse{
-			ID:          user.ID.String(),
-			FirstName:   user.FirstName,
-			LastName:    user.LastName,
-			PhoneNumber: user.PhoneNumber,
-			Address:     user.Address,
-			Pin:         user.Pin,
-			Balance:     user.Balance,
-		}
-
-		datas = append(datas, data)
-	}
+	datas := view.Users(func(user entity.User) view.Audience {
+		return view.AudienceFor(ctx, user.ID.String())
+	}, dataWithPaginate.Users)
 
 	return dto.UserPaginationResponse{
 		Data: datas,
@@ -302,6 +820,7 @@ func (s *userService) GetAllTransactionWithPagination(ctx context.Context, req d
 		transaction := dto.AllTransactionResponse{
 			TopUpID:       topup.ID.String(),
 			UserID:        topup.UserID.String(),
+			Currency:      topup.Currency,
 			Amount:        topup.Amount,
 			BalanceBefore: &topup.BalanceBefore,
 			BalanceAfter:  topup.BalanceAfter,
@@ -319,6 +838,7 @@ func (s *userService) GetAllTransactionWithPagination(ctx context.Context, req d
 		transaction := dto.AllTransactionResponse{
 			PaymentID:     payment.ID.String(),
 			UserID:        payment.UserID.String(),
+			Currency:      payment.Currency,
 			Amount:        payment.Amount,
 			Remarks:       payment.Remarks,
 			BalanceBefore: &payment.BalanceBefore,
@@ -338,6 +858,7 @@ func (s *userService) GetAllTransactionWithPagination(ctx context.Context, req d
 			TransferID:    transfer.ID.String(),
 			UserID:        transfer.UserID.String(),
 			TargetUserID:  transfer.TargetUserID.String(),
+			Currency:      transfer.Currency,
 			Amount:        transfer.Amount,
 			Remarks:       transfer.Remarks,
 			BalanceBefore: &transfer.BalanceBefore,
@@ -371,9 +892,7 @@ func (s *userService) UpdateProfileUser(ctx context.Context, req dto.UpdateProfi
 	mu.Lock()
 	defer mu.Unlock()
 
-	token := ctx.Value("Authorization").(string)
-
-	userID, err := s.jwtService.GetUserIDByToken(token)
+	userID, err := auth.UserID(ctx)
 	if err != nil {
 		return dto.UserResponse{}, dto.ErrGetUserFromToken
 	}
@@ -391,43 +910,269 @@ func (s *userService) UpdateProfileUser(ctx context.Context, req dto.UpdateProfi
 		req.LastName = user.LastName
 	}
 
-	if req.PhoneNumber == "" {
+	phoneVerifiedAt := user.PhoneVerifiedAt
+	if req.PhoneNumber == "" || req.PhoneNumber == user.PhoneNumber {
 		req.PhoneNumber = user.PhoneNumber
 	} else {
 		_, flag, err := s.userRepo.CheckPhoneNumber(ctx, nil, req.PhoneNumber)
 		if err == nil || flag {
 			return dto.UserResponse{}, dto.ErrPhoneNumberAlreadyExists
 		}
+		// A new number has to be verified again before the next login.
+		phoneVerifiedAt = nil
+	}
+
+	email, emailVerifiedAt := user.Email, user.EmailVerifiedAt
+	if req.Email != "" && (user.Email == nil || normalizeEmail(req.Email) != *user.Email) {
+		newEmail := normalizeEmail(req.Email)
+		_, flag, err := s.userRepo.CheckEmail(ctx, nil, newEmail)
+		if err == nil || flag {
+			return dto.UserResponse{}, dto.ErrEmailAlreadyExists
+		}
+		email, emailVerifiedAt = &newEmail, nil
 	}
 
 	if req.Address == "" {
 		req.Address = user.Address
 	}
 
-	if req.Pin == "" {
-		req.Pin = user.Pin
+	// Only profile fields change here; the PIN has its own endpoint and the
+	// rest of the row is written back untouched.
+	updatedUser := user
+	updatedUser.FirstName = req.FirstName
+	updatedUser.LastName = req.LastName
+	updatedUser.PhoneNumber = req.PhoneNumber
+	updatedUser.Address = req.Address
+	updatedUser.PhoneVerifiedAt = phoneVerifiedAt
+	updatedUser.Email = email
+	updatedUser.EmailVerifiedAt = emailVerifiedAt
+
+	if err := s.userRepo.UpdateUser(ctx, nil, updatedUser); err != nil {
+		return dto.UserResponse{}, dto.ErrUpdateUser
+	}
+
+	var verification *dto.OTPResponse
+	if phoneVerifiedAt == nil {
+		if otp, err := s.otpService.Issue(ctx, constants.ENUM_OTP_PURPOSE_PHONE_VERIFICATION, notify.ChannelSMS, updatedUser.PhoneNumber); err == nil {
+			verification = &otp
+		}
 	}
 
-	updatedUser := entity.User{
-		ID:          user.ID,
-		FirstName:   req.FirstName,
-		LastName:    req.LastName,
-		PhoneNumber: req.PhoneNumber,
-		Address:     req.Address,
-		Pin:         req.Pin,
-		Balance:     user.Balance,
+	if updatedUser.Email != nil && (user.Email == nil || *user.Email != *updatedUser.Email) {
+		s.sendEmailVerification(ctx, updatedUser)
 	}
 
-	if err := s.userRepo.UpdateUser(ctx, nil, updatedUser); err != nil {
+	res := view.User(view.AudienceSelf, updatedUser)
+	res.Verification = verification
+	return res, nil
+}
+
+func (s *userService) VerifyEmail(ctx context.Context, req dto.VerifyEmailRequest) (dto.UserResponse, error) {
+	userID, email, err := s.tokens.parseEmailToken(req.Token, time.Now())
+	if err != nil {
+		return dto.UserResponse{}, err
+	}
+
+	user, err := s.userRepo.FindUserByID(ctx, nil, userID)
+	if err != nil {
+		return dto.UserResponse{}, dto.ErrGetUserFromUserID
+	}
+
+	// The link was issued for an address the user has since replaced.
+	if user.Email == nil || *user.Email != email {
+		return dto.UserResponse{}, dto.ErrVerificationLinkInvalid
+	}
+
+	if user.EmailVerifiedAt != nil {
+		return dto.UserResponse{}, dto.ErrEmailAlreadyVerified
+	}
+
+	now := time.Now()
+	user.EmailVerifiedAt = &now
+	if err := s.userRepo.UpdateUser(ctx, nil, user); err != nil {
 		return dto.UserResponse{}, dto.ErrUpdateUser
 	}
 
-	return dto.UserResponse{
-		ID:          updatedUser.ID.String(),
-		FirstName:   updatedUser.FirstName,
-		LastName:    updatedUser.LastName,
-		PhoneNumber: updatedUser.PhoneNumber,
-		Address:     updatedUser.Address,
-		Pin:         updatedUser.Pin,
+	return view.User(view.AudienceSelf, user), nil
+}
+
+func (s *userService) ResendEmailVerification(ctx context.Context) (dto.EmailVerificationResponse, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return dto.EmailVerificationResponse{}, dto.ErrGetUserFromToken
+	}
+
+	user, err := s.userRepo.FindUserByID(ctx, nil, userID)
+	if err != nil {
+		return dto.EmailVerificationResponse{}, dto.ErrGetUserFromUserID
+	}
+
+	if user.Email == nil {
+		return dto.EmailVerificationResponse{}, dto.ErrEmailNotSet
+	}
+
+	if user.EmailVerifiedAt != nil {
+		return dto.EmailVerificationResponse{}, dto.ErrEmailAlreadyVerified
+	}
+
+	return s.sendEmailVerification(ctx, user)
+}
+
+func (s *userService) sendEmailVerification(ctx context.Context, user entity.User) (dto.EmailVerificationResponse, error) {
+	expiresAt := time.Now().Add(constants.ENUM_EMAIL_VERIFICATION_TTL_SECOND * time.Second)
+	link := s.tokens.emailVerificationLink(s.tokens.signEmailToken(user.ID.String(), *user.Email, expiresAt))
+
+	msg := notify.Message{
+		Channel: notify.ChannelEmail,
+		To:      *user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Hi %s,\n\nConfirm this address for your e-wallet account by opening the link below. It expires on %s.\n\n%s\n\nIf you did not request this, you can ignore this email.\n",
+			user.FirstName, expiresAt.Format(time.RFC1123), link),
+	}
+	if err := s.notifier.Send(ctx, msg); err != nil {
+		return dto.EmailVerificationResponse{}, dto.ErrSendEmail
+	}
+
+	return dto.EmailVerificationResponse{
+		Email:     view.MaskEmail(*user.Email),
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+func (s *userService) SendStatement(ctx context.Context, req dto.StatementRequest) (dto.StatementResponse, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return dto.StatementResponse{}, dto.ErrGetUserFromToken
+	}
+
+	user, err := s.userRepo.FindUserByID(ctx, nil, userID)
+	if err != nil {
+		return dto.StatementResponse{}, dto.ErrGetUserFromUserID
+	}
+
+	if user.Email == nil {
+		return dto.StatementResponse{}, dto.ErrEmailNotSet
+	}
+
+	if user.EmailVerifiedAt == nil {
+		return dto.StatementResponse{}, dto.ErrEmailNotVerified
+	}
+
+	from, to, err := statementPeriod(req, time.Now())
+	if err != nil {
+		return dto.StatementResponse{}, err
+	}
+
+	transactions, err := s.userRepo.GetUserTransactionsBetween(ctx, nil, userID, from, to)
+	if err != nil {
+		return dto.StatementResponse{}, dto.ErrGetTransactions
+	}
+
+	msg := notify.Message{
+		Channel: notify.ChannelEmail,
+		To:      *user.Email,
+		Subject: fmt.Sprintf("Your e-wallet statement %s - %s", from.Format(time.DateOnly), to.AddDate(0, 0, -1).Format(time.DateOnly)),
+		Body:    formatStatement(user, from, to, transactions),
+	}
+	if err := s.notifier.Send(ctx, msg); err != nil {
+		return dto.StatementResponse{}, dto.ErrSendEmail
+	}
+
+	return dto.StatementResponse{
+		Email:        view.MaskEmail(*user.Email),
+		From:         from,
+		To:           to,
+		Transactions: int(transactions.Count),
 	}, nil
 }
+
+func (s *userService) GetAllWallet(ctx context.Context) ([]dto.WalletResponse, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, dto.ErrGetUserFromToken
+	}
+
+	wallets, err := s.userRepo.GetWalletsByUserID(ctx, nil, userID)
+	if err != nil {
+		return nil, dto.ErrGetWallets
+	}
+
+	return view.Wallets(wallets), nil
+}
+
+func (s *userService) OpenWallet(ctx context.Context, req dto.OpenWalletRequest) (dto.WalletResponse, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return dto.WalletResponse{}, dto.ErrGetUserFromToken
+	}
+
+	user, err := s.userRepo.FindUserByID(ctx, nil, userID)
+	if err != nil {
+		return dto.WalletResponse{}, dto.ErrGetUserFromUserID
+	}
+
+	currency, err := resolveCurrency(req.Currency)
+	if err != nil {
+		return dto.WalletResponse{}, err
+	}
+
+	_, flag, err := s.userRepo.FindWallet(ctx, nil, user.ID.String(), currency)
+	if err == nil || flag {
+		return dto.WalletResponse{}, dto.ErrWalletAlreadyExists
+	}
+
+	wallet := newWallet(currency)
+	wallet.UserID = user.ID
+
+	wallet, err = s.userRepo.CreateWallet(ctx, nil, wallet)
+	if err != nil {
+		return dto.WalletResponse{}, dto.ErrCreateWallet
+	}
+
+	return view.Wallet(wallet), nil
+}
+
+// recordEvent writes a domain event to the outbox inside tx, so it is
+// published if and only if the balance change it describes commits.
+func (s *userService) recordEvent(ctx context.Context, tx *gorm.DB, eventType string, userID uuid.UUID, data any) error {
+	outboxEvent, err := newOutboxEvent(eventType, userID, data)
+	if err != nil {
+		return dto.ErrRecordEvent
+	}
+
+	if err := s.outboxRepo.CreateEvent(ctx, tx, outboxEvent); err != nil {
+		return dto.ErrRecordEvent
+	}
+
+	return nil
+}
+
+func resolveCurrency(currency string) (string, error) {
+	if currency == "" {
+		return constants.ENUM_CURRENCY_DEFAULT, nil
+	}
+
+	currency = money.NormalizeCurrency(currency)
+	if !money.IsSupported(currency) {
+		return "", dto.ErrUnsupportedCurrency
+	}
+
+	return currency, nil
+}
+
+func newWallet(currency string) entity.Wallet {
+	minorUnit, _ := money.MinorUnit(currency)
+	return entity.Wallet{
+		ID:        uuid.New(),
+		Currency:  currency,
+		MinorUnit: minorUnit,
+		Balance:   money.Zero(currency),
+	}
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
@@ -0,0 +1,43 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Amierza/e-wallet/auth"
+	"github.com/Amierza/e-wallet/constants"
+	"github.com/Amierza/e-wallet/dto"
+	"github.com/Amierza/e-wallet/metrics"
+	"github.com/Amierza/e-wallet/repository"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type searchRecorder struct {
+	repository.UserRepository
+
+	searchContact []bool
+}
+
+func (r *searchRecorder) GetAllUsersWithPagination(ctx context.Context, tx *gorm.DB, req dto.PaginationRequest, searchContact bool) (dto.GetAllUserRepositoryResponse, error) {
+	r.searchContact = append(r.searchContact, searchContact)
+	return dto.GetAllUserRepositoryResponse{}, nil
+}
+
+func TestUserSearchOnlyMatchesContactDetailsForAdmins(t *testing.T) {
+	repo := &searchRecorder{}
+	svc := NewUserService(repo, nil, nil, nil, nil, nil, nil, nil, nil, nil, metrics.Nop{})
+	req := dto.PaginationRequest{Search: "0812"}
+
+	user := auth.WithPrincipal(context.Background(), auth.Principal{UserID: uuid.NewString(), Role: constants.ENUM_ROLE_USER})
+	admin := auth.WithPrincipal(context.Background(), auth.Principal{UserID: uuid.NewString(), Role: constants.ENUM_ROLE_ADMIN})
+	for _, ctx := range []context.Context{user, admin} {
+		if _, err := svc.GetAllUserWithPagination(ctx, req); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if repo.searchContact[0] || !repo.searchContact[1] {
+		t.Errorf("searchContact for user, admin = %v, want [false true]", repo.searchContact)
+	}
+}
@@ -0,0 +1,464 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	crand "crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/Amierza/e-wallet/auth"
+	"github.com/Amierza/e-wallet/constants"
+	"github.com/Amierza/e-wallet/dto"
+	"github.com/Amierza/e-wallet/entity"
+	"github.com/Amierza/e-wallet/event"
+	"github.com/Amierza/e-wallet/repository"
+	"github.com/Amierza/e-wallet/webhook"
+	"github.com/google/uuid"
+)
+
+type (
+	WebhookService interface {
+		CreateEndpoint(ctx context.Context, req dto.WebhookEndpointRequest) (dto.WebhookEndpointResponse, error)
+		GetAllEndpoint(ctx context.Context) ([]dto.WebhookEndpointResponse, error)
+		DeleteEndpoint(ctx context.Context, endpointID string) error
+		GetAllDeliveryWithPagination(ctx context.Context, req dto.WebhookDeliveryPaginationRequest) (dto.WebhookDeliveryPaginationResponse, error)
+		Redeliver(ctx context.Context, deliveryID string) (dto.WebhookDeliveryResponse, error)
+		Publish(ctx context.Context, eventID uuid.UUID, eventType string, userID uuid.UUID, data any) error
+		DispatchDue(ctx context.Context) (int, error)
+		StartDispatcher(ctx context.Context)
+	}
+	webhookService struct {
+		webhookRepo repository.WebhookRepository
+		policy      webhook.Policy
+		client      *http.Client
+	}
+)
+
+// webhookEvents are the event types an endpoint can subscribe to. There is
+// no refund.created: the wallet has no refund flow yet, and accepting a
+// subscription to an event that is never sent would look like a broken
+// endpoint to its owner. Add it together with the flow that emits it.
+var webhookEvents = []string{
+	constants.ENUM_EVENT_TOPUP_COMPLETED,
+	constants.ENUM_EVENT_PAYMENT_COMPLETED,
+	constants.ENUM_EVENT_TRANSFER_RECEIVED,
+}
+
+// NewWebhookService accepts endpoints and sends deliveries only where policy
+// allows; servers pass the zero Policy, or AllowHTTP in local development.
+func NewWebhookService(webhookRepo repository.WebhookRepository, policy webhook.Policy) WebhookService {
+	return &webhookService{
+		webhookRepo: webhookRepo,
+		policy:      policy,
+		client:      policy.NewClient(constants.ENUM_WEBHOOK_TIMEOUT_SECOND * time.Second),
+	}
+}
+
+func (s *webhookService) CreateEndpoint(ctx context.Context, req dto.WebhookEndpointRequest) (dto.WebhookEndpointResponse, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return dto.WebhookEndpointResponse{}, dto.ErrGetUserFromToken
+	}
+
+	target, err := s.policy.CheckTarget(ctx, req.URL)
+	switch {
+	case errors.Is(err, webhook.ErrInsecureTarget):
+		return dto.WebhookEndpointResponse{}, dto.ErrInsecureWebhookURL
+	case errors.Is(err, webhook.ErrForbiddenTarget):
+		return dto.WebhookEndpointResponse{}, dto.ErrForbiddenWebhookURL
+	case err != nil:
+		return dto.WebhookEndpointResponse{}, dto.ErrInvalidWebhookURL
+	}
+
+	for _, event := range req.Events {
+		if !slices.Contains(webhookEvents, event) {
+			return dto.WebhookEndpointResponse{}, dto.ErrUnknownWebhookEvent
+		}
+	}
+
+	endpoint := entity.WebhookEndpoint{
+		ID:     uuid.New(),
+		URL:    target.String(),
+		Secret: newWebhookSecret(),
+		Events: slices.Compact(slices.Sorted(slices.Values(req.Events))),
+		Active: true,
+	}
+
+	if req.Global {
+		if !auth.IsAdmin(ctx) {
+			return dto.WebhookEndpointResponse{}, dto.ErrGlobalWebhookNotAllowed
+		}
+	} else {
+		owner := uuid.MustParse(userID)
+		endpoint.UserID = &owner
+	}
+
+	endpoint, err = s.webhookRepo.CreateEndpoint(ctx, nil, endpoint)
+	if err != nil {
+		return dto.WebhookEndpointResponse{}, dto.ErrCreateWebhookEndpoint
+	}
+
+	res := toWebhookEndpointResponse(endpoint)
+	res.Secret = endpoint.Secret
+	return res, nil
+}
+
+func (s *webhookService) GetAllEndpoint(ctx context.Context) ([]dto.WebhookEndpointResponse, error) {
+	endpoints, err := s.ownedEndpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	datas := []dto.WebhookEndpointResponse{}
+	for _, endpoint := range endpoints {
+		datas = append(datas, toWebhookEndpointResponse(endpoint))
+	}
+
+	return datas, nil
+}
+
+func (s *webhookService) DeleteEndpoint(ctx context.Context, endpointID string) error {
+	endpoint, err := s.findOwnedEndpoint(ctx, endpointID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.webhookRepo.DeleteEndpoint(ctx, nil, endpoint.ID.String()); err != nil {
+		return dto.ErrDeleteWebhookEndpoint
+	}
+
+	return nil
+}
+
+func (s *webhookService) GetAllDeliveryWithPagination(ctx context.Context, req dto.WebhookDeliveryPaginationRequest) (dto.WebhookDeliveryPaginationResponse, error) {
+	endpoints, err := s.ownedEndpoints(ctx)
+	if err != nil {
+		return dto.WebhookDeliveryPaginationResponse{}, err
+	}
+
+	var endpointIDs []string
+	for _, endpoint := range endpoints {
+		if req.EndpointID == "" || req.EndpointID == endpoint.ID.String() {
+			endpointIDs = append(endpointIDs, endpoint.ID.String())
+		}
+	}
+
+	if len(endpointIDs) == 0 {
+		return dto.WebhookDeliveryPaginationResponse{}, dto.ErrWebhookEndpointNotFound
+	}
+
+	dataWithPaginate, err := s.webhookRepo.GetDeliveriesWithPagination(ctx, nil, endpointIDs, req.PaginationRequest)
+	if err != nil {
+		return dto.WebhookDeliveryPaginationResponse{}, dto.ErrGetWebhookDeliveries
+	}
+
+	datas := []dto.WebhookDeliveryResponse{}
+	for _, delivery := range dataWithPaginate.Deliveries {
+		datas = append(datas, toWebhookDeliveryResponse(delivery))
+	}
+
+	return dto.WebhookDeliveryPaginationResponse{
+		Data: datas,
+		PaginationResponse: dto.PaginationResponse{
+			Page:    dataWithPaginate.Page,
+			PerPage: dataWithPaginate.PerPage,
+			MaxPage: dataWithPaginate.MaxPage,
+			Count:   dataWithPaginate.Count,
+		},
+	}, nil
+}
+
+// Redeliver queues a fresh delivery of the same event so the original row
+// stays in the log untouched.
+func (s *webhookService) Redeliver(ctx context.Context, deliveryID string) (dto.WebhookDeliveryResponse, error) {
+	delivery, err := s.webhookRepo.FindDeliveryByID(ctx, nil, deliveryID)
+	if err != nil {
+		return dto.WebhookDeliveryResponse{}, dto.ErrWebhookDeliveryNotFound
+	}
+
+	if _, err := s.findOwnedEndpoint(ctx, delivery.EndpointID.String()); err != nil {
+		return dto.WebhookDeliveryResponse{}, dto.ErrWebhookDeliveryNotFound
+	}
+
+	redelivery := entity.WebhookDelivery{
+		ID:            uuid.New(),
+		EndpointID:    delivery.EndpointID,
+		EventID:       delivery.EventID,
+		EventType:     delivery.EventType,
+		Payload:       delivery.Payload,
+		Status:        constants.ENUM_WEBHOOK_STATUS_PENDING,
+		NextAttemptAt: time.Now(),
+	}
+
+	if err := s.webhookRepo.CreateDeliveries(ctx, nil, []entity.WebhookDelivery{redelivery}); err != nil {
+		return dto.WebhookDeliveryResponse{}, dto.ErrRedeliverWebhook
+	}
+
+	return toWebhookDeliveryResponse(redelivery), nil
+}
+
+// Publish queues one delivery per active endpoint subscribed to eventType,
+// either owned by userID or registered globally. eventID is reused as the
+// X-Webhook-Id so receivers can drop duplicates.
+func (s *webhookService) Publish(ctx context.Context, eventID uuid.UUID, eventType string, userID uuid.UUID, data any) error {
+	endpoints, err := s.webhookRepo.GetActiveEndpointsForUser(ctx, nil, userID.String())
+	if err != nil {
+		return dto.ErrPublishWebhook
+	}
+
+	body, err := json.Marshal(data)
+	if err != nil {
+		return dto.ErrPublishWebhook
+	}
+
+	now := time.Now()
+	event := webhook.Event{
+		ID:        eventID.String(),
+		Type:      eventType,
+		CreatedAt: now,
+		Data:      body,
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return dto.ErrPublishWebhook
+	}
+
+	var deliveries []entity.WebhookDelivery
+	for _, endpoint := range endpoints {
+		if !slices.Contains(endpoint.Events, eventType) {
+			continue
+		}
+
+		deliveries = append(deliveries, entity.WebhookDelivery{
+			ID:            uuid.New(),
+			EndpointID:    endpoint.ID,
+			EventID:       eventID,
+			EventType:     eventType,
+			Payload:       string(payload),
+			Status:        constants.ENUM_WEBHOOK_STATUS_PENDING,
+			NextAttemptAt: now,
+		})
+	}
+
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	if err := s.webhookRepo.CreateDeliveries(ctx, nil, deliveries); err != nil {
+		return dto.ErrPublishWebhook
+	}
+
+	return nil
+}
+
+func (s *webhookService) DispatchDue(ctx context.Context) (int, error) {
+	deliveries, err := s.webhookRepo.ClaimDueDeliveries(ctx, nil, time.Now(),
+		constants.ENUM_WEBHOOK_CLAIM_LEASE_SECOND*time.Second, constants.ENUM_WEBHOOK_DISPATCH_BATCH)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, delivery := range deliveries {
+		s.deliver(ctx, delivery)
+	}
+
+	return len(deliveries), nil
+}
+
+func (s *webhookService) StartDispatcher(ctx context.Context) {
+	ticker := time.NewTicker(constants.ENUM_WEBHOOK_POLL_INTERVAL_SECOND * time.Second)
+	defer ticker.Stop()
+
+	for {
+		// A batch that already started is finished even when ctx is cancelled,
+		// so stopping never leaves it half done.
+		if _, err := s.DispatchDue(context.WithoutCancel(ctx)); err != nil {
+			slog.ErrorContext(ctx, "webhook dispatcher failed", slog.Any("error", err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *webhookService) deliver(ctx context.Context, delivery entity.WebhookDelivery) {
+	now := time.Now()
+	delivery.Attempts++
+
+	statusCode, err := s.send(ctx, delivery, now)
+	delivery.LastStatusCode = statusCode
+
+	switch {
+	case err == nil:
+		delivery.Status = constants.ENUM_WEBHOOK_STATUS_SUCCEEDED
+		delivery.LastError = ""
+		delivery.DeliveredAt = &now
+	case delivery.Attempts >= constants.ENUM_WEBHOOK_MAX_ATTEMPT || delivery.Endpoint.ID == uuid.Nil:
+		delivery.Status = constants.ENUM_WEBHOOK_STATUS_FAILED
+		delivery.LastError = err.Error()
+	default:
+		delivery.LastError = err.Error()
+		delivery.NextAttemptAt = now.Add(webhookBackoff(delivery.Attempts))
+	}
+
+	if err := s.webhookRepo.UpdateDelivery(ctx, nil, delivery); err != nil {
+		slog.ErrorContext(ctx, "webhook dispatcher: update delivery failed", slog.String("delivery_id", delivery.ID.String()), slog.Any("error", err))
+	}
+}
+
+func (s *webhookService) send(ctx context.Context, delivery entity.WebhookDelivery, now time.Time) (int, error) {
+	if delivery.Endpoint.ID == uuid.Nil || !delivery.Endpoint.Active {
+		return 0, fmt.Errorf("endpoint is removed or inactive")
+	}
+
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.Endpoint.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(webhook.HeaderID, delivery.EventID.String())
+	req.Header.Set(webhook.HeaderEvent, delivery.EventType)
+	req.Header.Set(webhook.HeaderTimestamp, fmt.Sprint(now.Unix()))
+	req.Header.Set(webhook.HeaderSignature, webhook.Sign(delivery.Endpoint.Secret, now, body))
+
+	// The delivery log is shown to the endpoint owner, so the raw error,
+	// which may name internal addresses, is only logged here.
+	resp, err := s.client.Do(req)
+	if err != nil {
+		slog.WarnContext(ctx, "webhook dispatcher: delivery failed", slog.String("delivery_id", delivery.ID.String()), slog.Any("error", err))
+		return 0, errors.New(webhook.Describe(err))
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
+
+type webhookSink struct {
+	webhookService WebhookService
+}
+
+// NewWebhookSink lets the outbox relay hand domain events to the webhook
+// dispatcher; event types nobody can subscribe to are ignored.
+func NewWebhookSink(webhookService WebhookService) event.Sink {
+	return &webhookSink{
+		webhookService: webhookService,
+	}
+}
+
+func (s *webhookSink) Name() string {
+	return "webhook"
+}
+
+func (s *webhookSink) Publish(ctx context.Context, e event.Event) error {
+	if !slices.Contains(webhookEvents, e.Type) {
+		return nil
+	}
+
+	eventID, err := uuid.Parse(e.ID)
+	if err != nil {
+		return err
+	}
+
+	userID, err := uuid.Parse(e.AggregateID)
+	if err != nil {
+		return err
+	}
+
+	return s.webhookService.Publish(ctx, eventID, e.Type, userID, e.Payload)
+}
+
+func (s *webhookService) ownedEndpoints(ctx context.Context) ([]entity.WebhookEndpoint, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, dto.ErrGetUserFromToken
+	}
+
+	endpoints, err := s.webhookRepo.GetEndpoints(ctx, nil, userID, auth.IsAdmin(ctx))
+	if err != nil {
+		return nil, dto.ErrGetWebhookEndpoints
+	}
+
+	return endpoints, nil
+}
+
+func (s *webhookService) findOwnedEndpoint(ctx context.Context, endpointID string) (entity.WebhookEndpoint, error) {
+	endpoints, err := s.ownedEndpoints(ctx)
+	if err != nil {
+		return entity.WebhookEndpoint{}, err
+	}
+
+	for _, endpoint := range endpoints {
+		if endpoint.ID.String() == endpointID {
+			return endpoint, nil
+		}
+	}
+
+	return entity.WebhookEndpoint{}, dto.ErrWebhookEndpointNotFound
+}
+
+// webhookBackoff doubles the wait after every failed attempt, capped at the
+// configured maximum, with up to 10% jitter so retries from one outage spread out.
+func webhookBackoff(attempts int) time.Duration {
+	delay := time.Duration(constants.ENUM_WEBHOOK_BACKOFF_BASE_SECOND) * time.Second << (attempts - 1)
+	maxDelay := time.Duration(constants.ENUM_WEBHOOK_BACKOFF_MAX_SECOND) * time.Second
+	if delay <= 0 || delay > maxDelay {
+		delay = maxDelay
+	}
+
+	return delay + time.Duration(rand.Int64N(int64(delay/10)+1))
+}
+
+func newWebhookSecret() string {
+	b := make([]byte, 32)
+	if _, err := crand.Read(b); err != nil {
+		panic(err)
+	}
+	return "whsec_" + hex.EncodeToString(b)
+}
+
+func toWebhookEndpointResponse(endpoint entity.WebhookEndpoint) dto.WebhookEndpointResponse {
+	return dto.WebhookEndpointResponse{
+		ID:        endpoint.ID.String(),
+		URL:       endpoint.URL,
+		Events:    endpoint.Events,
+		Global:    endpoint.UserID == nil,
+		Active:    endpoint.Active,
+		CreatedAt: endpoint.CreatedAt,
+	}
+}
+
+func toWebhookDeliveryResponse(delivery entity.WebhookDelivery) dto.WebhookDeliveryResponse {
+	return dto.WebhookDeliveryResponse{
+		ID:             delivery.ID.String(),
+		EndpointID:     delivery.EndpointID.String(),
+		EventID:        delivery.EventID.String(),
+		EventType:      delivery.EventType,
+		Status:         delivery.Status,
+		Attempts:       delivery.Attempts,
+		NextAttemptAt:  delivery.NextAttemptAt,
+		LastStatusCode: delivery.LastStatusCode,
+		LastError:      delivery.LastError,
+		DeliveredAt:    delivery.DeliveredAt,
+		CreatedAt:      delivery.CreatedAt,
+	}
+}
@@ -0,0 +1,96 @@
+package totp
+
+import (
+	"testing"
+	"time"
+)
+
+// secret is the RFC 6238 appendix B SHA1 key "12345678901234567890" in base32.
+const secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestCodeAt(t *testing.T) {
+	// The last six digits of the RFC 6238 appendix B SHA1 vectors.
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+
+	for _, tt := range tests {
+		got, err := CodeAt(secret, Step(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatalf("CodeAt(%d): %v", tt.unix, err)
+		}
+		if got != tt.want {
+			t.Errorf("CodeAt(%d) = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+
+	if _, err := CodeAt("not base32!", 1); err != ErrInvalidSecret {
+		t.Errorf("CodeAt(invalid secret) err = %v, want ErrInvalidSecret", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	current := Step(now)
+	codeAt := func(step int64) string {
+		code, err := CodeAt(secret, step)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return code
+	}
+
+	tests := []struct {
+		name     string
+		code     string
+		skew     int64
+		wantStep int64
+		wantOK   bool
+	}{
+		{"current step", codeAt(current), 1, current, true},
+		{"previous step within skew", codeAt(current - 1), 1, current - 1, true},
+		{"next step within skew", codeAt(current + 1), 1, current + 1, true},
+		{"two steps back outside skew", codeAt(current - 2), 1, 0, false},
+		{"two steps ahead outside skew", codeAt(current + 2), 1, 0, false},
+		{"previous step without skew", codeAt(current - 1), 0, 0, false},
+		{"wider skew", codeAt(current - 2), 2, current - 2, true},
+		{"surrounding spaces", " " + codeAt(current) + " ", 1, current, true},
+		{"wrong length", codeAt(current)[:5], 1, 0, false},
+		{"wrong code", "000000", 1, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := Validate(secret, tt.code, now, tt.skew)
+			if ok != tt.wantOK || step != tt.wantStep {
+				t.Errorf("Validate() = (%d, %v), want (%d, %v)", step, ok, tt.wantStep, tt.wantOK)
+			}
+		})
+	}
+}
+
+// TestValidateReportsMatchedStep covers what step reuse protection relies on:
+// a code keeps validating while its step is inside the window, always as the
+// step it was generated for, so callers can refuse any step at or below the
+// last one they accepted.
+func TestValidateReportsMatchedStep(t *testing.T) {
+	issued := time.Unix(1234567890, 0)
+	code, err := CodeAt(secret, Step(issued))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, at := range []time.Time{issued, issued.Add(Period * time.Second)} {
+		step, ok := Validate(secret, code, at, 1)
+		if !ok || step != Step(issued) {
+			t.Errorf("Validate(at %d) = (%d, %v), want (%d, true)", at.Unix(), step, ok, Step(issued))
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package tracing
+
+import (
+	"errors"
+	"runtime"
+	"strings"
+
+	"github.com/Amierza/e-wallet/logging"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const spanKey = "tracing:span"
+
+// GormPlugin records a span per statement, named after the repository method
+// that ran it, such as userRepository.FindWallet. Statements are only traced
+// inside a recorded span, so the polling of background workers does not
+// start a trace of its own every tick. Like the logger, it records SQL with
+// placeholders only.
+type GormPlugin struct {
+	tracer trace.Tracer
+}
+
+func NewGormPlugin() *GormPlugin {
+	return &GormPlugin{tracer: otel.Tracer("github.com/Amierza/e-wallet/repository")}
+}
+
+func (p *GormPlugin) Name() string {
+	return "tracing"
+}
+
+func (p *GormPlugin) Initialize(db *gorm.DB) error {
+	// GORM's callback types are unexported, so only their Register is named.
+	type registerer interface {
+		Register(name string, fn func(*gorm.DB)) error
+	}
+	callback := db.Callback()
+	hooks := []struct {
+		before    registerer
+		after     registerer
+		name      string
+		operation string
+	}{
+		{callback.Create().Before("gorm:create"), callback.Create().After("gorm:create"), "create", "INSERT"},
+		{callback.Query().Before("gorm:query"), callback.Query().After("gorm:query"), "query", "SELECT"},
+		{callback.Update().Before("gorm:update"), callback.Update().After("gorm:update"), "update", "UPDATE"},
+		{callback.Delete().Before("gorm:delete"), callback.Delete().After("gorm:delete"), "delete", "DELETE"},
+		{callback.Row().Before("gorm:row"), callback.Row().After("gorm:row"), "row", "ROW"},
+		{callback.Raw().Before("gorm:raw"), callback.Raw().After("gorm:raw"), "raw", "RAW"},
+	}
+
+	for _, hook := range hooks {
+		if err := hook.before.Register("tracing:before_"+hook.name, p.start(hook.operation)); err != nil {
+			return err
+		}
+		if err := hook.after.Register("tracing:after_"+hook.name, p.end); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *GormPlugin) start(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		ctx := db.Statement.Context
+		if !trace.SpanFromContext(ctx).IsRecording() {
+			return
+		}
+
+		name, function := caller()
+		if name == "" {
+			name = "gorm." + strings.ToLower(operation)
+		}
+
+		ctx, span := p.tracer.Start(ctx, name,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				semconv.DBSystemPostgreSQL,
+				semconv.DBOperationName(operation),
+				semconv.CodeFunction(function),
+			),
+		)
+		db.Statement.Context = ctx
+		db.InstanceSet(spanKey, span)
+	}
+}
+
+func (p *GormPlugin) end(db *gorm.DB) {
+	value, ok := db.InstanceGet(spanKey)
+	if !ok {
+		return
+	}
+	span := value.(trace.Span)
+	defer span.End()
+
+	span.SetAttributes(
+		semconv.DBQueryText(db.Statement.SQL.String()),
+		attribute.Int64("db.rows_affected", db.Statement.RowsAffected),
+	)
+	if db.Statement.Table != "" {
+		span.SetAttributes(semconv.DBCollectionName(db.Statement.Table))
+	}
+	if err := db.Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		span.SetStatus(codes.Error, logging.Scrub(err.Error()))
+	}
+}
+
+// caller finds the first function outside GORM and this package, which is
+// the repository method, and returns a short span name for it and its full
+// name.
+func caller() (string, string) {
+	pcs := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(3, pcs)])
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, "gorm.io/") && !strings.Contains(frame.Function, "/e-wallet/tracing.") {
+			return shortName(frame.Function), frame.Function
+		}
+		if !more {
+			return "", ""
+		}
+	}
+}
+
+// shortName turns github.com/Amierza/e-wallet/repository.(*userRepository).FindWallet
+// into userRepository.FindWallet.
+func shortName(function string) string {
+	function = function[strings.LastIndex(function, "/")+1:]
+	if i := strings.Index(function, "."); i >= 0 {
+		function = function[i+1:]
+	}
+	return strings.NewReplacer("(*", "", ")", "").Replace(function)
+}
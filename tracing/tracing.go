@@ -0,0 +1,67 @@
+// Package tracing sets up OpenTelemetry for the server. Spans start at the
+// Gin middleware, continue through the services and end at the SQL the
+// repositories run, so a slow request shows which of them took the time.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/Amierza/e-wallet/buildinfo"
+	"github.com/Amierza/e-wallet/config"
+	"github.com/Amierza/e-wallet/constants"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// Setup installs the global tracer provider and the W3C traceparent and
+// baggage propagators. The returned function flushes buffered spans and must
+// be called before the process exits. With the none exporter, incoming trace
+// context is still propagated but nothing is recorded.
+func Setup(ctx context.Context, cfg config.Tracing, environment string) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var (
+		exporter sdktrace.SpanExporter
+		err      error
+	)
+	switch cfg.Exporter {
+	case constants.ENUM_TRACING_EXPORTER_STDOUT:
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout), stdouttrace.WithPrettyPrint())
+	case constants.ENUM_TRACING_EXPORTER_OTLP:
+		exporter, err = otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(cfg.OTLPEndpoint))
+	default:
+		return func(context.Context) error { return nil }, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("creating %s span exporter: %w", cfg.Exporter, err)
+	}
+
+	res, err := resource.New(ctx,
+		resource.WithTelemetrySDK(),
+		resource.WithFromEnv(),
+		resource.WithAttributes(
+			semconv.ServiceName(cfg.ServiceName),
+			semconv.ServiceVersion(buildinfo.Version),
+			semconv.DeploymentEnvironment(environment),
+		),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("describing tracing resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
@@ -0,0 +1,34 @@
+// Package view shapes entities into the public DTOs returned by the API.
+// Every field that leaves the server is chosen here, per audience, instead of
+// being copied from the entity wholesale.
+package view
+
+import (
+	"context"
+
+	"github.com/Amierza/e-wallet/auth"
+)
+
+type Audience int
+
+const (
+	// AudiencePeer is any other signed-in user looking at someone else.
+	AudiencePeer Audience = iota
+	// AudienceSelf is the user looking at their own record.
+	AudienceSelf
+	// AudienceAdmin is an operator with full visibility.
+	AudienceAdmin
+)
+
+// AudienceFor decides how much of the subject the caller in ctx may see.
+func AudienceFor(ctx context.Context, subjectUserID string) Audience {
+	principal, ok := auth.FromContext(ctx)
+	switch {
+	case ok && auth.IsAdmin(ctx):
+		return AudienceAdmin
+	case ok && principal.UserID == subjectUserID:
+		return AudienceSelf
+	default:
+		return AudiencePeer
+	}
+}
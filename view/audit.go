@@ -0,0 +1,74 @@
+package view
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// sensitiveNames are JSON names that must never appear in a response. A field
+// that is meant to be shown once, such as a freshly generated webhook secret,
+// opts out with the struct tag `redact:"allow"`.
+var sensitiveNames = []string{"pin", "password", "secret", "otp_code"}
+
+// Audit walks each value's type, including nested and embedded structs, and
+// reports every serialized field whose name marks it as sensitive.
+func Audit(values ...any) error {
+	var leaks []string
+	for _, value := range values {
+		t := reflect.TypeOf(value)
+		auditType(t, t.Name(), map[reflect.Type]bool{}, &leaks)
+	}
+
+	if len(leaks) > 0 {
+		return fmt.Errorf("sensitive fields in responses: %s", strings.Join(leaks, ", "))
+	}
+	return nil
+}
+
+func auditType(t reflect.Type, path string, seen map[reflect.Type]bool, leaks *[]string) {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || seen[t] {
+		return
+	}
+	seen[t] = true
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+
+		if field.Anonymous && name == "" {
+			auditType(field.Type, path, seen, leaks)
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		if isSensitive(name) && field.Tag.Get("redact") != "allow" {
+			*leaks = append(*leaks, path+"."+name)
+		}
+
+		auditType(field.Type, path+"."+name, seen, leaks)
+	}
+}
+
+func isSensitive(name string) bool {
+	name = strings.ToLower(name)
+	for _, sensitive := range sensitiveNames {
+		if name == sensitive || strings.HasSuffix(name, "_"+sensitive) || strings.HasSuffix(name, "_hash") {
+			return true
+		}
+	}
+	return false
+}
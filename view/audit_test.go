@@ -0,0 +1,156 @@
+package view
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Amierza/e-wallet/dto"
+	"github.com/Amierza/e-wallet/realtime"
+)
+
+// responses is every type the API serializes to clients, webhooks or
+// realtime subscribers. TestEveryResponseIsAudited fails when a dto response
+// or event type is neither listed here nor nested in one that is.
+var responses = []any{
+	dto.UserResponse{},
+	dto.UserLoginResponse{},
+	dto.UserPaginationResponse{},
+	dto.TransactionPaginationResponse{},
+	dto.AllTransactionResponse{},
+	dto.WalletResponse{},
+	dto.TopUpResponse{},
+	dto.PaymentResponse{},
+	dto.TransferResponse{},
+	dto.EmailVerificationResponse{},
+	dto.StatementResponse{},
+	dto.OTPResponse{},
+	dto.VerifyPinResetResponse{},
+	dto.TwoFactorEnrollResponse{},
+	dto.RecoveryCodesResponse{},
+	dto.DeviceResponse{},
+	dto.FXRateResponse{},
+	dto.FXQuoteResponse{},
+	dto.FXRevenueResponse{},
+	dto.WebhookEndpointResponse{},
+	dto.WebhookDeliveryPaginationResponse{},
+	dto.UserDetailResponse{},
+	dto.RevokeTokensResponse{},
+	dto.TransactionExportResponse{},
+	dto.LedgerReportResponse{},
+	dto.LivenessResponse{},
+	dto.ReadinessResponse{},
+	dto.VersionResponse{},
+	dto.TopUpCompletedEvent{},
+	dto.PaymentCompletedEvent{},
+	dto.TransferCompletedEvent{},
+	dto.TransferReceivedEvent{},
+	dto.SessionRevokedEvent{},
+	dto.RealtimeBalanceData{},
+	dto.RealtimeTransactionData{},
+	realtime.Message{},
+}
+
+func TestResponsesHaveNoSensitiveFields(t *testing.T) {
+	if err := Audit(responses...); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestEveryResponseIsAudited(t *testing.T) {
+	audited := map[string]bool{}
+	for _, response := range responses {
+		collectTypes(reflect.TypeOf(response), audited)
+	}
+
+	for _, name := range dtoResponseTypes(t) {
+		if !audited[name] {
+			t.Errorf("dto.%s is not audited; add it to responses in view/audit_test.go", name)
+		}
+	}
+}
+
+func TestAuditFindsLeaks(t *testing.T) {
+	type nested struct {
+		PinHash string `json:"pin_hash"`
+	}
+	type response struct {
+		ID       string   `json:"id"`
+		Password string   `json:"password"`
+		Secret   string   `json:"secret" redact:"allow"`
+		Items    []nested `json:"items"`
+		Ignored  string   `json:"-"`
+	}
+
+	err := Audit(response{})
+	if err == nil {
+		t.Fatal("Audit() = nil, want the leaking fields reported")
+	}
+	for _, want := range []string{"response.password", "response.items.pin_hash"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Audit() = %q, want it to report %s", err, want)
+		}
+	}
+	if strings.Contains(err.Error(), "response.secret") {
+		t.Errorf("Audit() = %q, reported a field tagged redact:\"allow\"", err)
+	}
+}
+
+// dtoResponseTypes reads the dto sources for every exported type that is
+// sent to someone: the ones named ...Response or ...Event, except the
+// repository results that never leave the server.
+func dtoResponseTypes(t *testing.T) []string {
+	t.Helper()
+
+	files, err := filepath.Glob(filepath.Join("..", "dto", "*.go"))
+	if err != nil || len(files) == 0 {
+		t.Fatalf("listing dto sources: %v", err)
+	}
+
+	var names []string
+	fset := token.NewFileSet()
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		parsed, err := parser.ParseFile(fset, file, nil, parser.SkipObjectResolution)
+		if err != nil {
+			t.Fatalf("parsing %s: %v", file, err)
+		}
+		ast.Inspect(parsed, func(node ast.Node) bool {
+			spec, ok := node.(*ast.TypeSpec)
+			if !ok || !spec.Name.IsExported() {
+				return true
+			}
+			name := spec.Name.Name
+			if _, isStruct := spec.Type.(*ast.StructType); !isStruct || strings.Contains(name, "Repository") {
+				return true
+			}
+			if strings.HasSuffix(name, "Response") || strings.HasSuffix(name, "Event") {
+				names = append(names, name)
+			}
+			return true
+		})
+	}
+	return names
+}
+
+// collectTypes records the name of t and of every dto struct reachable from
+// it, so nested responses count as audited through their parent.
+func collectTypes(t reflect.Type, seen map[string]bool) {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || seen[t.Name()] {
+		return
+	}
+	seen[t.Name()] = true
+
+	for i := 0; i < t.NumField(); i++ {
+		collectTypes(t.Field(i).Type, seen)
+	}
+}
@@ -0,0 +1,28 @@
+package view
+
+import "strings"
+
+// MaskPhone keeps only the last four digits.
+func MaskPhone(phone string) string {
+	if len(phone) <= 4 {
+		return phone
+	}
+	return strings.Repeat("*", len(phone)-4) + phone[len(phone)-4:]
+}
+
+// MaskEmail keeps the first character of the local part and the domain.
+func MaskEmail(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at <= 0 {
+		return MaskPhone(email)
+	}
+	return email[:1] + strings.Repeat("*", at-1) + email[at:]
+}
+
+// Mask picks MaskEmail or MaskPhone depending on what the target looks like.
+func Mask(target string) string {
+	if strings.Contains(target, "@") {
+		return MaskEmail(target)
+	}
+	return MaskPhone(target)
+}
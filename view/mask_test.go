@@ -0,0 +1,22 @@
+package view
+
+import "testing"
+
+func TestMask(t *testing.T) {
+	tests := map[string]string{
+		"081234567890":     "********7890",
+		"+6281234567890":   "**********7890",
+		"1234":             "1234",
+		"":                 "",
+		"budi@example.com": "b***@example.com",
+		"b@example.com":    "b@example.com",
+		"@example.com":     "********.com",
+		"not-an-address@":  "n*************@",
+	}
+
+	for target, want := range tests {
+		if got := Mask(target); got != want {
+			t.Errorf("Mask(%q) = %q, want %q", target, got, want)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package view
+
+import (
+	"github.com/Amierza/e-wallet/dto"
+	"github.com/Amierza/e-wallet/entity"
+)
+
+type userFields struct {
+	fullPhone    bool
+	contact      bool
+	verification bool
+	wallets      bool
+	role         bool
+}
+
+// userVisibility is the single table deciding which user fields each
+// audience receives. Peers only ever get the name and a masked phone.
+var userVisibility = map[Audience]userFields{
+	AudiencePeer:  {},
+	AudienceSelf:  {fullPhone: true, contact: true, verification: true, wallets: true},
+	AudienceAdmin: {fullPhone: true, contact: true, verification: true, wallets: true, role: true},
+}
+
+func User(audience Audience, user entity.User) dto.UserResponse {
+	fields := userVisibility[audience]
+
+	res := dto.UserResponse{
+		ID:          user.ID.String(),
+		FirstName:   user.FirstName,
+		LastName:    user.LastName,
+		PhoneNumber: MaskPhone(user.PhoneNumber),
+	}
+
+	if fields.fullPhone {
+		res.PhoneNumber = user.PhoneNumber
+	}
+
+	if fields.contact {
+		res.Address = user.Address
+		res.Email = user.Email
+	}
+
+	if fields.verification {
+		res.PhoneVerifiedAt = user.PhoneVerifiedAt
+		res.EmailVerifiedAt = user.EmailVerifiedAt
+		timestamp := user.Timestamp
+		res.Timestamp = &timestamp
+	}
+
+	if fields.wallets && user.Wallets != nil {
+		res.Wallets = Wallets(user.Wallets)
+	}
+
+	if fields.role {
+		res.Role = user.Role
+		res.FrozenAt = user.FrozenAt
+	}
+
+	return res
+}
+
+func Users(audienceOf func(entity.User) Audience, users []entity.User) []dto.UserResponse {
+	datas := []dto.UserResponse{}
+	for _, user := range users {
+		datas = append(datas, User(audienceOf(user), user))
+	}
+	return datas
+}
+
+func Wallet(wallet entity.Wallet) dto.WalletResponse {
+	return dto.WalletResponse{
+		ID:        wallet.ID.String(),
+		Currency:  wallet.Currency,
+		MinorUnit: wallet.MinorUnit,
+		Balance:   wallet.Balance,
+	}
+}
+
+func Wallets(wallets []entity.Wallet) []dto.WalletResponse {
+	datas := []dto.WalletResponse{}
+	for _, wallet := range wallets {
+		datas = append(datas, Wallet(wallet))
+	}
+	return datas
+}
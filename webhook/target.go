@@ -0,0 +1,138 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+var (
+	ErrInvalidTarget   = errors.New("webhook: target must be an absolute http or https url")
+	ErrInsecureTarget  = errors.New("webhook: target must use https")
+	ErrForbiddenTarget = errors.New("webhook: target does not resolve to a public address")
+)
+
+// nonPublic are the ranges a webhook may never reach: the server's own
+// network, cloud metadata services and addresses that are not routable on
+// the internet. Loopback, private, link-local, multicast and unspecified
+// addresses are checked through netip as well.
+var nonPublic = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("192.0.2.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("198.51.100.0/24"),
+	netip.MustParsePrefix("203.0.113.0/24"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+	netip.MustParsePrefix("64:ff9b:1::/48"),
+	netip.MustParsePrefix("2001::/23"),
+	netip.MustParsePrefix("2001:db8::/32"),
+	netip.MustParsePrefix("2002::/16"),
+}
+
+// IsPublic reports whether ip is a globally routable unicast address.
+func IsPublic(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	if !ip.IsValid() || !ip.IsGlobalUnicast() || ip.IsPrivate() {
+		return false
+	}
+	for _, prefix := range nonPublic {
+		if prefix.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// Policy says where deliveries may go. The zero Policy only allows https
+// endpoints at public addresses.
+type Policy struct {
+	// AllowHTTP accepts plain http URLs, for local development.
+	AllowHTTP bool
+	// AllowLoopback accepts endpoints on this machine, so integration tests
+	// can deliver to a webhooktest.Receiver. A server never sets it.
+	AllowLoopback bool
+}
+
+func (p Policy) allows(ip netip.Addr) bool {
+	return IsPublic(ip) || p.AllowLoopback && ip.Unmap().IsLoopback()
+}
+
+// CheckTarget parses raw and makes sure every address its host resolves to is
+// allowed. The check is repeated when connecting, see NewClient, so a host
+// that resolves differently later still cannot reach an internal address.
+func (p Policy) CheckTarget(ctx context.Context, raw string) (*url.URL, error) {
+	target, err := url.ParseRequestURI(raw)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Hostname() == "" || target.User != nil {
+		return nil, ErrInvalidTarget
+	}
+	if target.Scheme != "https" && !p.AllowHTTP {
+		return nil, ErrInsecureTarget
+	}
+
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", target.Hostname())
+	if err != nil || len(addrs) == 0 {
+		return nil, ErrForbiddenTarget
+	}
+	for _, addr := range addrs {
+		if !p.allows(addr) {
+			return nil, ErrForbiddenTarget
+		}
+	}
+
+	return target, nil
+}
+
+// NewClient returns the client deliveries are sent with. It refuses to
+// connect to an address the policy does not allow, whatever the host
+// resolved to, ignores proxy settings so the check applies to the real peer,
+// and does not follow redirects; a redirect is reported as its own status code.
+func (p Policy) NewClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(network string, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil || !p.allows(addrPort.Addr()) {
+				return ErrForbiddenTarget
+			}
+			return nil
+		},
+	}
+
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			Proxy:                 nil,
+			DialContext:           dialer.DialContext,
+			TLSHandshakeTimeout:   timeout,
+			ResponseHeaderTimeout: timeout,
+			MaxIdleConnsPerHost:   2,
+			IdleConnTimeout:       90 * time.Second,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// Describe turns a failed delivery into the text shown in the delivery log.
+// Transport errors carry resolved addresses and internal details, so only
+// their kind is reported to the endpoint owner.
+func Describe(err error) string {
+	var netErr net.Error
+	switch {
+	case errors.Is(err, ErrForbiddenTarget):
+		return "endpoint address is not allowed"
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return "request timed out"
+	default:
+		return "request failed"
+	}
+}
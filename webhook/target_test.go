@@ -0,0 +1,71 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+)
+
+func TestIsPublic(t *testing.T) {
+	public := []string{"1.1.1.1", "93.184.216.34", "2606:4700:4700::1111"}
+	internal := []string{
+		"127.0.0.1", "::1", "10.1.2.3", "172.16.0.1", "192.168.1.1", "169.254.169.254",
+		"100.64.0.1", "0.0.0.0", "224.0.0.1", "fd00::1", "fe80::1", "::ffff:127.0.0.1",
+		"64:ff9b::7f00:1", "2002:7f00:1::", "192.0.2.1", "255.255.255.255",
+	}
+
+	for _, addr := range public {
+		if !IsPublic(netip.MustParseAddr(addr)) {
+			t.Errorf("IsPublic(%s) = false, want true", addr)
+		}
+	}
+	for _, addr := range internal {
+		if IsPublic(netip.MustParseAddr(addr)) {
+			t.Errorf("IsPublic(%s) = true, want false", addr)
+		}
+	}
+}
+
+func TestPolicyAllowsLoopbackOnlyWhenAsked(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://169.254.169.254/", http.StatusFound)
+	}))
+	defer server.Close()
+
+	if _, err := (Policy{AllowHTTP: true}).CheckTarget(context.Background(), server.URL); !errors.Is(err, ErrForbiddenTarget) {
+		t.Errorf("CheckTarget(loopback) = %v, want ErrForbiddenTarget", err)
+	}
+	if _, err := (Policy{AllowHTTP: true}).NewClient(time.Second).Get(server.URL); !errors.Is(err, ErrForbiddenTarget) {
+		t.Errorf("Get(loopback) = %v, want the dial refused", err)
+	}
+
+	loopback := Policy{AllowHTTP: true, AllowLoopback: true}
+	if _, err := loopback.CheckTarget(context.Background(), server.URL); err != nil {
+		t.Errorf("CheckTarget(loopback) with AllowLoopback = %v", err)
+	}
+	resp, err := loopback.NewClient(time.Second).Get(server.URL)
+	if err != nil {
+		t.Fatalf("Get(loopback) with AllowLoopback = %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusFound {
+		t.Errorf("status = %d, want the redirect returned instead of followed", resp.StatusCode)
+	}
+}
+
+func TestDescribeHidesTransportErrors(t *testing.T) {
+	_, err := (Policy{}).NewClient(time.Second).Get("https://127.0.0.1:1/")
+	if got := Describe(err); got != "endpoint address is not allowed" {
+		t.Errorf("Describe(%v) = %q", err, got)
+	}
+	if got := Describe(context.DeadlineExceeded); got != "request timed out" {
+		t.Errorf("Describe(deadline) = %q", got)
+	}
+	if got := Describe(errors.New("dial tcp 10.0.0.5:443: connection refused")); got != "request failed" {
+		t.Errorf("Describe(other) = %q", got)
+	}
+}
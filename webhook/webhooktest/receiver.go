@@ -0,0 +1,127 @@
+// Package webhooktest provides a local webhook receiver for integration tests.
+package webhooktest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	"github.com/Amierza/e-wallet/webhook"
+)
+
+type Delivery struct {
+	Event    webhook.Event
+	Header   http.Header
+	Body     []byte
+	Verified bool
+}
+
+// Receiver is an HTTP server that records every webhook it receives and
+// checks its signature against Secret. It listens on plain http on loopback,
+// so register it with a webhook service built with
+// webhook.Policy{AllowHTTP: true, AllowLoopback: true}.
+type Receiver struct {
+	URL    string
+	Secret string
+
+	server     *httptest.Server
+	mu         sync.Mutex
+	deliveries []Delivery
+	failures   []int
+	notify     chan struct{}
+}
+
+func NewReceiver(secret string) *Receiver {
+	r := &Receiver{
+		Secret: secret,
+		notify: make(chan struct{}, 1),
+	}
+	r.server = httptest.NewServer(http.HandlerFunc(r.handle))
+	r.URL = r.server.URL
+	return r
+}
+
+func (r *Receiver) Close() {
+	r.server.Close()
+}
+
+// FailNext makes the next n requests answer with status, to exercise retries.
+func (r *Receiver) FailNext(n int, status int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := 0; i < n; i++ {
+		r.failures = append(r.failures, status)
+	}
+}
+
+func (r *Receiver) Deliveries() []Delivery {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Delivery(nil), r.deliveries...)
+}
+
+// WaitFor blocks until at least n successful deliveries were recorded.
+func (r *Receiver) WaitFor(n int, timeout time.Duration) ([]Delivery, error) {
+	deadline := time.After(timeout)
+	for {
+		if deliveries := r.Deliveries(); len(deliveries) >= n {
+			return deliveries, nil
+		}
+
+		select {
+		case <-r.notify:
+		case <-deadline:
+			return r.Deliveries(), fmt.Errorf("webhooktest: got %d deliveries, want %d", len(r.Deliveries()), n)
+		}
+	}
+}
+
+func (r *Receiver) handle(w http.ResponseWriter, req *http.Request) {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	r.mu.Lock()
+	if len(r.failures) > 0 {
+		status := r.failures[0]
+		r.failures = r.failures[1:]
+		r.mu.Unlock()
+		w.WriteHeader(status)
+		return
+	}
+	r.mu.Unlock()
+
+	var event webhook.Event
+	if err := json.Unmarshal(body, &event); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	verified := webhook.Verify(r.Secret, req.Header.Get(webhook.HeaderSignature), body, 0) == nil
+
+	r.mu.Lock()
+	r.deliveries = append(r.deliveries, Delivery{
+		Event:    event,
+		Header:   req.Header.Clone(),
+		Body:     body,
+		Verified: verified,
+	})
+	r.mu.Unlock()
+
+	select {
+	case r.notify <- struct{}{}:
+	default:
+	}
+
+	if !verified {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}